@@ -0,0 +1,11 @@
+package main
+
+import (
+	"interactive_learning/internal/interactive_learning"
+	"os"
+)
+
+// проверяет видимость категорий в базе, параметры подключения те же, что у сервера
+func main() {
+	os.Exit(interactive_learning.CheckCategories(os.Stdout))
+}
@@ -0,0 +1,25 @@
+UPDATE results
+SET type = lower(trim(type));
+
+UPDATE results
+SET type = 'learning'
+WHERE type NOT IN ('learning', 'test', 'write', 'match', 'exam');
+
+ALTER TABLE public.results
+ADD CONSTRAINT results_type_check
+CHECK (type IN ('learning', 'test', 'write', 'match', 'exam'));
+
+ALTER TABLE public.results
+ADD COLUMN IF NOT EXISTS score integer;
+
+ALTER TABLE public.results
+ADD COLUMN IF NOT EXISTS duration integer;
+
+ALTER TABLE public.results
+ADD COLUMN IF NOT EXISTS questions_count integer;
+
+ALTER TABLE public.results
+ADD CONSTRAINT results_meta_check
+CHECK ((score IS NULL OR score >= 0)
+    AND (duration IS NULL OR duration >= 0)
+    AND (questions_count IS NULL OR questions_count >= 0));
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS public.idempotency_keys
+(
+    owner integer NOT NULL,
+    key character varying COLLATE pg_catalog."default" NOT NULL,
+    kind character varying COLLATE pg_catalog."default" NOT NULL,
+    request_hash character varying COLLATE pg_catalog."default" NOT NULL,
+    response jsonb NOT NULL,
+    created_at timestamp without time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT idempotency_keys_pkey PRIMARY KEY (owner, key)
+);
+
+ALTER TABLE IF EXISTS public.idempotency_keys
+    ADD CONSTRAINT idempotency_keys_owner_fkey FOREIGN KEY (owner)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_at_idx
+    ON public.idempotency_keys (created_at);
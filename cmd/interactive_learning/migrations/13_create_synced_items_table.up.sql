@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS public.synced_items
+(
+    owner integer NOT NULL,
+    client_id uuid NOT NULL,
+    kind character varying COLLATE pg_catalog."default" NOT NULL,
+    client_time timestamp without time zone NOT NULL,
+    response jsonb NOT NULL,
+    synced_at timestamp without time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT synced_items_pkey PRIMARY KEY (owner, client_id)
+);
+
+ALTER TABLE IF EXISTS public.synced_items
+    ADD CONSTRAINT synced_items_owner_fkey FOREIGN KEY (owner)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
@@ -0,0 +1,29 @@
+ALTER TABLE public.cards_results
+ADD COLUMN IF NOT EXISTS term_lang character varying COLLATE pg_catalog."default";
+
+ALTER TABLE public.cards_results
+ADD COLUMN IF NOT EXISTS term_text character varying COLLATE pg_catalog."default";
+
+ALTER TABLE public.cards_results
+ADD COLUMN IF NOT EXISTS def_lang character varying COLLATE pg_catalog."default";
+
+ALTER TABLE public.cards_results
+ADD COLUMN IF NOT EXISTS def_text character varying COLLATE pg_catalog."default";
+
+UPDATE cards_results
+SET term_lang = cards.term_lang,
+    term_text = cards.term_text,
+    def_lang = cards.def_lang,
+    def_text = cards.def_text
+FROM cards
+WHERE cards.id = cards_results.card_id;
+
+ALTER TABLE cards_results
+ALTER COLUMN term_lang SET NOT NULL,
+ALTER COLUMN term_text SET NOT NULL,
+ALTER COLUMN def_lang SET NOT NULL,
+ALTER COLUMN def_text SET NOT NULL;
+
+-- результаты по удаленным карточкам остаются в истории
+ALTER TABLE IF EXISTS public.cards_results
+DROP CONSTRAINT IF EXISTS cards_results_card_id_fkey;
@@ -0,0 +1,35 @@
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS forked_from integer;
+
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS forked_from_owner integer;
+
+ALTER TABLE IF EXISTS public.modules
+    ADD CONSTRAINT modules_forked_from_fkey FOREIGN KEY (forked_from)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE SET NULL;
+
+ALTER TABLE IF EXISTS public.modules
+    ADD CONSTRAINT modules_forked_from_owner_fkey FOREIGN KEY (forked_from_owner)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS forked_from integer;
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS forked_from_owner integer;
+
+ALTER TABLE IF EXISTS public.categories
+    ADD CONSTRAINT categories_forked_from_fkey FOREIGN KEY (forked_from)
+    REFERENCES public.categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE SET NULL;
+
+ALTER TABLE IF EXISTS public.categories
+    ADD CONSTRAINT categories_forked_from_owner_fkey FOREIGN KEY (forked_from_owner)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
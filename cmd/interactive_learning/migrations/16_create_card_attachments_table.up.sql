@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS public.card_attachments
+(
+    id serial NOT NULL,
+    card_id integer NOT NULL,
+    owner integer NOT NULL,
+    side character varying COLLATE pg_catalog."default" NOT NULL,
+    kind character varying COLLATE pg_catalog."default" NOT NULL,
+    content_type character varying COLLATE pg_catalog."default" NOT NULL,
+    size bigint NOT NULL,
+    blob_key character varying COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT card_attachments_pkey PRIMARY KEY (id),
+    CONSTRAINT card_attachments_blob_key_unique UNIQUE (blob_key),
+    CONSTRAINT card_attachments_side_check CHECK (side IN ('term', 'definition')),
+    CONSTRAINT card_attachments_kind_check CHECK (kind IN ('image', 'audio'))
+);
+
+ALTER TABLE IF EXISTS public.card_attachments
+    ADD CONSTRAINT card_attachments_card_id_fkey FOREIGN KEY (card_id)
+    REFERENCES public.cards (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
+
+ALTER TABLE IF EXISTS public.card_attachments
+    ADD CONSTRAINT card_attachments_owner_fkey FOREIGN KEY (owner)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
+
+CREATE INDEX IF NOT EXISTS card_attachments_card_id_idx
+    ON public.card_attachments (card_id);
@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS public.card_revisions
+(
+    id serial NOT NULL,
+    card_id integer NOT NULL,
+    editor integer NOT NULL,
+    action character varying COLLATE pg_catalog."default" NOT NULL,
+    before_term_lang character varying COLLATE pg_catalog."default" NOT NULL,
+    before_term_text character varying COLLATE pg_catalog."default" NOT NULL,
+    before_def_lang character varying COLLATE pg_catalog."default" NOT NULL,
+    before_def_text character varying COLLATE pg_catalog."default" NOT NULL,
+    after_term_lang character varying COLLATE pg_catalog."default" NOT NULL,
+    after_term_text character varying COLLATE pg_catalog."default" NOT NULL,
+    after_def_lang character varying COLLATE pg_catalog."default" NOT NULL,
+    after_def_text character varying COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT card_revisions_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE IF EXISTS public.card_revisions
+    ADD CONSTRAINT card_revisions_card_id_fkey FOREIGN KEY (card_id)
+    REFERENCES public.cards (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.card_revisions
+    ADD CONSTRAINT card_revisions_editor_fkey FOREIGN KEY (editor)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
+
+CREATE INDEX IF NOT EXISTS card_revisions_card_id_idx
+    ON public.card_revisions (card_id);
+
+CREATE TABLE IF NOT EXISTS public.module_revisions
+(
+    id serial NOT NULL,
+    module_id integer NOT NULL,
+    editor integer NOT NULL,
+    action character varying COLLATE pg_catalog."default" NOT NULL,
+    old_name character varying COLLATE pg_catalog."default" NOT NULL,
+    new_name character varying COLLATE pg_catalog."default" NOT NULL,
+    old_type integer NOT NULL,
+    new_type integer NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT module_revisions_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE IF EXISTS public.module_revisions
+    ADD CONSTRAINT module_revisions_module_id_fkey FOREIGN KEY (module_id)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.module_revisions
+    ADD CONSTRAINT module_revisions_editor_fkey FOREIGN KEY (editor)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE NO ACTION;
+
+CREATE INDEX IF NOT EXISTS module_revisions_module_id_idx
+    ON public.module_revisions (module_id);
@@ -0,0 +1,8 @@
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS deleted_at timestamp with time zone;
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS deleted_at timestamp with time zone;
+
+ALTER TABLE public.cards
+ADD COLUMN IF NOT EXISTS deleted_at timestamp with time zone;
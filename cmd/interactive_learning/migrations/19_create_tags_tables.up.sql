@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS public.module_tags
+(
+    module_id integer NOT NULL,
+    tag character varying COLLATE pg_catalog."default" NOT NULL,
+    CONSTRAINT module_tags_pkey PRIMARY KEY (module_id, tag)
+);
+
+CREATE TABLE IF NOT EXISTS public.card_tags
+(
+    card_id integer NOT NULL,
+    tag character varying COLLATE pg_catalog."default" NOT NULL,
+    CONSTRAINT card_tags_pkey PRIMARY KEY (card_id, tag)
+);
+
+ALTER TABLE IF EXISTS public.module_tags
+    ADD CONSTRAINT module_tags_module_id_fkey FOREIGN KEY (module_id)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.card_tags
+    ADD CONSTRAINT card_tags_card_id_fkey FOREIGN KEY (card_id)
+    REFERENCES public.cards (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS module_tags_tag_idx
+    ON public.module_tags (tag);
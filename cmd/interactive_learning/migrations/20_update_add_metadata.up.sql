@@ -0,0 +1,27 @@
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS description character varying COLLATE pg_catalog."default" NOT NULL DEFAULT '';
+
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS difficulty integer NOT NULL DEFAULT 0;
+
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS created_at timestamp with time zone NOT NULL DEFAULT NOW();
+
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT NOW();
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS description character varying COLLATE pg_catalog."default" NOT NULL DEFAULT '';
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS difficulty integer NOT NULL DEFAULT 0;
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS created_at timestamp with time zone NOT NULL DEFAULT NOW();
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT NOW();
+
+-- для подсчета карточек в списках модулей
+CREATE INDEX IF NOT EXISTS cards_module_id_idx
+    ON public.cards (module_id);
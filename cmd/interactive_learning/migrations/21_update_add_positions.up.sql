@@ -0,0 +1,18 @@
+ALTER TABLE public.cards
+ADD COLUMN IF NOT EXISTS "position" integer NOT NULL DEFAULT 0;
+
+ALTER TABLE public.category_modules
+ADD COLUMN IF NOT EXISTS "position" integer NOT NULL DEFAULT 0;
+
+-- существующие карточки и модули сохраняют порядок добавления
+UPDATE cards
+SET "position" = ordered.pos
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY module_id ORDER BY id) - 1 AS pos FROM cards) AS ordered
+WHERE ordered.id = cards.id;
+
+UPDATE category_modules
+SET "position" = ordered.pos
+FROM (SELECT category_id, module_id,
+        ROW_NUMBER() OVER (PARTITION BY category_id ORDER BY module_id) - 1 AS pos
+      FROM category_modules) AS ordered
+WHERE ordered.category_id = category_modules.category_id AND ordered.module_id = category_modules.module_id;
@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS public.module_grants
+(
+    module_id integer NOT NULL,
+    user_id integer NOT NULL,
+    role character varying COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT module_grants_pkey PRIMARY KEY (module_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS public.category_grants
+(
+    category_id integer NOT NULL,
+    user_id integer NOT NULL,
+    role character varying COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT category_grants_pkey PRIMARY KEY (category_id, user_id)
+);
+
+ALTER TABLE IF EXISTS public.module_grants
+    ADD CONSTRAINT module_grants_module_id_fkey FOREIGN KEY (module_id)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.module_grants
+    ADD CONSTRAINT module_grants_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.category_grants
+    ADD CONSTRAINT category_grants_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES public.categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.category_grants
+    ADD CONSTRAINT category_grants_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- для списка "доступно мне"
+CREATE INDEX IF NOT EXISTS module_grants_user_id_idx
+    ON public.module_grants (user_id);
+
+CREATE INDEX IF NOT EXISTS category_grants_user_id_idx
+    ON public.category_grants (user_id);
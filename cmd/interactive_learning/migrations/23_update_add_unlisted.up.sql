@@ -0,0 +1,16 @@
+-- модуль с type = 2 не попадает в поиск и популярное, но открывается по ссылке
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS share_slug character varying COLLATE pg_catalog."default";
+
+-- у категории type считает приватные модули, поэтому "по ссылке" хранится отдельно
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS unlisted boolean NOT NULL DEFAULT false;
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS share_slug character varying COLLATE pg_catalog."default";
+
+CREATE UNIQUE INDEX IF NOT EXISTS modules_share_slug_idx
+    ON public.modules (share_slug);
+
+CREATE UNIQUE INDEX IF NOT EXISTS categories_share_slug_idx
+    ON public.categories (share_slug);
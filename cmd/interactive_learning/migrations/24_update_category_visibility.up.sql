@@ -0,0 +1,20 @@
+-- видимость, которую выбрал владелец. Действующая видимость считается в запросах:
+-- категория с приватным модулем приватна, что бы ни выбрал владелец.
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS visibility smallint NOT NULL DEFAULT 0;
+
+-- type >= 1 не отличает выбор владельца от счетчика приватных модулей,
+-- поэтому такие категории остаются приватными: ничего не открывается без ведома владельца.
+-- Категории, которые остались приватными без приватных модулей, показывает cmd/check_categories.
+UPDATE categories
+SET visibility = CASE
+    WHEN type >= 1 THEN 1
+    WHEN unlisted THEN 2
+    ELSE 0
+END;
+
+ALTER TABLE public.categories
+    ADD CONSTRAINT categories_visibility_check CHECK (visibility IN (0, 1, 2));
+
+ALTER TABLE public.categories DROP COLUMN IF EXISTS unlisted;
+ALTER TABLE public.categories DROP COLUMN IF EXISTS type;
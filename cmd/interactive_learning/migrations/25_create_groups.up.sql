@@ -0,0 +1,87 @@
+CREATE TABLE IF NOT EXISTS public.groups
+(
+    id serial NOT NULL,
+    name character varying COLLATE pg_catalog."default" NOT NULL,
+    owner_id integer NOT NULL,
+    join_code character varying COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT groups_pkey PRIMARY KEY (id),
+    CONSTRAINT groups_join_code_key UNIQUE (join_code)
+);
+
+CREATE TABLE IF NOT EXISTS public.group_members
+(
+    group_id integer NOT NULL,
+    user_id integer NOT NULL,
+    role character varying COLLATE pg_catalog."default" NOT NULL,
+    joined_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT group_members_pkey PRIMARY KEY (group_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS public.group_modules
+(
+    group_id integer NOT NULL,
+    module_id integer NOT NULL,
+    added_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT group_modules_pkey PRIMARY KEY (group_id, module_id)
+);
+
+CREATE TABLE IF NOT EXISTS public.group_categories
+(
+    group_id integer NOT NULL,
+    category_id integer NOT NULL,
+    added_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT group_categories_pkey PRIMARY KEY (group_id, category_id)
+);
+
+ALTER TABLE IF EXISTS public.groups
+    ADD CONSTRAINT groups_owner_id_fkey FOREIGN KEY (owner_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.group_members
+    ADD CONSTRAINT group_members_group_id_fkey FOREIGN KEY (group_id)
+    REFERENCES public.groups (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.group_members
+    ADD CONSTRAINT group_members_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.group_modules
+    ADD CONSTRAINT group_modules_group_id_fkey FOREIGN KEY (group_id)
+    REFERENCES public.groups (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.group_modules
+    ADD CONSTRAINT group_modules_module_id_fkey FOREIGN KEY (module_id)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.group_categories
+    ADD CONSTRAINT group_categories_group_id_fkey FOREIGN KEY (group_id)
+    REFERENCES public.groups (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.group_categories
+    ADD CONSTRAINT group_categories_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES public.categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- для списка групп пользователя и проверки доступа к материалам
+CREATE INDEX IF NOT EXISTS group_members_user_id_idx
+    ON public.group_members (user_id);
+
+CREATE INDEX IF NOT EXISTS group_modules_module_id_idx
+    ON public.group_modules (module_id);
+
+CREATE INDEX IF NOT EXISTS group_categories_category_id_idx
+    ON public.group_categories (category_id);
@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS public.assignments
+(
+    id serial NOT NULL,
+    group_id integer NOT NULL,
+    module_id integer,
+    category_id integer,
+    mode character varying COLLATE pg_catalog."default" NOT NULL,
+    -- процент правильных ответов, null - без порога
+    min_score integer,
+    due_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT assignments_pkey PRIMARY KEY (id),
+    CONSTRAINT assignments_material_check CHECK ((module_id IS NULL) <> (category_id IS NULL)),
+    CONSTRAINT assignments_min_score_check CHECK (min_score IS NULL OR min_score BETWEEN 0 AND 100)
+);
+
+-- результат ученика, засчитанный в задание. result_id - результат по модулю,
+-- category_result_id - прохождение категории
+CREATE TABLE IF NOT EXISTS public.assignment_submissions
+(
+    id serial NOT NULL,
+    assignment_id integer NOT NULL,
+    user_id integer NOT NULL,
+    result_id integer,
+    category_result_id integer,
+    score integer,
+    passed boolean NOT NULL,
+    submitted_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT assignment_submissions_pkey PRIMARY KEY (id),
+    CONSTRAINT assignment_submissions_result_check CHECK ((result_id IS NULL) <> (category_result_id IS NULL))
+);
+
+ALTER TABLE IF EXISTS public.assignments
+    ADD CONSTRAINT assignments_group_id_fkey FOREIGN KEY (group_id)
+    REFERENCES public.groups (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.assignments
+    ADD CONSTRAINT assignments_module_id_fkey FOREIGN KEY (module_id)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.assignments
+    ADD CONSTRAINT assignments_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES public.categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.assignment_submissions
+    ADD CONSTRAINT assignment_submissions_assignment_id_fkey FOREIGN KEY (assignment_id)
+    REFERENCES public.assignments (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.assignment_submissions
+    ADD CONSTRAINT assignment_submissions_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS assignments_group_id_idx
+    ON public.assignments (group_id);
+
+CREATE INDEX IF NOT EXISTS assignment_submissions_assignment_id_idx
+    ON public.assignment_submissions (assignment_id);
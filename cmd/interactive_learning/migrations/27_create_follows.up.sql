@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS public.follows
+(
+    follower_id integer NOT NULL,
+    followee_id integer NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT follows_pkey PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT follows_self_check CHECK (follower_id <> followee_id)
+);
+
+ALTER TABLE IF EXISTS public.follows
+    ADD CONSTRAINT follows_follower_id_fkey FOREIGN KEY (follower_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.follows
+    ADD CONSTRAINT follows_followee_id_fkey FOREIGN KEY (followee_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- для списка подписчиков
+CREATE INDEX IF NOT EXISTS follows_followee_id_idx
+    ON public.follows (followee_id);
+
+-- показывать ли подписчикам успехи в обучении
+ALTER TABLE public.users
+ADD COLUMN IF NOT EXISTS share_activity boolean NOT NULL DEFAULT false;
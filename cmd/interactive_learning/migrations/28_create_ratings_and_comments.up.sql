@@ -0,0 +1,80 @@
+CREATE TABLE IF NOT EXISTS public.module_ratings
+(
+    module_id integer NOT NULL,
+    user_id integer NOT NULL,
+    rating smallint NOT NULL,
+    updated_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT module_ratings_pkey PRIMARY KEY (module_id, user_id),
+    CONSTRAINT module_ratings_rating_check CHECK (rating BETWEEN 1 AND 5)
+);
+
+CREATE TABLE IF NOT EXISTS public.category_ratings
+(
+    category_id integer NOT NULL,
+    user_id integer NOT NULL,
+    rating smallint NOT NULL,
+    updated_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT category_ratings_pkey PRIMARY KEY (category_id, user_id),
+    CONSTRAINT category_ratings_rating_check CHECK (rating BETWEEN 1 AND 5)
+);
+
+CREATE TABLE IF NOT EXISTS public.comments
+(
+    id serial NOT NULL,
+    module_id integer,
+    category_id integer,
+    user_id integer NOT NULL,
+    text character varying COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT comments_pkey PRIMARY KEY (id),
+    CONSTRAINT comments_target_check CHECK ((module_id IS NULL) <> (category_id IS NULL))
+);
+
+ALTER TABLE IF EXISTS public.module_ratings
+    ADD CONSTRAINT module_ratings_module_id_fkey FOREIGN KEY (module_id)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.module_ratings
+    ADD CONSTRAINT module_ratings_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.category_ratings
+    ADD CONSTRAINT category_ratings_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES public.categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.category_ratings
+    ADD CONSTRAINT category_ratings_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.comments
+    ADD CONSTRAINT comments_module_id_fkey FOREIGN KEY (module_id)
+    REFERENCES public.modules (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.comments
+    ADD CONSTRAINT comments_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES public.categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS public.comments
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS comments_module_id_idx
+    ON public.comments (module_id);
+
+CREATE INDEX IF NOT EXISTS comments_category_id_idx
+    ON public.comments (category_id);
@@ -0,0 +1,77 @@
+ALTER TABLE public.users
+ADD COLUMN IF NOT EXISTS is_admin boolean NOT NULL DEFAULT false;
+
+-- скрытое модератором не показывается в поиске, популярном и ленте
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS hidden_at timestamp with time zone;
+
+ALTER TABLE public.categories
+ADD COLUMN IF NOT EXISTS hidden_at timestamp with time zone;
+
+ALTER TABLE public.comments
+ADD COLUMN IF NOT EXISTS hidden_at timestamp with time zone;
+
+CREATE TABLE IF NOT EXISTS public.reports
+(
+    id serial NOT NULL,
+    target_kind character varying COLLATE pg_catalog."default" NOT NULL,
+    target_id integer NOT NULL,
+    reporter_id integer NOT NULL,
+    reason character varying COLLATE pg_catalog."default" NOT NULL,
+    note character varying COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    status character varying COLLATE pg_catalog."default" NOT NULL DEFAULT 'open',
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    resolved_at timestamp with time zone,
+    resolved_by integer,
+    CONSTRAINT reports_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE IF EXISTS public.reports
+    ADD CONSTRAINT reports_reporter_id_fkey FOREIGN KEY (reporter_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- одна открытая жалоба пользователя на объект
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_unique_idx
+    ON public.reports (target_kind, target_id, reporter_id) WHERE status = 'open';
+
+CREATE INDEX IF NOT EXISTS reports_status_idx
+    ON public.reports (status, created_at);
+
+-- журнал модерации не ссылается на пользователей и объекты, чтобы записи
+-- оставались после их удаления
+CREATE TABLE IF NOT EXISTS public.moderation_log
+(
+    id serial NOT NULL,
+    admin_id integer NOT NULL,
+    action character varying COLLATE pg_catalog."default" NOT NULL,
+    target_kind character varying COLLATE pg_catalog."default" NOT NULL,
+    target_id integer NOT NULL,
+    report_id integer,
+    note character varying COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT moderation_log_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.notifications
+(
+    id serial NOT NULL,
+    user_id integer NOT NULL,
+    kind character varying COLLATE pg_catalog."default" NOT NULL,
+    target_kind character varying COLLATE pg_catalog."default" NOT NULL,
+    target_id integer NOT NULL,
+    text character varying COLLATE pg_catalog."default" NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    read_at timestamp with time zone,
+    CONSTRAINT notifications_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE IF EXISTS public.notifications
+    ADD CONSTRAINT notifications_user_id_fkey FOREIGN KEY (user_id)
+    REFERENCES public.users (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx
+    ON public.notifications (user_id, created_at);
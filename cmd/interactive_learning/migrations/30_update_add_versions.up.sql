@@ -0,0 +1,6 @@
+-- версия растет при каждом изменении и отдается клиенту как ETag
+ALTER TABLE public.cards
+ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+
+ALTER TABLE public.modules
+ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// Assignment - задание группе пройти модуль или категорию в нужном режиме до срока
+type Assignment struct {
+	Id         int    `json:"id"`
+	GroupId    int    `json:"group_id"`
+	ModuleId   *int   `json:"module_id,omitempty"`
+	CategoryId *int   `json:"category_id,omitempty"`
+	Mode       string `json:"mode"`
+	// минимальный процент правильных ответов
+	MinScore  *int      `json:"min_score,omitempty"`
+	DueAt     time.Time `json:"due_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Submission - результат ученика, засчитанный в задание
+type Submission struct {
+	Id               int       `json:"id"`
+	AssignmentId     int       `json:"assignment_id"`
+	UserId           int       `json:"user_id"`
+	Login            string    `json:"login"`
+	ResultId         *int      `json:"result_id,omitempty"`
+	CategoryResultId *int      `json:"category_result_id,omitempty"`
+	Score            *int      `json:"score,omitempty"`
+	Passed           bool      `json:"passed"`
+	Late             bool      `json:"late"`
+	SubmittedAt      time.Time `json:"submitted_at"`
+}
+
+// AssignmentStatus - итог ученика по заданию: лучшая попытка и выполнено ли оно
+type AssignmentStatus struct {
+	UserId      int        `json:"user_id"`
+	Login       string     `json:"login"`
+	Attempts    int        `json:"attempts"`
+	BestScore   *int       `json:"best_score,omitempty"`
+	Completed   bool       `json:"completed"`
+	Late        bool       `json:"late"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// StudentAssignment - задание в списке ученика
+type StudentAssignment struct {
+	Assignment
+	GroupName string           `json:"group_name"`
+	Status    AssignmentStatus `json:"status"`
+}
+
+type Gradebook struct {
+	Assignment Assignment         `json:"assignment"`
+	Students   []AssignmentStatus `json:"students"`
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// сторона карточки, к которой прикреплен файл
+const (
+	TermSide       = "term"
+	DefinitionSide = "definition"
+)
+
+const (
+	ImageAttachment = "image"
+	AudioAttachment = "audio"
+)
+
+const (
+	MaxImageAttachmentSize = 5 << 20
+	MaxAudioAttachmentSize = 10 << 20
+)
+
+// AttachmentContentTypes - разрешенные типы файлов и их вид
+var AttachmentContentTypes = map[string]string{
+	"image/png":  ImageAttachment,
+	"image/jpeg": ImageAttachment,
+	"image/gif":  ImageAttachment,
+	"image/webp": ImageAttachment,
+	"audio/mpeg": AudioAttachment,
+	"audio/wave": AudioAttachment,
+	"audio/ogg":  AudioAttachment,
+}
+
+type Attachment struct {
+	Id          int       `json:"id"`
+	CardId      int       `json:"card_id"`
+	OwnerId     int       `json:"-"`
+	Side        string    `json:"side"`
+	Kind        string    `json:"kind"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Key         string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
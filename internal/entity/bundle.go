@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+const (
+	BundleFormat  = "interactive_learning.bundle"
+	BundleVersion = 1
+)
+
+// виды содержимого в выгрузке
+const (
+	ModuleBundle   = "module"
+	CategoryBundle = "category"
+)
+
+type BundleManifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BundleModule struct {
+	Name  string      `json:"name"`
+	Type  int         `json:"type"`
+	Cards []CardToAdd `json:"cards"`
+}
+
+type BundleContent struct {
+	Name    string         `json:"name,omitempty"` // название категории
+	Type    int            `json:"type,omitempty"`
+	Modules []BundleModule `json:"modules"`
+}
+
+type Bundle struct {
+	Manifest BundleManifest `json:"manifest"`
+	Content  BundleContent  `json:"content"`
+}
+
+type BundleImport struct {
+	CategoryId int   `json:"category_id,omitempty"`
+	ModulesIds []int `json:"modules_ids"`
+}
@@ -10,6 +10,11 @@ type Card struct {
 	ParentModule int          `json:"parent_module"`
 	Term         TextWithLang `json:"term"`
 	Definition   TextWithLang `json:"definition"`
+	Position     int          `json:"position"`
+	Tags         []string     `json:"tags,omitempty"`
+	Attachments  []Attachment `json:"attachments,omitempty"`
+	// растет при каждом изменении термина или определения
+	Version int `json:"version"`
 }
 
 type CardToAdd struct {
@@ -20,4 +25,6 @@ type CardToAdd struct {
 type CardsToAdd struct {
 	Cards        []CardToAdd `json:"cards"`
 	ParentModule int         `json:"parent_module"`
+	// позиция первой из добавляемых карточек, по умолчанию - в конец модуля
+	Position *int `json:"position,omitempty"`
 }
@@ -1,8 +1,13 @@
 package entity
 
+import "time"
+
+// видимость категории
 const (
 	PublicCategory  = 0
 	PrivateCategory = 1
+	// не показывается в поиске и популярном, открывается по ссылке
+	UnlistedCategory = 2
 )
 
 type Category struct {
@@ -10,12 +15,28 @@ type Category struct {
 	Name    string   `json:"name"`
 	OwnerId int      `json:"owner_id"`
 	Modules []Module `json:"modules,omitempty"`
-	Type    int      `json:"type"` // если >= 1 то приват, значение больше храниться в бд для отслеживания сколько приватных модулей
+	// действующая видимость: категория с приватным модулем приватна
+	Type int `json:"type"`
+	// видимость, которую выбрал владелец
+	Visibility int `json:"visibility"`
+
+	ForkedFrom *ForkOrigin `json:"forked_from,omitempty"`
+
+	Description  string    `json:"description"` // markdown
+	Difficulty   int       `json:"difficulty"`
+	ModulesCount int       `json:"modules_count"`
+	CardsCount   int       `json:"cards_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	// скрыта модератором
+	Hidden bool `json:"hidden,omitempty"`
 }
 
 type PopularCategory struct {
-	Cat   Category `json:"category"`
-	Count int      `json:"users_count"`
+	Cat          Category `json:"category"`
+	Count        int      `json:"users_count"`
+	Rating       *float64 `json:"rating,omitempty"`
+	RatingsCount int      `json:"ratings_count"`
 }
 
 type CategoryToCreate struct {
@@ -23,4 +44,28 @@ type CategoryToCreate struct {
 	OwnerId int    `json:"-"`
 	Modules []int  `json:"modules_ids"`
 	Type    int    `json:"type"`
+
+	ForkedFrom *ForkOrigin `json:"-"`
+
+	Description string `json:"description"`
+	Difficulty  int    `json:"difficulty"`
+}
+
+// проблемы, которые находит проверка видимости категорий
+const (
+	// владелец открыл категорию, но в ней есть приватный модуль
+	HiddenByPrivateModuleIssue = "hidden_by_private_module"
+	// приватный модуль другого автора не виден зрителям категории
+	ForeignPrivateModuleIssue = "foreign_private_module"
+	UnknownModuleTypeIssue    = "unknown_module_type"
+	// категория приватна без приватных модулей. Миграция 24 закрыла все категории
+	// со счетчиком приватных модулей, даже если он разошелся с составом категории,
+	// поэтому выбор владельца для них стоит проверить
+	PrivateWithoutPrivateModulesIssue = "private_without_private_modules"
+)
+
+type VisibilityIssue struct {
+	Kind       string `json:"kind"`
+	CategoryId int    `json:"category_id,omitempty"`
+	ModuleId   int    `json:"module_id"`
 }
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+const (
+	MinRating = 1
+	MaxRating = 5
+
+	MaxCommentLength = 2000
+)
+
+// сортировка списков популярных модулей и категорий
+const (
+	PopularByLearners = "learners"
+	PopularByRating   = "rating"
+)
+
+type RatingSummary struct {
+	Average *float64 `json:"average,omitempty"`
+	Count   int      `json:"count"`
+	// оценка текущего пользователя
+	My *int `json:"my,omitempty"`
+}
+
+type Comment struct {
+	Id         int       `json:"id"`
+	ModuleId   *int      `json:"module_id,omitempty"`
+	CategoryId *int      `json:"category_id,omitempty"`
+	UserId     int       `json:"user_id"`
+	Login      string    `json:"login"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
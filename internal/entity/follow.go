@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// события ленты подписок
+const (
+	ModuleCreatedEvent   = "module_created"
+	CategoryCreatedEvent = "category_created"
+	// первое прохождение модуля, показывается, если пользователь разрешил
+	StudyMilestoneEvent = "study_milestone"
+)
+
+type FeedItem struct {
+	Kind string `json:"kind"`
+	// id модуля, категории или результата
+	Id             int       `json:"id"`
+	UserId         int       `json:"user_id"`
+	Login          string    `json:"login"`
+	ModuleId       *int      `json:"module_id,omitempty"`
+	CategoryId     *int      `json:"category_id,omitempty"`
+	Name           string    `json:"name"`
+	ResultType     *string   `json:"result_type,omitempty"`
+	Score          *int      `json:"score,omitempty"`
+	QuestionsCount *int      `json:"questions_count,omitempty"`
+	At             time.Time `json:"at"`
+}
+
+// FeedCursor - последнее событие предыдущей страницы, лента отсортирована по (At, Kind, Id)
+type FeedCursor struct {
+	At   time.Time `json:"at"`
+	Kind string    `json:"kind"`
+	Id   int       `json:"id"`
+}
+
+type FeedPage struct {
+	Items      []FeedItem `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
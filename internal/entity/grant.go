@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// роли пользователей, с которыми поделились модулем или категорией
+const (
+	ViewerRole = "viewer"
+	EditorRole = "editor"
+)
+
+var GrantRoles = []string{ViewerRole, EditorRole}
+
+type Grant struct {
+	UserId    int       `json:"user_id"`
+	Login     string    `json:"login"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Shared - модули и категории, которыми поделились с пользователем
+type Shared struct {
+	Modules    []Module   `json:"modules"`
+	Categories []Category `json:"categories"`
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// роли участников учебной группы
+const (
+	TeacherRole = "teacher"
+	StudentRole = "student"
+)
+
+type Group struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	OwnerId int    `json:"owner_id"`
+	// код для вступления видит только преподаватель
+	JoinCode     string    `json:"join_code,omitempty"`
+	MembersCount int       `json:"members_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type GroupMember struct {
+	UserId   int       `json:"user_id"`
+	Login    string    `json:"login"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// GroupMaterials - модули и категории, которые преподаватель открыл группе
+type GroupMaterials struct {
+	Modules    []Module   `json:"modules"`
+	Categories []Category `json:"categories"`
+}
+
+// GroupResults - результаты учеников по материалам группы
+type GroupResults struct {
+	Modules    []ModuleResult          `json:"modules_res"`
+	Categories []CategoryModulesResult `json:"categories_res"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// виды запросов, для которых сохраняется ключ идемпотентности
+const (
+	ModuleResultIdempotency   = "module_result"
+	CategoryResultIdempotency = "category_result"
+)
+
+type IdempotentResponse struct {
+	ResultId         int   `json:"result_id,omitempty"`
+	CategoryResultId int   `json:"category_result_id,omitempty"`
+	ResultsIds       []int `json:"results_ids,omitempty"`
+}
+
+type IdempotencyKey struct {
+	Owner       int
+	Key         string
+	Kind        string
+	RequestHash string
+	Response    IdempotentResponse
+	CreatedAt   time.Time
+}
@@ -0,0 +1,13 @@
+package entity
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ModuleImport struct {
+	Cards    []CardToAdd      `json:"cards"`
+	Errors   []ImportRowError `json:"errors,omitempty"`
+	ModuleId int              `json:"module_id,omitempty"`
+	CardsIds []int            `json:"cards_ids,omitempty"`
+}
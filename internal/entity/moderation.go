@@ -0,0 +1,81 @@
+package entity
+
+import "time"
+
+// AdminRole - роль администратора при проверке доступа к модерации
+const AdminRole = "admin"
+
+// на что можно пожаловаться
+const (
+	ReportModule   = "module"
+	ReportCategory = "category"
+	ReportCard     = "card"
+	ReportComment  = "comment"
+)
+
+// причины жалоб
+const (
+	SpamReason          = "spam"
+	AbuseReason         = "abuse"
+	CopyrightReason     = "copyright"
+	InappropriateReason = "inappropriate"
+	OtherReason         = "other"
+)
+
+const MaxReportNoteLength = 1000
+
+// состояния жалобы
+const (
+	OpenReport      = "open"
+	ResolvedReport  = "resolved"
+	DismissedReport = "dismissed"
+)
+
+// действия модератора, они же записи журнала
+const (
+	HideAction    = "hide"
+	RestoreAction = "restore"
+	DismissAction = "dismiss"
+)
+
+// уведомления владельцу
+const (
+	ContentHiddenNotification   = "content_hidden"
+	ContentRestoredNotification = "content_restored"
+)
+
+type Report struct {
+	Id         int        `json:"id"`
+	TargetKind string     `json:"target_kind"`
+	TargetId   int        `json:"target_id"`
+	ReporterId int        `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Note       string     `json:"note,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy *int       `json:"resolved_by,omitempty"`
+}
+
+// ModerationEntry - запись журнала модерации
+type ModerationEntry struct {
+	Id         int       `json:"id"`
+	AdminId    int       `json:"admin_id"`
+	Action     string    `json:"action"`
+	TargetKind string    `json:"target_kind"`
+	TargetId   int       `json:"target_id"`
+	ReportId   *int      `json:"report_id,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Notification struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"user_id"`
+	Kind       string     `json:"kind"`
+	TargetKind string     `json:"target_kind"`
+	TargetId   int        `json:"target_id"`
+	Text       string     `json:"text,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+}
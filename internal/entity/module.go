@@ -1,27 +1,73 @@
 package entity
 
+import "time"
+
 const (
 	PrivateModule = 1
 	PublicModule  = 0
+	// не показывается в поиске и популярном, открывается по ссылке
+	UnlistedModule = 2
+)
+
+// уровень сложности модуля или категории, 0 - не указан
+const (
+	NoDifficulty  = 0
+	MaxDifficulty = 5
 )
 
+const MaxDescriptionLen = 10000
+
+// ForkOrigin - откуда скопирован модуль или категория. Id пустой, если оригинал удален.
+type ForkOrigin struct {
+	Id      *int `json:"id"`
+	OwnerId int  `json:"owner_id"`
+}
+
 type Module struct {
-	Id      int    `json:"id"`
-	Name    string `json:"name"`
-	Cards   []Card `json:"cards,omitempty"`
-	OwnerId int    `json:"owner_id"`
-	Type    int    `json:"type"`
+	Id         int         `json:"id"`
+	Name       string      `json:"name"`
+	Cards      []Card      `json:"cards,omitempty"`
+	OwnerId    int         `json:"owner_id"`
+	Type       int         `json:"type"`
+	Tags       []string    `json:"tags,omitempty"`
+	ForkedFrom *ForkOrigin `json:"forked_from,omitempty"`
+
+	Description string `json:"description"` // markdown
+	Difficulty  int    `json:"difficulty"`
+	// самые частые языки термина и определения среди карточек
+	TermLang   string    `json:"term_lang,omitempty"`
+	DefLang    string    `json:"def_lang,omitempty"`
+	CardsCount int       `json:"cards_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// скрыт модератором
+	Hidden bool `json:"hidden,omitempty"`
+	// растет при изменении названия, видимости и описания
+	Version int `json:"version"`
+}
+
+// Info - описание модуля или категории, которое меняется отдельно от названия
+type Info struct {
+	Description string `json:"description"`
+	Difficulty  int    `json:"difficulty"`
 }
 
 type PopularModule struct {
-	Mod   Module `json:"module"`
-	Count int    `json:"count"`
+	Mod          Module   `json:"module"`
+	Count        int      `json:"count"`
+	Rating       *float64 `json:"rating,omitempty"`
+	RatingsCount int      `json:"ratings_count"`
 }
 
 type ModuleToCreate struct {
-	Id      int         `json:"id"`
-	Name    string      `json:"name"`
-	Cards   []CardToAdd `json:"cards"`
-	OwnerId int         `json:"owner_id"`
-	Type    int         `json:"type"`
+	Id         int         `json:"id"`
+	Name       string      `json:"name"`
+	Cards      []CardToAdd `json:"cards"`
+	OwnerId    int         `json:"owner_id"`
+	Type       int         `json:"type"`
+	Tags       []string    `json:"tags"`
+	ForkedFrom *ForkOrigin `json:"-"`
+
+	Description string `json:"description"`
+	Difficulty  int    `json:"difficulty"`
 }
@@ -2,14 +2,39 @@ package entity
 
 import "time"
 
+// режимы изучения, в которых может быть получен результат
+const (
+	LearningResult = "learning"
+	TestResult     = "test"
+	WriteResult    = "write"
+	MatchResult    = "match"
+	ExamResult     = "exam"
+)
+
+var ResultTypes = []string{LearningResult, TestResult, WriteResult, MatchResult, ExamResult}
+
+// режимы, в которых выставляется оценка и считается количество вопросов
+var ScoredResultTypes = []string{TestResult, WriteResult, ExamResult}
+
 type CardsResult struct {
 	CardId int    `json:"card_id"`
 	Result string `json:"result"`
+	// текст карточки на момент прохождения
+	Term          TextWithLang `json:"term"`
+	Definition    TextWithLang `json:"definition"`
+	IsCardDeleted bool         `json:"card_deleted,omitempty"`
+}
+
+type ResultMeta struct {
+	Score          *int `json:"score,omitempty"`
+	Duration       *int `json:"duration,omitempty"` // в секундах
+	QuestionsCount *int `json:"questions_count,omitempty"`
 }
 
 type Result struct {
-	Id       int           `json:"result_id"`
-	Type     string        `json:"type"`
+	Id   int    `json:"result_id"`
+	Type string `json:"type"`
+	ResultMeta
 	CardsRes []CardsResult `json:"cards_result,omitempty"`
 }
 
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// что было сделано в ревизии
+const (
+	UpdateRevision     = "update"
+	RevertRevision     = "revert"
+	RenameRevision     = "rename"
+	ChangeTypeRevision = "change_type"
+)
+
+// CardRevision хранит состояние карточки до и после изменения
+type CardRevision struct {
+	Id        int       `json:"id"`
+	CardId    int       `json:"card_id"`
+	EditorId  int       `json:"editor_id"`
+	Action    string    `json:"action"`
+	Before    CardToAdd `json:"before"`
+	After     CardToAdd `json:"after"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ModuleRevision struct {
+	Id        int       `json:"id"`
+	ModuleId  int       `json:"module_id"`
+	EditorId  int       `json:"editor_id"`
+	Action    string    `json:"action"`
+	OldName   string    `json:"old_name"`
+	NewName   string    `json:"new_name"`
+	OldType   int       `json:"old_type"`
+	NewType   int       `json:"new_type"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,7 @@
+package entity
+
+// ShareLink - то, что открывается по ссылке: модуль или категория
+type ShareLink struct {
+	Module   *Module   `json:"module,omitempty"`
+	Category *Category `json:"category,omitempty"`
+}
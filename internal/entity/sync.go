@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// статусы элементов пакетной синхронизации
+const (
+	SyncApplied   = "applied"
+	SyncDuplicate = "duplicate"
+	SyncRejected  = "rejected"
+)
+
+type SyncedItem struct {
+	Owner      int
+	ClientId   string
+	Kind       string
+	ClientTime time.Time
+	Response   IdempotentResponse
+}
+
+type SyncItemStatus struct {
+	ClientId         string `json:"client_id"`
+	Status           string `json:"status"`
+	ResultId         int    `json:"result_id,omitempty"`
+	CategoryResultId int    `json:"category_result_id,omitempty"`
+	ResultsIds       []int  `json:"results_ids,omitempty"`
+	SkippedCards     []int  `json:"skipped_cards,omitempty"` // карточки, удаленные или перенесенные после прохождения
+	Message          string `json:"message,omitempty"`
+}
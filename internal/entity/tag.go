@@ -0,0 +1,11 @@
+package entity
+
+const (
+	MaxTagLen  = 32
+	MaxTagsLen = 10 // тегов на модуле или карточке
+)
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
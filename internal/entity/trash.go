@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type TrashedModule struct {
+	Mod       Module    `json:"module"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type TrashedCategory struct {
+	Cat       Category  `json:"category"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type TrashedCard struct {
+	Card      Card      `json:"card"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Trash - корзина пользователя. Карточки удаленных модулей лежат вместе с модулем
+// и отдельно не показываются.
+type Trash struct {
+	Modules    []TrashedModule   `json:"modules"`
+	Categories []TrashedCategory `json:"categories"`
+	Cards      []TrashedCard     `json:"cards"`
+}
@@ -2,19 +2,127 @@ package httputils
 
 import (
 	"encoding/json"
+	"errors"
 	"interactive_learning/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
 )
 
+// ключ контекста, под которым AuthToken кладет id пользователя из токена.
+// Параметры запроса для этого не годятся: клиент может передать свой user_id.
+const UserIdKey = "user_id"
+
+func UserId(c echo.Context) (int, error) {
+	userId, ok := c.Get(UserIdKey).(int)
+	if !ok {
+		return 0, errors.New("bad user id")
+	}
+	return userId, nil
+}
+
+// заголовки оптимистичной блокировки: версия объекта отдается в ETag
+// и должна вернуться в If-Match при изменении
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+var ErrNoIfMatch = errors.New("If-Match is required")
+
+// ParseIfMatch разбирает версию из If-Match. Слабые ETag и * не принимаются.
+func ParseIfMatch(header string) (int, error) {
+	if header == "" {
+		return 0, ErrNoIfMatch
+	} else if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, errors.New("bad If-Match")
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil {
+		return 0, errors.New("bad If-Match")
+	}
+	return version, nil
+}
+
+// VersionFromIfMatch читает версию из обязательного If-Match и возвращает статус
+// для ответа об ошибке: 428 без заголовка, 400 при неверном формате
+func VersionFromIfMatch(c echo.Context) (int, int, error) {
+	version, err := ParseIfMatch(c.Request().Header.Get(IfMatchHeader))
+	if errors.Is(err, ErrNoIfMatch) {
+		return 0, http.StatusPreconditionRequired, err
+	} else if err != nil {
+		return 0, http.StatusBadRequest, err
+	}
+	return version, 0, nil
+}
+
+// VersionConflict - ошибка изменения устаревшей версии объекта,
+// ее реализует usecase.VersionMismatchError
+type VersionConflict interface {
+	error
+	Conflict() (object string, version int, current any)
+}
+
+// UpdateError отвечает на ошибку изменения: при конфликте версий - 412 с текущим
+// состоянием объекта и его ETag, остальные ошибки переводит toHttp
+func UpdateError(c echo.Context, err error, toHttp func(error) (int, map[string]string)) error {
+	var conflict VersionConflict
+	if errors.As(err, &conflict) {
+		object, version, current := conflict.Conflict()
+		c.Response().Header().Set(ETagHeader, ETag(version))
+		return c.JSON(http.StatusPreconditionFailed, map[string]interface{}{
+			"message": err.Error(),
+			object:    current,
+		})
+	}
+	return c.JSON(toHttp(err))
+}
+
 type ModuleCreateReq struct {
-	Name  string             `json:"name"`
-	Type  int                `json:"type"`
-	Cards []entity.CardToAdd `json:"cards"`
+	Name        string             `json:"name"`
+	Type        int                `json:"type"`
+	Tags        []string           `json:"tags"`
+	Description string             `json:"description"`
+	Difficulty  int                `json:"difficulty"`
+	Cards       []entity.CardToAdd `json:"cards"`
+}
+
+type ImportModuleReq struct {
+	Name           string
+	Type           int
+	Separator      string
+	HasHeader      bool
+	TermColumn     int
+	DefColumn      int
+	TermLangColumn int
+	DefLangColumn  int
+	TermLang       string
+	DefLang        string
+	DryRun         bool
+	SkipInvalid    bool
 }
 
 type AddModulesToCategoryReq struct {
 	ModulesIds []int `json:"modules_ids"`
 }
 
+type CardsOrderReq struct {
+	CardsIds []int `json:"cards_ids"`
+}
+
+type ModulesOrderReq struct {
+	ModulesIds []int `json:"modules_ids"`
+}
+
+type TagsReq struct {
+	Tags []string `json:"tags"`
+}
+
 type RenameReq struct {
 	NewName string `json:"new_name"`
 }
@@ -24,28 +132,89 @@ type GetModulesByIdsReq struct {
 }
 
 type ResultForReq struct {
-	Type     string               `json:"type"`
+	Type string `json:"type"`
+	entity.ResultMeta
 	CardsRes []entity.CardsResult `json:"cards_result,omitempty"`
 }
 
 type InsertModuleResultReq struct {
 	ModuleId int          `json:"module_id"`
 	Result   ResultForReq `json:"result"`
-	Owner    int          `json:"owner"`
+	Owner    int          `json:"-"`
 	Time     string       `json:"time,omitempty"`
 }
 
 type InsertCategoryModulesResultReq struct {
 	CategoryId int                     `json:"category_id"`
 	Modules    []InsertModuleResultReq `json:"modules_res"`
-	Owner      int                     `json:"owner"`
+	Owner      int                     `json:"-"`
 	Time       string                  `json:"time"`
 }
 
+type SyncItemReq struct {
+	ClientId       string                          `json:"client_id"`
+	ClientTime     string                          `json:"client_time"`
+	ModuleResult   *InsertModuleResultReq          `json:"module_result,omitempty"`
+	CategoryResult *InsertCategoryModulesResultReq `json:"category_result,omitempty"`
+}
+
+type SyncReq struct {
+	Items []SyncItemReq `json:"items"`
+}
+
 type TypeFromReq struct {
 	Type int `json:"type"`
 }
 
+type GrantReq struct {
+	Role string `json:"role"`
+}
+
+type GroupReq struct {
+	Name string `json:"name"`
+}
+
+type JoinGroupReq struct {
+	Code string `json:"code"`
+}
+
+type ShareActivityReq struct {
+	ShareActivity bool `json:"share_activity"`
+}
+
+type RatingReq struct {
+	Rating int `json:"rating"`
+}
+
+type CommentReq struct {
+	Text string `json:"text"`
+}
+
+type ReportReq struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note"`
+}
+
+// ModerationReq - решение по жалобе: hide или dismiss
+type ModerationReq struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
+
+type RestoreReq struct {
+	TargetKind string `json:"target_kind"`
+	TargetId   int    `json:"target_id"`
+	Note       string `json:"note"`
+}
+
+type AssignmentReq struct {
+	ModuleId   *int   `json:"module_id"`
+	CategoryId *int   `json:"category_id"`
+	Mode       string `json:"mode"`
+	MinScore   *int   `json:"min_score"`
+	DueAt      string `json:"due_at"`
+}
+
 func GetModulesCreateReqFromJson(body []byte) (ModuleCreateReq, error) {
 	var mod ModuleCreateReq
 	err := json.Unmarshal(body, &mod)
@@ -0,0 +1,152 @@
+package assignments
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"interactive_learning/internal/utils/gradebook"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AssignmentsRoutes struct {
+	AssignmentsUC usecase.Assignments
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewAssignmentsRoutes(assignmentsUC usecase.Assignments, errorsMapper *errors_mapper.ApplicationErrorsMapper) *AssignmentsRoutes {
+	return &AssignmentsRoutes{AssignmentsUC: assignmentsUC, errorsMapper: errorsMapper}
+}
+
+// parseIds разбирает id группы или задания из пути и id текущего пользователя
+func parseIds(c echo.Context) (int, int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("bad id")
+	}
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return 0, 0, errors.New("bad user id")
+	}
+	return id, userId, nil
+}
+
+func (ar *AssignmentsRoutes) CreateAssignment(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	assignmentReq := httputils.AssignmentReq{}
+	if err = c.Bind(&assignmentReq); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	id, err := ar.AssignmentsUC.CreateAssignment(userId, groupId, assignmentReq)
+	if err != nil {
+		return c.JSON(ar.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+func (ar *AssignmentsRoutes) GetGroupAssignments(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	assignments, err := ar.AssignmentsUC.GetGroupAssignments(userId, groupId)
+	if err != nil {
+		return c.JSON(ar.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"assignments": assignments,
+	})
+}
+
+func (ar *AssignmentsRoutes) DeleteAssignment(c echo.Context) error {
+	assignmentId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = ar.AssignmentsUC.DeleteAssignment(userId, assignmentId); err != nil {
+		return c.JSON(ar.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (ar *AssignmentsRoutes) GetMyAssignments(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	assignments, err := ar.AssignmentsUC.GetMyAssignments(userId)
+	if err != nil {
+		return c.JSON(ar.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"assignments": assignments,
+	})
+}
+
+func (ar *AssignmentsRoutes) GetAssignmentSubmissions(c echo.Context) error {
+	assignmentId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	submissions, err := ar.AssignmentsUC.GetAssignmentSubmissions(userId, assignmentId)
+	if err != nil {
+		return c.JSON(ar.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"submissions": submissions,
+	})
+}
+
+// ExportGradebook отдает ведомость по заданию в CSV
+func (ar *AssignmentsRoutes) ExportGradebook(c echo.Context) error {
+	assignmentId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	book, err := ar.AssignmentsUC.GetGradebook(userId, assignmentId)
+	if err != nil {
+		return c.JSON(ar.errorsMapper.ApplicationErrorToHttp(err))
+	}
+
+	buf := &bytes.Buffer{}
+	if err = gradebook.WriteCSV(buf, book); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"gradebook_%d.csv\"", assignmentId))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
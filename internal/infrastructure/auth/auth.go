@@ -2,12 +2,12 @@ package auth
 
 import (
 	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/usecase"
 	"interactive_learning/internal/utils/tokengenerator"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -44,7 +44,7 @@ func (auth *AuthRoutes) AuthToken(next echo.HandlerFunc) echo.HandlerFunc {
 			})
 		}
 
-		c.QueryParams().Add("user_id", strconv.Itoa(userId))
+		c.Set(httputils.UserIdKey, userId)
 
 		return next(c)
 	}
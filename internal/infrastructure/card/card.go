@@ -2,6 +2,7 @@ package card
 
 import (
 	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/usecase"
 	"net/http"
@@ -29,7 +30,7 @@ func (cr *CardRoutes) GetCardsByModule(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -55,17 +56,32 @@ func (cr *CardRoutes) GetCardById(c echo.Context) error {
 		})
 	}
 
-	card, err := cr.CardUC.GetCardById(id)
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	card, err := cr.CardUC.GetCardById(id, userId)
 	if err != nil {
 		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
 	}
 
+	c.Response().Header().Set(httputils.ETagHeader, httputils.ETag(card.Version))
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"card": card,
 	})
 }
 
 func (cr *CardRoutes) InsertCards(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
 	cards := entity.CardsToAdd{}
 	if err := c.Bind(&cards); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -73,7 +89,7 @@ func (cr *CardRoutes) InsertCards(c echo.Context) error {
 		})
 	}
 
-	id, err := cr.CardUC.InsertCards(cards)
+	id, err := cr.CardUC.InsertCards(userId, cards)
 	if err != nil {
 		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -82,8 +98,37 @@ func (cr *CardRoutes) InsertCards(c echo.Context) error {
 	})
 }
 
+func (cr *CardRoutes) ReorderCards(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	order := httputils.CardsOrderReq{}
+	if err = c.Bind(&order); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	err = cr.CardUC.ReorderCards(userId, moduleId, order.CardsIds)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
 func (cr *CardRoutes) UpdateCard(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -98,6 +143,13 @@ func (cr *CardRoutes) UpdateCard(c echo.Context) error {
 		})
 	}
 
+	version, status, err := httputils.VersionFromIfMatch(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
 	card := entity.Card{}
 	if err = c.Bind(&card); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -106,15 +158,16 @@ func (cr *CardRoutes) UpdateCard(c echo.Context) error {
 	}
 	card.Id = cardId
 
-	err = cr.CardUC.UpdateCard(userId, card)
+	newVersion, err := cr.CardUC.UpdateCard(userId, version, card)
 	if err != nil {
-		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+		return httputils.UpdateError(c, err, cr.errorsMapper.ApplicationErrorToHttp)
 	}
+	c.Response().Header().Set(httputils.ETagHeader, httputils.ETag(newVersion))
 	return c.JSON(http.StatusOK, map[string]interface{}{})
 }
 
 func (cr *CardRoutes) DeleteCard(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -135,3 +188,178 @@ func (cr *CardRoutes) DeleteCard(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, map[string]interface{}{})
 }
+
+func (cr *CardRoutes) AddCardAttachment(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	cardId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	} else if fileHeader.Size > entity.MaxAudioAttachmentSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"message": "file is too large",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	}
+	defer file.Close()
+
+	attachment, err := cr.CardUC.AddCardAttachment(userId, cardId, c.FormValue("side"), file, fileHeader.Size)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"attachment": attachment,
+	})
+}
+
+func (cr *CardRoutes) GetCardAttachment(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	attachmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	attachment, file, err := cr.CardUC.GetCardAttachment(userId, attachmentId)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	defer file.Close()
+
+	c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(attachment.Size, 10))
+	c.Response().Header().Set("Cache-Control", "private, max-age=86400")
+	return c.Stream(http.StatusOK, attachment.ContentType, file)
+}
+
+func (cr *CardRoutes) DeleteCardAttachment(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	attachmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	if err = cr.CardUC.DeleteCardAttachment(userId, attachmentId); err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (cr *CardRoutes) GetCardHistory(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	cardId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	revisions, err := cr.CardUC.GetCardHistory(userId, cardId)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revisions": revisions,
+	})
+}
+
+func (cr *CardRoutes) RevertCard(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	cardId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+	revisionId, err := strconv.Atoi(c.Param("revision_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad revision id",
+		})
+	}
+
+	card, err := cr.CardUC.RevertCard(userId, cardId, revisionId)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"card": card,
+	})
+}
+
+func (cr *CardRoutes) SetCardTags(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	cardId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	req := httputils.TagsReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	tags, err := cr.CardUC.SetCardTags(userId, cardId, req.Tags)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tags": tags,
+	})
+}
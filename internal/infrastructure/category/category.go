@@ -31,7 +31,7 @@ func (cr *CategoryRoutes) GetCategoryById(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -53,7 +53,7 @@ func (cr *CategoryRoutes) GetCategoriesToUser(c echo.Context) error {
 	var id int
 	var err error
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -95,7 +95,7 @@ func (cr *CategoryRoutes) GetModulesToCategory(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -131,7 +131,7 @@ func (cr *CategoryRoutes) GetPopularCategories(c echo.Context) error {
 		})
 	}
 
-	popularCategories, err := cr.CategoriesUC.GetPopularCategories(limit, offset)
+	popularCategories, err := cr.CategoriesUC.GetPopularCategories(limit, offset, c.QueryParam("sort"))
 	if err != nil {
 		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -161,7 +161,7 @@ func (cr *CategoryRoutes) SearchCategories(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -179,8 +179,7 @@ func (cr *CategoryRoutes) SearchCategories(c echo.Context) error {
 
 func (cr *CategoryRoutes) InsertCategory(c echo.Context) error {
 	categoryToCreate := entity.CategoryToCreate{}
-	userIdStr := c.QueryParam("user_id")
-	userId, err := strconv.Atoi(userIdStr)
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -203,8 +202,33 @@ func (cr *CategoryRoutes) InsertCategory(c echo.Context) error {
 	})
 }
 
+func (cr *CategoryRoutes) ForkCategory(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad category id",
+		})
+	}
+
+	id, modulesIds, err := cr.CategoriesUC.ForkCategory(userId, categoryId)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"new_id":          id,
+		"new_modules_ids": modulesIds,
+	})
+}
+
 func (cr *CategoryRoutes) InsertModulesToCategory(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -232,7 +256,7 @@ func (cr *CategoryRoutes) InsertModulesToCategory(c echo.Context) error {
 }
 
 func (cr *CategoryRoutes) RenameCategory(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -260,8 +284,114 @@ func (cr *CategoryRoutes) RenameCategory(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{})
 }
 
+func (cr *CategoryRoutes) ReorderCategoryModules(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad category id",
+		})
+	}
+
+	order := httputils.ModulesOrderReq{}
+	if err = c.Bind(&order); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	err = cr.CategoryModulesUC.ReorderCategoryModules(userId, categoryId, order.ModulesIds)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (cr *CategoryRoutes) UpdateCategoryInfo(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad category id",
+		})
+	}
+
+	info := entity.Info{}
+	if err = c.Bind(&info); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	err = cr.CategoriesUC.UpdateCategoryInfo(userId, categoryId, info)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (cr *CategoryRoutes) GetCategoryShareLink(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad category id",
+		})
+	}
+
+	slug, err := cr.CategoriesUC.GetCategoryShareSlug(userId, categoryId)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"slug": slug,
+	})
+}
+
+func (cr *CategoryRoutes) RotateCategoryShareLink(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad category id",
+		})
+	}
+
+	slug, err := cr.CategoriesUC.RotateCategoryShareSlug(userId, categoryId)
+	if err != nil {
+		return c.JSON(cr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"slug": slug,
+	})
+}
+
 func (cr *CategoryRoutes) ChangeCategoryType(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -288,7 +418,7 @@ func (cr *CategoryRoutes) ChangeCategoryType(c echo.Context) error {
 }
 
 func (cr *CategoryRoutes) DeleteCategory(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -311,7 +441,7 @@ func (cr *CategoryRoutes) DeleteCategory(c echo.Context) error {
 }
 
 func (cr *CategoryRoutes) DeleteModuleFromCategory(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
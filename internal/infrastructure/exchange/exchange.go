@@ -0,0 +1,200 @@
+package exchange
+
+import (
+	"bytes"
+	"fmt"
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"interactive_learning/internal/utils/anki"
+	"interactive_learning/internal/utils/cardsexport"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxBundleFileSize = 20 << 20
+
+// форматы выгрузки
+const (
+	csvFormat    = "csv"
+	jsonFormat   = "json"
+	bundleFormat = "bundle"
+	ankiFormat   = "apkg"
+)
+
+type ExchangeRoutes struct {
+	BundlesUC usecase.Bundles
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewExchangeRoutes(bundlesUC usecase.Bundles, errorsMapper *errors_mapper.ApplicationErrorsMapper) *ExchangeRoutes {
+	return &ExchangeRoutes{BundlesUC: bundlesUC, errorsMapper: errorsMapper}
+}
+
+func (er *ExchangeRoutes) ExportModule(c echo.Context) error {
+	return er.export(c, entity.ModuleBundle, er.BundlesUC.ExportModule)
+}
+
+func (er *ExchangeRoutes) ExportCategory(c echo.Context) error {
+	return er.export(c, entity.CategoryBundle, er.BundlesUC.ExportCategory)
+}
+
+func (er *ExchangeRoutes) export(c echo.Context, kind string, exportFunc func(id, userId int) (entity.Bundle, error)) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": fmt.Sprintf("bad %s id", kind),
+		})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = jsonFormat
+	}
+
+	var (
+		write       func(buf *bytes.Buffer, bundle entity.Bundle) error
+		contentType string
+		extension   string
+	)
+	switch format {
+	case csvFormat:
+		write = func(buf *bytes.Buffer, bundle entity.Bundle) error { return cardsexport.WriteCSV(buf, bundle) }
+		contentType, extension = "text/csv; charset=utf-8", "csv"
+	case jsonFormat:
+		write = func(buf *bytes.Buffer, bundle entity.Bundle) error { return cardsexport.WriteJSON(buf, bundle) }
+		contentType, extension = echo.MIMEApplicationJSONCharsetUTF8, "json"
+	case bundleFormat:
+		write = func(buf *bytes.Buffer, bundle entity.Bundle) error { return cardsexport.WriteBundle(buf, bundle) }
+		contentType, extension = "application/zip", "zip"
+	case ankiFormat:
+		write = func(buf *bytes.Buffer, bundle entity.Bundle) error { return anki.Write(buf, bundle) }
+		contentType, extension = "application/apkg", "apkg"
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "unknown format",
+		})
+	}
+
+	bundle, err := exportFunc(id, userId)
+	if err != nil {
+		return c.JSON(er.errorsMapper.ApplicationErrorToHttp(err))
+	}
+
+	buf := &bytes.Buffer{}
+	if err = write(buf, bundle); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"%s_%d.%s\"", kind, id, extension))
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
+
+func (er *ExchangeRoutes) ImportBundle(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	} else if fileHeader.Size > maxBundleFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"message": "file is too large",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	}
+	defer file.Close()
+
+	bundle, err := cardsexport.ReadBundle(file, fileHeader.Size)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	bundleImport, err := er.BundlesUC.ImportBundle(userId, bundle)
+	if err != nil {
+		return c.JSON(er.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"import": bundleImport,
+	})
+}
+
+func (er *ExchangeRoutes) ImportAnki(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	} else if fileHeader.Size > maxBundleFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"message": "file is too large",
+		})
+	}
+
+	moduleType, err := strconv.Atoi(c.FormValue("type"))
+	if err != nil {
+		moduleType = entity.PublicModule
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	}
+	defer file.Close()
+
+	bundle, err := anki.Read(file, fileHeader.Size, anki.Options{
+		Name:     c.FormValue("name"),
+		TermLang: c.FormValue("term_lang"),
+		DefLang:  c.FormValue("def_lang"),
+		Type:     moduleType,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	bundleImport, err := er.BundlesUC.ImportBundle(userId, bundle)
+	if err != nil {
+		return c.JSON(er.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"import": bundleImport,
+	})
+}
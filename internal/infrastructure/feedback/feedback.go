@@ -0,0 +1,280 @@
+package feedback
+
+import (
+	"errors"
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type FeedbackRoutes struct {
+	FeedbackUC usecase.Feedback
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewFeedbackRoutes(feedbackUC usecase.Feedback, errorsMapper *errors_mapper.ApplicationErrorsMapper) *FeedbackRoutes {
+	return &FeedbackRoutes{FeedbackUC: feedbackUC, errorsMapper: errorsMapper}
+}
+
+// parseIds разбирает id модуля, категории или комментария из пути и id текущего пользователя
+func parseIds(c echo.Context) (int, int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("bad id")
+	}
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return 0, 0, errors.New("bad user id")
+	}
+	return id, userId, nil
+}
+
+// parsePage разбирает необязательные limit и offset списка комментариев
+func parsePage(c echo.Context) (int, int, error) {
+	limit, offset := 0, 0
+	var err error
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			return 0, 0, errors.New("bad limit")
+		}
+	}
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if offset, err = strconv.Atoi(offsetStr); err != nil {
+			return 0, 0, errors.New("bad offset")
+		}
+	}
+	return limit, offset, nil
+}
+
+func (fr *FeedbackRoutes) GetModuleRating(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	rating, err := fr.FeedbackUC.GetModuleRating(userId, moduleId)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rating": rating,
+	})
+}
+
+func (fr *FeedbackRoutes) RateModule(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	req := httputils.RatingReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	if err = fr.FeedbackUC.RateModule(userId, moduleId, req.Rating); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (fr *FeedbackRoutes) UnrateModule(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = fr.FeedbackUC.UnrateModule(userId, moduleId); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (fr *FeedbackRoutes) GetCategoryRating(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	rating, err := fr.FeedbackUC.GetCategoryRating(userId, categoryId)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"rating": rating,
+	})
+}
+
+func (fr *FeedbackRoutes) RateCategory(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	req := httputils.RatingReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	if err = fr.FeedbackUC.RateCategory(userId, categoryId, req.Rating); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (fr *FeedbackRoutes) UnrateCategory(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = fr.FeedbackUC.UnrateCategory(userId, categoryId); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (fr *FeedbackRoutes) GetModuleComments(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	comments, err := fr.FeedbackUC.GetModuleComments(userId, moduleId, limit, offset)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"comments": comments,
+	})
+}
+
+func (fr *FeedbackRoutes) AddModuleComment(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	req := httputils.CommentReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	id, err := fr.FeedbackUC.AddModuleComment(userId, moduleId, req.Text)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+func (fr *FeedbackRoutes) GetCategoryComments(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	limit, offset, err := parsePage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	comments, err := fr.FeedbackUC.GetCategoryComments(userId, categoryId, limit, offset)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"comments": comments,
+	})
+}
+
+func (fr *FeedbackRoutes) AddCategoryComment(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	req := httputils.CommentReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	id, err := fr.FeedbackUC.AddCategoryComment(userId, categoryId, req.Text)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+func (fr *FeedbackRoutes) UpdateComment(c echo.Context) error {
+	commentId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	req := httputils.CommentReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	if err = fr.FeedbackUC.UpdateComment(userId, commentId, req.Text); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (fr *FeedbackRoutes) DeleteComment(c echo.Context) error {
+	commentId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = fr.FeedbackUC.DeleteComment(userId, commentId); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
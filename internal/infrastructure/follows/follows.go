@@ -0,0 +1,143 @@
+package follows
+
+import (
+	"errors"
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type FollowsRoutes struct {
+	FollowsUC usecase.Follows
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewFollowsRoutes(followsUC usecase.Follows, errorsMapper *errors_mapper.ApplicationErrorsMapper) *FollowsRoutes {
+	return &FollowsRoutes{FollowsUC: followsUC, errorsMapper: errorsMapper}
+}
+
+// parseIds разбирает id пользователя из пути и id текущего пользователя
+func parseIds(c echo.Context) (int, int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("bad id")
+	}
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return 0, 0, errors.New("bad user id")
+	}
+	return id, userId, nil
+}
+
+func (fr *FollowsRoutes) Follow(c echo.Context) error {
+	followeeId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = fr.FollowsUC.Follow(userId, followeeId); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (fr *FollowsRoutes) Unfollow(c echo.Context) error {
+	followeeId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = fr.FollowsUC.Unfollow(userId, followeeId); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (fr *FollowsRoutes) GetFollowers(c echo.Context) error {
+	ownerId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	users, err := fr.FollowsUC.GetFollowers(ownerId, userId)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"users": users,
+	})
+}
+
+func (fr *FollowsRoutes) GetFollowing(c echo.Context) error {
+	ownerId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	users, err := fr.FollowsUC.GetFollowing(ownerId, userId)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"users": users,
+	})
+}
+
+func (fr *FollowsRoutes) GetFeed(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+	limit := 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"message": "bad limit",
+			})
+		}
+	}
+
+	page, err := fr.FollowsUC.GetFeed(userId, c.QueryParam("cursor"), limit)
+	if err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"items":       page.Items,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+func (fr *FollowsRoutes) SetShareActivity(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+	req := httputils.ShareActivityReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	if err = fr.FollowsUC.SetShareActivity(userId, req.ShareActivity); err != nil {
+		return c.JSON(fr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
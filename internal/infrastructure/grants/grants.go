@@ -0,0 +1,194 @@
+package grants
+
+import (
+	"errors"
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type GrantsRoutes struct {
+	GrantsUC usecase.Grants
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewGrantsRoutes(grantsUC usecase.Grants, errorsMapper *errors_mapper.ApplicationErrorsMapper) *GrantsRoutes {
+	return &GrantsRoutes{GrantsUC: grantsUC, errorsMapper: errorsMapper}
+}
+
+// parseIds разбирает id объекта из пути и id текущего пользователя
+func parseIds(c echo.Context) (int, int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("bad id")
+	}
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return 0, 0, errors.New("bad user id")
+	}
+	return id, userId, nil
+}
+
+// parseGrant разбирает id пользователя, которому выдается доступ, и его роль
+func parseGrant(c echo.Context) (int, string, error) {
+	granteeId, err := strconv.Atoi(c.Param("grantee_id"))
+	if err != nil {
+		return 0, "", errors.New("bad grantee id")
+	}
+	grantReq := httputils.GrantReq{}
+	if err = c.Bind(&grantReq); err != nil {
+		return 0, "", errors.New("bad data")
+	}
+	return granteeId, grantReq.Role, nil
+}
+
+func (gr *GrantsRoutes) GetModuleGrants(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	grants, err := gr.GrantsUC.GetModuleGrants(userId, moduleId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"grants": grants,
+	})
+}
+
+func (gr *GrantsRoutes) ShareModule(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	granteeId, role, err := parseGrant(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GrantsUC.ShareModule(userId, moduleId, granteeId, role); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GrantsRoutes) UnshareModule(c echo.Context) error {
+	moduleId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	granteeId, _, err := parseGrant(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GrantsUC.UnshareModule(userId, moduleId, granteeId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GrantsRoutes) GetCategoryGrants(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	grants, err := gr.GrantsUC.GetCategoryGrants(userId, categoryId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"grants": grants,
+	})
+}
+
+func (gr *GrantsRoutes) ShareCategory(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	granteeId, role, err := parseGrant(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GrantsUC.ShareCategory(userId, categoryId, granteeId, role); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GrantsRoutes) UnshareCategory(c echo.Context) error {
+	categoryId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	granteeId, _, err := parseGrant(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GrantsUC.UnshareCategory(userId, categoryId, granteeId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GrantsRoutes) GetSharedWithUser(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	shared, err := gr.GrantsUC.GetSharedWithUser(userId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"shared": shared,
+	})
+}
+
+func (gr *GrantsRoutes) OpenShareLink(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	link, err := gr.GrantsUC.OpenShareLink(userId, c.Param("slug"))
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, link)
+}
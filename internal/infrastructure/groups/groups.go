@@ -0,0 +1,316 @@
+package groups
+
+import (
+	"errors"
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type GroupsRoutes struct {
+	GroupsUC usecase.Groups
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewGroupsRoutes(groupsUC usecase.Groups, errorsMapper *errors_mapper.ApplicationErrorsMapper) *GroupsRoutes {
+	return &GroupsRoutes{GroupsUC: groupsUC, errorsMapper: errorsMapper}
+}
+
+// parseIds разбирает id группы из пути и id текущего пользователя
+func parseIds(c echo.Context) (int, int, error) {
+	groupId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("bad id")
+	}
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return 0, 0, errors.New("bad user id")
+	}
+	return groupId, userId, nil
+}
+
+// parseMaterial разбирает id группы, пользователя и второй id из пути - ученика или материала
+func parseMaterial(c echo.Context, param string) (int, int, int, error) {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		return 0, 0, 0, errors.New("bad " + param)
+	}
+	return groupId, userId, id, nil
+}
+
+func (gr *GroupsRoutes) CreateGroup(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+	groupReq := httputils.GroupReq{}
+	if err = c.Bind(&groupReq); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	group, err := gr.GroupsUC.CreateGroup(userId, groupReq.Name)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group": group,
+	})
+}
+
+func (gr *GroupsRoutes) GetUserGroups(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	groups, err := gr.GroupsUC.GetUserGroups(userId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"groups": groups,
+	})
+}
+
+func (gr *GroupsRoutes) GetGroup(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	group, err := gr.GroupsUC.GetGroup(userId, groupId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group": group,
+	})
+}
+
+func (gr *GroupsRoutes) RenameGroup(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	groupReq := httputils.GroupReq{}
+	if err = c.Bind(&groupReq); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	if err = gr.GroupsUC.RenameGroup(userId, groupId, groupReq.Name); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) DeleteGroup(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GroupsUC.DeleteGroup(userId, groupId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) RotateJoinCode(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	code, err := gr.GroupsUC.RotateJoinCode(userId, groupId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"join_code": code,
+	})
+}
+
+func (gr *GroupsRoutes) JoinGroup(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+	joinReq := httputils.JoinGroupReq{}
+	if err = c.Bind(&joinReq); err != nil || joinReq.Code == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	group, err := gr.GroupsUC.JoinGroup(userId, joinReq.Code)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"group": group,
+	})
+}
+
+func (gr *GroupsRoutes) LeaveGroup(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GroupsUC.LeaveGroup(userId, groupId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) GetGroupMembers(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	members, err := gr.GroupsUC.GetGroupMembers(userId, groupId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"members": members,
+	})
+}
+
+func (gr *GroupsRoutes) KickMember(c echo.Context) error {
+	groupId, userId, memberId, err := parseMaterial(c, "member_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GroupsUC.KickMember(userId, groupId, memberId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) GetGroupMaterials(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	materials, err := gr.GroupsUC.GetGroupMaterials(userId, groupId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"modules":    materials.Modules,
+		"categories": materials.Categories,
+	})
+}
+
+func (gr *GroupsRoutes) AttachModule(c echo.Context) error {
+	groupId, userId, moduleId, err := parseMaterial(c, "module_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GroupsUC.AttachModuleToGroup(userId, groupId, moduleId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) DetachModule(c echo.Context) error {
+	groupId, userId, moduleId, err := parseMaterial(c, "module_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GroupsUC.DetachModuleFromGroup(userId, groupId, moduleId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) AttachCategory(c echo.Context) error {
+	groupId, userId, categoryId, err := parseMaterial(c, "category_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GroupsUC.AttachCategoryToGroup(userId, groupId, categoryId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) DetachCategory(c echo.Context) error {
+	groupId, userId, categoryId, err := parseMaterial(c, "category_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = gr.GroupsUC.DetachCategoryFromGroup(userId, groupId, categoryId); err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (gr *GroupsRoutes) GetGroupResults(c echo.Context) error {
+	groupId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	results, err := gr.GroupsUC.GetGroupResults(userId, groupId)
+	if err != nil {
+		return c.JSON(gr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"modules_res":    results.Modules,
+		"categories_res": results.Categories,
+	})
+}
@@ -0,0 +1,200 @@
+package moderation
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ModerationRoutes struct {
+	ModerationUC usecase.Moderation
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewModerationRoutes(moderationUC usecase.Moderation, errorsMapper *errors_mapper.ApplicationErrorsMapper) *ModerationRoutes {
+	return &ModerationRoutes{ModerationUC: moderationUC, errorsMapper: errorsMapper}
+}
+
+// parseIds разбирает id объекта из пути и id текущего пользователя
+func parseIds(c echo.Context) (int, int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, errors.New("bad id")
+	}
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return 0, 0, errors.New("bad user id")
+	}
+	return id, userId, nil
+}
+
+// parsePage разбирает id текущего пользователя и необязательные limit и offset
+func parsePage(c echo.Context) (int, int, int, error) {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return 0, 0, 0, errors.New("bad user id")
+	}
+	limit, offset := 0, 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			return 0, 0, 0, errors.New("bad limit")
+		}
+	}
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if offset, err = strconv.Atoi(offsetStr); err != nil {
+			return 0, 0, 0, errors.New("bad offset")
+		}
+	}
+	return userId, limit, offset, nil
+}
+
+func (mr *ModerationRoutes) report(c echo.Context, targetKind string) error {
+	targetId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	req := httputils.ReportReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	id, err := mr.ModerationUC.Report(userId, targetKind, targetId, req)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+func (mr *ModerationRoutes) ReportModule(c echo.Context) error {
+	return mr.report(c, entity.ReportModule)
+}
+
+func (mr *ModerationRoutes) ReportCategory(c echo.Context) error {
+	return mr.report(c, entity.ReportCategory)
+}
+
+func (mr *ModerationRoutes) ReportCard(c echo.Context) error {
+	return mr.report(c, entity.ReportCard)
+}
+
+func (mr *ModerationRoutes) ReportComment(c echo.Context) error {
+	return mr.report(c, entity.ReportComment)
+}
+
+func (mr *ModerationRoutes) GetReports(c echo.Context) error {
+	userId, limit, offset, err := parsePage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	reports, err := mr.ModerationUC.GetReports(userId, c.QueryParam("status"), limit, offset)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"reports": reports,
+	})
+}
+
+func (mr *ModerationRoutes) ResolveReport(c echo.Context) error {
+	reportId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+	req := httputils.ModerationReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	if err = mr.ModerationUC.ResolveReport(userId, reportId, req); err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (mr *ModerationRoutes) RestoreContent(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+	req := httputils.RestoreReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	if err = mr.ModerationUC.RestoreContent(userId, req); err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (mr *ModerationRoutes) GetModerationLog(c echo.Context) error {
+	userId, limit, offset, err := parsePage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	entries, err := mr.ModerationUC.GetModerationLog(userId, limit, offset)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"log": entries,
+	})
+}
+
+func (mr *ModerationRoutes) GetNotifications(c echo.Context) error {
+	userId, limit, offset, err := parsePage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	notifications, err := mr.ModerationUC.GetNotifications(userId, limit, offset)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"notifications": notifications,
+	})
+}
+
+func (mr *ModerationRoutes) MarkNotificationRead(c echo.Context) error {
+	notificationId, userId, err := parseIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	if err = mr.ModerationUC.MarkNotificationRead(userId, notificationId); err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
@@ -1,10 +1,12 @@
 package module
 
 import (
+	"errors"
 	"interactive_learning/internal/entity"
 	httputils "interactive_learning/internal/http_utils"
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/usecase"
+	"interactive_learning/internal/utils/cardsimport"
 	"net/http"
 	"strconv"
 
@@ -34,7 +36,7 @@ func (mr *ModuleRoutes) GetModulesByUser(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -64,7 +66,7 @@ func (mr *ModuleRoutes) GetModuleById(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -75,6 +77,7 @@ func (mr *ModuleRoutes) GetModuleById(c echo.Context) error {
 	if err != nil {
 		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
 	}
+	c.Response().Header().Set(httputils.ETagHeader, httputils.ETag(module.Version))
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"module": module,
 	})
@@ -88,7 +91,7 @@ func (mr *ModuleRoutes) GetModulesByIds(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -123,7 +126,7 @@ func (mr *ModuleRoutes) GetPopularModule(c echo.Context) error {
 		})
 	}
 
-	popularModules, err := mr.ModuleUC.GetPopularModules(limit, offset)
+	popularModules, err := mr.ModuleUC.GetPopularModules(limit, offset, c.QueryParam("sort"))
 	if err != nil {
 		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -133,8 +136,9 @@ func (mr *ModuleRoutes) GetPopularModule(c echo.Context) error {
 }
 
 func (mr *ModuleRoutes) SearchModules(c echo.Context) error {
-	name := c.QueryParam("name")
-	if name == "" || len([]byte(name)) < 2 {
+	name, tag := c.QueryParam("name"), c.QueryParam("tag")
+	// поиск только по тегу допускает пустое название
+	if name == "" && tag == "" || name != "" && len([]byte(name)) < 2 {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
 			"message": "empty or short name",
 		})
@@ -153,14 +157,14 @@ func (mr *ModuleRoutes) SearchModules(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
 		})
 	}
 
-	foundModules, err := mr.ModuleUC.GetModulesWithSimilarName(name, limit, offset, userId)
+	foundModules, err := mr.ModuleUC.GetModulesWithSimilarName(name, tag, limit, offset, userId)
 	if err != nil {
 		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -172,8 +176,7 @@ func (mr *ModuleRoutes) SearchModules(c echo.Context) error {
 func (mr *ModuleRoutes) InsertModule(c echo.Context) error {
 	moduleReq := httputils.ModuleCreateReq{}
 	module := entity.ModuleToCreate{}
-	userIdStr := c.QueryParam("user_id")
-	userId, err := strconv.Atoi(userIdStr)
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -189,6 +192,10 @@ func (mr *ModuleRoutes) InsertModule(c echo.Context) error {
 	module.OwnerId = userId
 	module.Name = moduleReq.Name
 	module.Type = moduleReq.Type
+	module.Tags = moduleReq.Tags
+	module.Description = moduleReq.Description
+	module.Difficulty = moduleReq.Difficulty
+	module.Cards = moduleReq.Cards
 
 	id, ids, err := mr.ModuleUC.InsertModule(module)
 	if err != nil {
@@ -200,8 +207,57 @@ func (mr *ModuleRoutes) InsertModule(c echo.Context) error {
 	})
 }
 
+func (mr *ModuleRoutes) ForkModule(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	id, ids, err := mr.ModuleUC.ForkModule(userId, moduleId)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"new_module_id": id,
+		"new_cards_ids": ids,
+	})
+}
+
+func (mr *ModuleRoutes) GetModuleHistory(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	revisions, err := mr.ModuleUC.GetModuleHistory(userId, moduleId)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"revisions": revisions,
+	})
+}
+
 func (mr *ModuleRoutes) RenameModule(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -216,6 +272,13 @@ func (mr *ModuleRoutes) RenameModule(c echo.Context) error {
 		})
 	}
 
+	version, status, err := httputils.VersionFromIfMatch(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
 	newName := httputils.RenameReq{}
 	if err = c.Bind(&newName); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -223,15 +286,132 @@ func (mr *ModuleRoutes) RenameModule(c echo.Context) error {
 		})
 	}
 
-	err = mr.ModuleUC.RenameModule(userId, moduleId, newName.NewName)
+	newVersion, err := mr.ModuleUC.RenameModule(userId, moduleId, version, newName.NewName)
 	if err != nil {
-		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+		return httputils.UpdateError(c, err, mr.errorsMapper.ApplicationErrorToHttp)
 	}
+	c.Response().Header().Set(httputils.ETagHeader, httputils.ETag(newVersion))
 	return c.JSON(http.StatusOK, map[string]interface{}{})
 }
 
+func (mr *ModuleRoutes) UpdateModuleInfo(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	version, status, err := httputils.VersionFromIfMatch(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	info := entity.Info{}
+	if err = c.Bind(&info); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	newVersion, err := mr.ModuleUC.UpdateModuleInfo(userId, moduleId, version, info)
+	if err != nil {
+		return httputils.UpdateError(c, err, mr.errorsMapper.ApplicationErrorToHttp)
+	}
+	c.Response().Header().Set(httputils.ETagHeader, httputils.ETag(newVersion))
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
+
+func (mr *ModuleRoutes) GetModuleShareLink(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	slug, err := mr.ModuleUC.GetModuleShareSlug(userId, moduleId)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"slug": slug,
+	})
+}
+
+func (mr *ModuleRoutes) RotateModuleShareLink(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	slug, err := mr.ModuleUC.RotateModuleShareSlug(userId, moduleId)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"slug": slug,
+	})
+}
+
+func (mr *ModuleRoutes) SetModuleTags(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	req := httputils.TagsReq{}
+	if err = c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad data",
+		})
+	}
+
+	tags, err := mr.ModuleUC.SetModuleTags(userId, moduleId, req.Tags)
+	if err != nil {
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tags": tags,
+	})
+}
+
 func (mr *ModuleRoutes) ChangeModuleType(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -243,6 +423,12 @@ func (mr *ModuleRoutes) ChangeModuleType(c echo.Context) error {
 			"message": "bad module id",
 		})
 	}
+	version, status, err := httputils.VersionFromIfMatch(c)
+	if err != nil {
+		return c.JSON(status, map[string]string{
+			"message": err.Error(),
+		})
+	}
 	var moduleType httputils.TypeFromReq
 	if err := c.Bind(&moduleType); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -250,15 +436,16 @@ func (mr *ModuleRoutes) ChangeModuleType(c echo.Context) error {
 		})
 	}
 
-	err = mr.ModuleUC.UpdateModuleType(moduleId, moduleType.Type, userId)
+	newVersion, err := mr.ModuleUC.UpdateModuleType(moduleId, moduleType.Type, userId, version)
 	if err != nil {
-		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+		return httputils.UpdateError(c, err, mr.errorsMapper.ApplicationErrorToHttp)
 	}
+	c.Response().Header().Set(httputils.ETagHeader, httputils.ETag(newVersion))
 	return c.NoContent(http.StatusOK)
 }
 
 func (mr *ModuleRoutes) DeleteModule(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -279,3 +466,111 @@ func (mr *ModuleRoutes) DeleteModule(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, map[string]interface{}{})
 }
+
+const maxImportFileSize = 5 << 20
+
+func (mr *ModuleRoutes) ImportModule(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	} else if fileHeader.Size > maxImportFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"message": "file is too large",
+		})
+	}
+
+	defaults := cardsimport.DefaultOptions()
+	importReq := httputils.ImportModuleReq{
+		Name:      c.FormValue("name"),
+		Separator: c.FormValue("separator"),
+		TermLang:  c.FormValue("term_lang"),
+		DefLang:   c.FormValue("def_lang"),
+	}
+	// неразобранное число или флаг - ошибка, иначе импорт молча возьмет не те колонки
+	intFields := []struct {
+		dest         *int
+		name         string
+		defaultValue int
+	}{
+		{&importReq.TermColumn, "term_column", defaults.TermColumn},
+		{&importReq.DefColumn, "def_column", defaults.DefColumn},
+		{&importReq.TermLangColumn, "term_lang_column", defaults.TermLangColumn},
+		{&importReq.DefLangColumn, "def_lang_column", defaults.DefLangColumn},
+		{&importReq.Type, "type", entity.PublicModule},
+	}
+	for _, field := range intFields {
+		if *field.dest, err = formInt(c, field.name, field.defaultValue); err != nil {
+			return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+		}
+	}
+	boolFields := []struct {
+		dest *bool
+		name string
+	}{
+		{&importReq.HasHeader, "has_header"},
+		{&importReq.DryRun, "dry_run"},
+		{&importReq.SkipInvalid, "skip_invalid"},
+	}
+	for _, field := range boolFields {
+		if *field.dest, err = formBool(c, field.name); err != nil {
+			return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad file",
+		})
+	}
+	defer file.Close()
+
+	moduleImport, err := mr.ModuleUC.ImportModule(userId, importReq, file)
+	if err != nil {
+		if errors.Is(err, usecase.InvalidDataErr) && len(moduleImport.Errors) > 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": err.Error(),
+				"import":  moduleImport,
+			})
+		}
+		return c.JSON(mr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"import": moduleImport,
+	})
+}
+
+// formInt читает число из формы, пустое поле - значение по умолчанию
+func formInt(c echo.Context, name string, defaultValue int) (int, error) {
+	value := c.FormValue(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, usecase.NewInvalidDataError(name, err)
+	}
+	return n, nil
+}
+
+// formBool читает флаг из формы, пустое поле - false
+func formBool(c echo.Context, name string) (bool, error) {
+	value := c.FormValue(name)
+	if value == "" {
+		return false, nil
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, usecase.NewInvalidDataError(name, err)
+	}
+	return flag, nil
+}
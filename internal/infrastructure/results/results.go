@@ -10,6 +10,8 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type ResultsRoutes struct {
 	ResultsUC usecase.Results
 
@@ -22,7 +24,14 @@ func NewResultsRoutes(ResultsUC usecase.Results, errorsMapper *errors_mapper.App
 
 func (rr *ResultsRoutes) GetResultsByOwner(c echo.Context) error {
 	idStr := c.Param("id")
-	userId, err := strconv.Atoi(idStr)
+	ownerId, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad owner id",
+		})
+	}
+
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -46,7 +55,7 @@ func (rr *ResultsRoutes) GetResultsByOwner(c echo.Context) error {
 			"message": "The request must contain either a category, a module, or nothing.",
 		})
 	} else if isModuleRes {
-		moduleResults, err := rr.ResultsUC.GetResultsToModuleId(moduleId, userId)
+		moduleResults, err := rr.ResultsUC.GetResultsToModuleId(moduleId, ownerId, userId)
 		if err != nil {
 			return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 		}
@@ -55,7 +64,7 @@ func (rr *ResultsRoutes) GetResultsByOwner(c echo.Context) error {
 			"module_results": moduleResults,
 		})
 	} else if isCategoryRes {
-		categoryResults, err := rr.ResultsUC.GetResultsByCategoryId(categoryId, userId)
+		categoryResults, err := rr.ResultsUC.GetResultsByCategoryId(categoryId, ownerId, userId)
 		if err != nil {
 			return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 		}
@@ -65,7 +74,7 @@ func (rr *ResultsRoutes) GetResultsByOwner(c echo.Context) error {
 		})
 	}
 
-	categoriesResults, modulesResults, err := rr.ResultsUC.GetResultsByOwner(userId)
+	categoriesResults, modulesResults, err := rr.ResultsUC.GetResultsByOwner(ownerId, userId)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -85,7 +94,14 @@ func (rr *ResultsRoutes) GetModuleResultById(c echo.Context) error {
 		})
 	}
 
-	moduleRes, err := rr.ResultsUC.GetModuleResultById(id)
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	moduleRes, err := rr.ResultsUC.GetModuleResultById(id, userId)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -104,7 +120,14 @@ func (rr *ResultsRoutes) GetCardsResultById(c echo.Context) error {
 		})
 	}
 
-	cardsResults, err := rr.ResultsUC.GetCardsResultById(id)
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	cardsResults, err := rr.ResultsUC.GetCardsResultById(id, userId)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -123,7 +146,14 @@ func (rr *ResultsRoutes) GetCategoryResById(c echo.Context) error {
 		})
 	}
 
-	categoryResult, err := rr.ResultsUC.GetCategoryResById(id)
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	categoryResult, err := rr.ResultsUC.GetCategoryResById(id, userId)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -141,15 +171,14 @@ func (rr *ResultsRoutes) InsertModuleResult(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
 		})
 	}
-	insertModuleReq.Owner = userId
 
-	newId, err := rr.ResultsUC.InsertModuleResult(insertModuleReq)
+	newId, err := rr.ResultsUC.InsertModuleResult(userId, c.Request().Header.Get(idempotencyKeyHeader), insertModuleReq)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -166,15 +195,14 @@ func (rr *ResultsRoutes) InsertCategoryResult(c echo.Context) error {
 		})
 	}
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
 		})
 	}
-	insertCategoryReq.Owner = userId
 
-	newCategoryResultId, newResultsIds, err := rr.ResultsUC.InsertCategoryResult(insertCategoryReq)
+	newCategoryResultId, newResultsIds, err := rr.ResultsUC.InsertCategoryResult(userId, c.Request().Header.Get(idempotencyKeyHeader), insertCategoryReq)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -193,7 +221,14 @@ func (rr *ResultsRoutes) DeleteModuleResult(c echo.Context) error {
 		})
 	}
 
-	err = rr.ResultsUC.DeleteModuleResult(id)
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	err = rr.ResultsUC.DeleteModuleResult(userId, id)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
@@ -209,7 +244,14 @@ func (rr *ResultsRoutes) DeleteCategoryResultById(c echo.Context) error {
 		})
 	}
 
-	err = rr.ResultsUC.DeleteCategoryResultById(id)
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	err = rr.ResultsUC.DeleteCategoryResultById(userId, id)
 	if err != nil {
 		return c.JSON(rr.errorsMapper.ApplicationErrorToHttp(err))
 	}
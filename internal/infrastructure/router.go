@@ -1,12 +1,22 @@
 package infrastructure
 
 import (
+	"interactive_learning/internal/infrastructure/assignments"
 	"interactive_learning/internal/infrastructure/auth"
 	"interactive_learning/internal/infrastructure/card"
 	"interactive_learning/internal/infrastructure/category"
+	"interactive_learning/internal/infrastructure/exchange"
+	"interactive_learning/internal/infrastructure/feedback"
+	"interactive_learning/internal/infrastructure/follows"
+	"interactive_learning/internal/infrastructure/grants"
+	"interactive_learning/internal/infrastructure/groups"
+	"interactive_learning/internal/infrastructure/moderation"
 	"interactive_learning/internal/infrastructure/module"
 	"interactive_learning/internal/infrastructure/results"
 	"interactive_learning/internal/infrastructure/selected"
+	"interactive_learning/internal/infrastructure/sync"
+	"interactive_learning/internal/infrastructure/tags"
+	"interactive_learning/internal/infrastructure/trash"
 	"interactive_learning/internal/infrastructure/user"
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/usecase"
@@ -25,6 +35,16 @@ func NewEcho(pathToStatic string,
 	categoryModulesUC usecase.CategoryModules,
 	resultsUC usecase.Results,
 	selectUC usecase.Selected,
+	syncUC usecase.Sync,
+	bundlesUC usecase.Bundles,
+	trashUC usecase.Trash,
+	tagsUC usecase.Tags,
+	grantsUC usecase.Grants,
+	groupsUC usecase.Groups,
+	assignmentsUC usecase.Assignments,
+	followsUC usecase.Follows,
+	feedbackUC usecase.Feedback,
+	moderationUC usecase.Moderation,
 	errorsMapper *errors_mapper.ApplicationErrorsMapper) *echo.Echo {
 	authRoutes := auth.NewAuthRoutes(usersUC, tokensUC, errorsMapper)
 	usersRoutes := user.NewUserRoues(usersUC, errorsMapper)
@@ -33,6 +53,16 @@ func NewEcho(pathToStatic string,
 	categoriesRoutes := category.NewCategoryRoutes(categorieUC, categoryModulesUC, errorsMapper)
 	resultsRoutes := results.NewResultsRoutes(resultsUC, errorsMapper)
 	selectedRoutes := selected.NewSelectedRouter(selectUC, errorsMapper)
+	syncRoutes := sync.NewSyncRoutes(syncUC, errorsMapper)
+	exchangeRoutes := exchange.NewExchangeRoutes(bundlesUC, errorsMapper)
+	trashRoutes := trash.NewTrashRoutes(trashUC, errorsMapper)
+	tagsRoutes := tags.NewTagsRoutes(tagsUC, errorsMapper)
+	grantsRoutes := grants.NewGrantsRoutes(grantsUC, errorsMapper)
+	groupsRoutes := groups.NewGroupsRoutes(groupsUC, errorsMapper)
+	assignmentsRoutes := assignments.NewAssignmentsRoutes(assignmentsUC, errorsMapper)
+	followsRoutes := follows.NewFollowsRoutes(followsUC, errorsMapper)
+	feedbackRoutes := feedback.NewFeedbackRoutes(feedbackUC, errorsMapper)
+	moderationRoutes := moderation.NewModerationRoutes(moderationUC, errorsMapper)
 
 	e := echo.New()
 	e.Static("/static", pathToStatic)
@@ -48,7 +78,22 @@ func NewEcho(pathToStatic string,
 
 	users := v1.Group("/user")
 	users.GET("/me", usersRoutes.GetUserInfoById)
+	users.PUT("/me/share_activity", followsRoutes.SetShareActivity)
+	users.GET("/me/notifications", moderationRoutes.GetNotifications)
+	users.PUT("/me/notifications/:id/read", moderationRoutes.MarkNotificationRead)
 	users.GET("/:id", usersRoutes.GetUserInfoById)
+	users.PUT("/:id/follow", followsRoutes.Follow)
+	users.DELETE("/:id/follow", followsRoutes.Unfollow)
+	users.GET("/:id/followers", followsRoutes.GetFollowers)
+	users.GET("/:id/following", followsRoutes.GetFollowing)
+
+	v1.GET("/feed", followsRoutes.GetFeed)
+
+	moderationGroup := v1.Group("/moderation")
+	moderationGroup.GET("/reports", moderationRoutes.GetReports)
+	moderationGroup.POST("/reports/:id/resolve", moderationRoutes.ResolveReport)
+	moderationGroup.POST("/restore", moderationRoutes.RestoreContent)
+	moderationGroup.GET("/log", moderationRoutes.GetModerationLog)
 
 	selected := v1.Group("/selected")
 	selectedModules := selected.Group("/modules")
@@ -78,39 +123,128 @@ func NewEcho(pathToStatic string,
 	categoryResult.POST("/insert", resultsRoutes.InsertCategoryResult)
 	categoryResult.DELETE("/delete/:id", resultsRoutes.DeleteCategoryResultById)
 
+	v1.POST("/sync", syncRoutes.Sync)
+
+	v1.POST("/bundle/import", exchangeRoutes.ImportBundle)
+	v1.POST("/bundle/import_anki", exchangeRoutes.ImportAnki)
+
+	trashGroup := v1.Group("/trash")
+	trashGroup.GET("/", trashRoutes.GetTrash)
+	trashGroup.POST("/module/:id/restore", trashRoutes.RestoreModule)
+	trashGroup.POST("/category/:id/restore", trashRoutes.RestoreCategory)
+	trashGroup.POST("/card/:id/restore", trashRoutes.RestoreCard)
+
 	search := v1.Group("/search")
 	search.GET("/users", usersRoutes.SearchUsers)
 	search.GET("/modules", moduleRoutes.SearchModules)
 	search.GET("/categories", categoriesRoutes.SearchCategories)
 
+	tagsGroup := v1.Group("/tags")
+	tagsGroup.GET("/autocomplete", tagsRoutes.AutocompleteTags)
+	tagsGroup.GET("/popular", tagsRoutes.GetPopularTags)
+
+	v1.GET("/shared", grantsRoutes.GetSharedWithUser)
+	v1.GET("/link/:slug", grantsRoutes.OpenShareLink)
+
+	groupsGroup := v1.Group("/group")
+	groupsGroup.GET("/", groupsRoutes.GetUserGroups)
+	groupsGroup.POST("/create", groupsRoutes.CreateGroup)
+	groupsGroup.POST("/join", groupsRoutes.JoinGroup)
+	groupsGroup.GET("/:id", groupsRoutes.GetGroup)
+	groupsGroup.PUT("/rename/:id", groupsRoutes.RenameGroup)
+	groupsGroup.DELETE("/delete/:id", groupsRoutes.DeleteGroup)
+	groupsGroup.POST("/:id/code/rotate", groupsRoutes.RotateJoinCode)
+	groupsGroup.POST("/:id/leave", groupsRoutes.LeaveGroup)
+	groupsGroup.GET("/:id/members", groupsRoutes.GetGroupMembers)
+	groupsGroup.DELETE("/:id/members/:member_id", groupsRoutes.KickMember)
+	groupsGroup.GET("/:id/materials", groupsRoutes.GetGroupMaterials)
+	groupsGroup.PUT("/:id/modules/:module_id", groupsRoutes.AttachModule)
+	groupsGroup.DELETE("/:id/modules/:module_id", groupsRoutes.DetachModule)
+	groupsGroup.PUT("/:id/categories/:category_id", groupsRoutes.AttachCategory)
+	groupsGroup.DELETE("/:id/categories/:category_id", groupsRoutes.DetachCategory)
+	groupsGroup.GET("/:id/results", groupsRoutes.GetGroupResults)
+	groupsGroup.GET("/:id/assignments", assignmentsRoutes.GetGroupAssignments)
+	groupsGroup.POST("/:id/assignments", assignmentsRoutes.CreateAssignment)
+
+	assignmentsGroup := v1.Group("/assignment")
+	assignmentsGroup.GET("/my", assignmentsRoutes.GetMyAssignments)
+	assignmentsGroup.GET("/:id/submissions", assignmentsRoutes.GetAssignmentSubmissions)
+	assignmentsGroup.GET("/:id/gradebook", assignmentsRoutes.ExportGradebook)
+	assignmentsGroup.DELETE("/delete/:id", assignmentsRoutes.DeleteAssignment)
+
 	categories := v1.Group("/category")
 	categories.POST("/:category_id/add_modules", categoriesRoutes.InsertModulesToCategory)
 	categories.DELETE("/:category_id/:module_id/delete", categoriesRoutes.DeleteModuleFromCategory)
 	categories.GET("/:id/modules", categoriesRoutes.GetModulesToCategory)
+	categories.PUT("/:id/modules/order", categoriesRoutes.ReorderCategoryModules)
+	categories.GET("/:id/export", exchangeRoutes.ExportCategory)
 	categories.GET("/:id", categoriesRoutes.GetCategoryById)
 	categories.GET("/to_user/:id", categoriesRoutes.GetCategoriesToUser)
 	categories.GET("/popular", categoriesRoutes.GetPopularCategories)
 	categories.POST("/create", categoriesRoutes.InsertCategory)
+	categories.POST("/:id/fork", categoriesRoutes.ForkCategory)
 	categories.PUT("/rename/:id", categoriesRoutes.RenameCategory)
+	categories.PUT("/:id/info", categoriesRoutes.UpdateCategoryInfo)
 	categories.PUT("/change_type/:id", categoriesRoutes.ChangeCategoryType)
 	categories.DELETE("/delete/:id", categoriesRoutes.DeleteCategory)
+	categories.GET("/:id/grants", grantsRoutes.GetCategoryGrants)
+	categories.PUT("/:id/grants/:grantee_id", grantsRoutes.ShareCategory)
+	categories.DELETE("/:id/grants/:grantee_id", grantsRoutes.UnshareCategory)
+	categories.GET("/:id/link", categoriesRoutes.GetCategoryShareLink)
+	categories.POST("/:id/link/rotate", categoriesRoutes.RotateCategoryShareLink)
+	categories.GET("/:id/rating", feedbackRoutes.GetCategoryRating)
+	categories.PUT("/:id/rating", feedbackRoutes.RateCategory)
+	categories.DELETE("/:id/rating", feedbackRoutes.UnrateCategory)
+	categories.GET("/:id/comments", feedbackRoutes.GetCategoryComments)
+	categories.POST("/:id/comments", feedbackRoutes.AddCategoryComment)
+	categories.POST("/:id/report", moderationRoutes.ReportCategory)
 
 	modules := v1.Group("/module")
 	modules.GET("/:id", moduleRoutes.GetModuleById)
+	modules.GET("/:id/export", exchangeRoutes.ExportModule)
+	modules.GET("/:id/history", moduleRoutes.GetModuleHistory)
 	modules.GET("/to_user/:id", moduleRoutes.GetModulesByUser)
 	modules.GET("/popular", moduleRoutes.GetPopularModule)
 	modules.POST("/by_ids", moduleRoutes.GetModulesByIds)
 	modules.POST("/create", moduleRoutes.InsertModule)
+	modules.POST("/import", moduleRoutes.ImportModule)
+	modules.POST("/:id/fork", moduleRoutes.ForkModule)
 	modules.PUT("/rename/:id", moduleRoutes.RenameModule)
+	modules.PUT("/:id/info", moduleRoutes.UpdateModuleInfo)
 	modules.PUT("/change_type/:id", moduleRoutes.ChangeModuleType)
+	modules.PUT("/:id/tags", moduleRoutes.SetModuleTags)
 	modules.DELETE("/delete/:id", moduleRoutes.DeleteModule)
+	modules.GET("/:id/grants", grantsRoutes.GetModuleGrants)
+	modules.PUT("/:id/grants/:grantee_id", grantsRoutes.ShareModule)
+	modules.DELETE("/:id/grants/:grantee_id", grantsRoutes.UnshareModule)
+	modules.GET("/:id/link", moduleRoutes.GetModuleShareLink)
+	modules.POST("/:id/link/rotate", moduleRoutes.RotateModuleShareLink)
+	modules.GET("/:id/rating", feedbackRoutes.GetModuleRating)
+	modules.PUT("/:id/rating", feedbackRoutes.RateModule)
+	modules.DELETE("/:id/rating", feedbackRoutes.UnrateModule)
+	modules.GET("/:id/comments", feedbackRoutes.GetModuleComments)
+	modules.POST("/:id/comments", feedbackRoutes.AddModuleComment)
+	modules.POST("/:id/report", moderationRoutes.ReportModule)
+
+	comments := v1.Group("/comment")
+	comments.PUT("/:id", feedbackRoutes.UpdateComment)
+	comments.DELETE("/:id", feedbackRoutes.DeleteComment)
+	comments.POST("/:id/report", moderationRoutes.ReportComment)
 
 	cards := v1.Group("/card")
 	cards.GET("/:id", cardRoutes.GetCardById)
 	cards.GET("/to_module/:id", cardRoutes.GetCardsByModule)
+	cards.PUT("/to_module/:id/order", cardRoutes.ReorderCards)
 	cards.POST("/insert_to_module", cardRoutes.InsertCards)
 	cards.PUT("/update/:id", cardRoutes.UpdateCard)
+	cards.PUT("/:id/tags", cardRoutes.SetCardTags)
 	cards.DELETE("/delete/:id", cardRoutes.DeleteCard)
+	cards.POST("/:id/attachments", cardRoutes.AddCardAttachment)
+	cards.GET("/attachment/:id", cardRoutes.GetCardAttachment)
+	cards.DELETE("/attachment/:id", cardRoutes.DeleteCardAttachment)
+	cards.GET("/:id/history", cardRoutes.GetCardHistory)
+	cards.POST("/:id/revert/:revision_id", cardRoutes.RevertCard)
+	cards.POST("/:id/report", moderationRoutes.ReportCard)
 
 	e.GET("/debug/pprof/*", echo.WrapHandler(http.DefaultServeMux))
 
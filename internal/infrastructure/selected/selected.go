@@ -1,6 +1,7 @@
 package selected
 
 import (
+	httputils "interactive_learning/internal/http_utils"
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/usecase"
 	"net/http"
@@ -20,7 +21,7 @@ func NewSelectedRouter(selectedUC usecase.Selected, errorsMapper *errors_mapper.
 }
 
 func (sr *SelectedRouter) GetAllSelectedModulesByUser(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -37,7 +38,7 @@ func (sr *SelectedRouter) GetAllSelectedModulesByUser(c echo.Context) error {
 }
 
 func (sr *SelectedRouter) GetAllSelectedCategoriesByUser(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -88,7 +89,7 @@ func (sr *SelectedRouter) GetUsersCountToSelectedModuleOrCategory(c echo.Context
 }
 
 func (sr *SelectedRouter) InsertSelectedModuleToUser(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -109,7 +110,7 @@ func (sr *SelectedRouter) InsertSelectedModuleToUser(c echo.Context) error {
 }
 
 func (sr *SelectedRouter) InsertSelectedCategoryToUser(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -130,7 +131,7 @@ func (sr *SelectedRouter) InsertSelectedCategoryToUser(c echo.Context) error {
 }
 
 func (sr *SelectedRouter) DeleteModuleToUser(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -151,7 +152,7 @@ func (sr *SelectedRouter) DeleteModuleToUser(c echo.Context) error {
 }
 
 func (sr *SelectedRouter) DeleteCategoryToUser(c echo.Context) error {
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
@@ -0,0 +1,44 @@
+package sync
+
+import (
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SyncRoutes struct {
+	SyncUC usecase.Sync
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewSyncRoutes(syncUC usecase.Sync, errorsMapper *errors_mapper.ApplicationErrorsMapper) *SyncRoutes {
+	return &SyncRoutes{SyncUC: syncUC, errorsMapper: errorsMapper}
+}
+
+func (sr *SyncRoutes) Sync(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	var syncReq httputils.SyncReq
+	if err := c.Bind(&syncReq); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": err.Error(),
+		})
+	}
+
+	statuses, err := sr.SyncUC.Sync(userId, syncReq)
+	if err != nil {
+		return c.JSON(sr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"items": statuses,
+	})
+}
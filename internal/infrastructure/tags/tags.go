@@ -0,0 +1,71 @@
+package tags
+
+import (
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TagsRoutes struct {
+	TagsUC usecase.Tags
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewTagsRoutes(tagsUC usecase.Tags, errorsMapper *errors_mapper.ApplicationErrorsMapper) *TagsRoutes {
+	return &TagsRoutes{TagsUC: tagsUC, errorsMapper: errorsMapper}
+}
+
+func (tr *TagsRoutes) AutocompleteTags(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	// limit необязателен, по умолчанию отдается максимум подсказок
+	limit := 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"message": err.Error(),
+			})
+		}
+	}
+
+	tags, err := tr.TagsUC.GetTagsByPrefix(c.QueryParam("prefix"), limit, userId)
+	if err != nil {
+		return c.JSON(tr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tags": tags,
+	})
+}
+
+func (tr *TagsRoutes) GetPopularTags(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"message": err.Error(),
+		})
+	}
+
+	tags, err := tr.TagsUC.GetPopularTags(limit, offset)
+	if err != nil {
+		return c.JSON(tr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"popular_tags": tags,
+	})
+}
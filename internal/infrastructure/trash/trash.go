@@ -0,0 +1,69 @@
+package trash
+
+import (
+	httputils "interactive_learning/internal/http_utils"
+	errors_mapper "interactive_learning/internal/mappers/errors"
+	"interactive_learning/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TrashRoutes struct {
+	TrashUC usecase.Trash
+
+	errorsMapper *errors_mapper.ApplicationErrorsMapper
+}
+
+func NewTrashRoutes(trashUC usecase.Trash, errorsMapper *errors_mapper.ApplicationErrorsMapper) *TrashRoutes {
+	return &TrashRoutes{TrashUC: trashUC, errorsMapper: errorsMapper}
+}
+
+func (tr *TrashRoutes) GetTrash(c echo.Context) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	trash, err := tr.TrashUC.GetTrash(userId)
+	if err != nil {
+		return c.JSON(tr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, trash)
+}
+
+func (tr *TrashRoutes) RestoreModule(c echo.Context) error {
+	return tr.restore(c, tr.TrashUC.RestoreModule)
+}
+
+func (tr *TrashRoutes) RestoreCategory(c echo.Context) error {
+	return tr.restore(c, tr.TrashUC.RestoreCategory)
+}
+
+func (tr *TrashRoutes) RestoreCard(c echo.Context) error {
+	return tr.restore(c, tr.TrashUC.RestoreCard)
+}
+
+func (tr *TrashRoutes) restore(c echo.Context, restore func(userId, id int) error) error {
+	userId, err := httputils.UserId(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad user id",
+		})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"message": "bad id",
+		})
+	}
+
+	if err = restore(userId, id); err != nil {
+		return c.JSON(tr.errorsMapper.ApplicationErrorToHttp(err))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	httputils "interactive_learning/internal/http_utils"
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/usecase"
 	"net/http"
@@ -24,7 +25,7 @@ func (ur *UserRoutes) GetUserInfoById(c echo.Context) error {
 	var id int
 	var err error
 
-	userId, err := strconv.Atoi(c.QueryParam("user_id"))
+	userId, err := httputils.UserId(c)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"message": "bad user id",
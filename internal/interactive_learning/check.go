@@ -0,0 +1,52 @@
+package interactive_learning
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo/persistent"
+	"io"
+	"time"
+)
+
+// CheckCategories печатает категории, видимость которых расходится с выбором владельца,
+// и возвращает код выхода: 1, если есть модули с неизвестным типом или проверка не удалась
+func CheckCategories(out io.Writer) int {
+	db, err := sql.Open("postgres", connectionString())
+	if err != nil {
+		fmt.Fprintln(out, "open database:", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err = db.PingContext(ctx); err != nil {
+		fmt.Fprintln(out, "database not ready:", err)
+		return 1
+	}
+
+	issues, err := persistent.NewCategoryRepo(db).GetVisibilityIssues()
+	if err != nil {
+		fmt.Fprintln(out, "check failed:", err)
+		return 1
+	}
+
+	code := 0
+	for _, issue := range issues {
+		switch issue.Kind {
+		case entity.HiddenByPrivateModuleIssue:
+			fmt.Fprintf(out, "category %d: opened by owner, but private module %d makes it private\n", issue.CategoryId, issue.ModuleId)
+		case entity.ForeignPrivateModuleIssue:
+			fmt.Fprintf(out, "category %d: private module %d of another author is hidden from its viewers\n", issue.CategoryId, issue.ModuleId)
+		case entity.PrivateWithoutPrivateModulesIssue:
+			fmt.Fprintf(out, "category %d: private without private modules, closed by its owner or kept private by migration 24\n", issue.CategoryId)
+		case entity.UnknownModuleTypeIssue:
+			fmt.Fprintf(out, "module %d: unknown type\n", issue.ModuleId)
+			code = 1
+		}
+	}
+	fmt.Fprintf(out, "%d issues found\n", len(issues))
+	return code
+}
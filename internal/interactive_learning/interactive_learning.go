@@ -8,9 +8,12 @@ import (
 	"interactive_learning/internal/infrastructure"
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/migrator"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/repo/blobstore"
 	"interactive_learning/internal/repo/persistent"
 	"interactive_learning/internal/uow"
 	uowPersistent "interactive_learning/internal/uow/persistent"
+	"interactive_learning/internal/usecase"
 	interactivelearning "interactive_learning/internal/usecase/interactive_learning"
 	"log"
 	"os"
@@ -21,12 +24,16 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func Run(migrationsDir string, migrationsFS embed.FS, pathToStatic string) {
-	connectionString := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable",
+func connectionString() string {
+	return fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable",
 		os.Getenv("POSTGRES_USER"),
 		os.Getenv("POSTGRES_PASSWORD"),
 		os.Getenv("POSTGRES_ADDR"),
 		os.Getenv("POSTGRES_DB"))
+}
+
+func Run(migrationsDir string, migrationsFS embed.FS, pathToStatic string) {
+	connectionString := connectionString()
 
 	// first conn for migrations
 	db, err := sql.Open(
@@ -65,6 +72,11 @@ func Run(migrationsDir string, migrationsFS embed.FS, pathToStatic string) {
 		log.Fatal("database not ready")
 	}
 
+	blobStore, err := newBlobStore()
+	if err != nil {
+		log.Fatal("blob store not ready: ", err)
+	}
+
 	domainErrorsMapper := errors_mapper.NewDomainErrorsMapper()
 	applicationErrorsMapper := errors_mapper.NewApplicationErrorsMapper()
 
@@ -82,9 +94,22 @@ func Run(migrationsDir string, migrationsFS embed.FS, pathToStatic string) {
 		persistent.NewModulesResultsRepo(db),
 		persistent.NewCategoryModulesResultsRepo(db),
 		persistent.NewSelectedRepo(db),
+		persistent.NewAttachmentsRepo(db),
+		persistent.NewRevisionsRepo(db),
+		persistent.NewTagsRepo(db),
+		persistent.NewGrantsRepo(db),
+		persistent.NewGroupsRepo(db),
+		persistent.NewAssignmentsRepo(db),
+		persistent.NewFollowsRepo(db),
+		persistent.NewRatingsRepo(db),
+		persistent.NewCommentsRepo(db),
+		persistent.NewModerationRepo(db),
+		persistent.NewNotificationsRepo(db),
+		blobStore,
+		usecase.NewPolicy(),
 		domainErrorsMapper,
 	)
-	e := infrastructure.NewEcho(pathToStatic, us, us, us, us, us, us, us, us, applicationErrorsMapper)
+	e := infrastructure.NewEcho(pathToStatic, us, us, us, us, us, us, us, us, us, us, us, us, us, us, us, us, us, us, applicationErrorsMapper)
 
 	e.Use(middleware.Recover())
 	e.Use(middleware.Logger())
@@ -94,6 +119,10 @@ func Run(migrationsDir string, migrationsFS embed.FS, pathToStatic string) {
 		e.Start(":8080")
 	}()
 
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go purgeTrash(purgeCtx, us)
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	<-c
@@ -102,3 +131,45 @@ func Run(migrationsDir string, migrationsFS embed.FS, pathToStatic string) {
 	defer cancel()
 	e.Shutdown(ctx)
 }
+
+// сколько удаленные модули, категории и карточки лежат в корзине
+const (
+	trashRetention     = 30 * 24 * time.Hour
+	trashPurgeInterval = time.Hour
+)
+
+func purgeTrash(ctx context.Context, us *interactivelearning.UseCase) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := us.PurgeTrash(trashRetention); err != nil {
+			log.Println("trash purge failed: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// файлы карточек хранятся в S3-совместимом хранилище, если задан S3_ENDPOINT, иначе на диске
+func newBlobStore() (repo.BlobStore, error) {
+	if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+		return blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:  endpoint,
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}), nil
+	}
+
+	dir := os.Getenv("BLOB_DIR")
+	if dir == "" {
+		dir = "data/blobs"
+	}
+	return blobstore.NewLocalStore(dir)
+}
@@ -21,8 +21,13 @@ func (aem *ApplicationErrorsMapper) ApplicationErrorToHttp(err error) (int, map[
 		answerStatus = http.StatusUnauthorized
 	case errors.Is(err, usecase.ErrNotAvailable):
 		answerStatus = http.StatusNotAcceptable
+	case errors.Is(err, usecase.ConflictErr):
+		answerStatus = http.StatusConflict
+	case errors.Is(err, usecase.PreconditionFailedErr):
+		answerStatus = http.StatusPreconditionFailed
 	case errors.Is(err, usecase.ChangeTypeErr),
-		errors.Is(err, usecase.AlreadyExistsErr):
+		errors.Is(err, usecase.AlreadyExistsErr),
+		errors.Is(err, usecase.InvalidDataErr):
 		answerStatus = http.StatusBadRequest
 	default:
 		answerStatus = http.StatusInternalServerError
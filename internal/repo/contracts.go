@@ -3,6 +3,7 @@ package repo
 import (
 	"interactive_learning/internal/entity"
 	"interactive_learning/internal/utils/tokengenerator"
+	"io"
 	"time"
 )
 
@@ -17,10 +18,24 @@ type UsersRepoRead interface {
 	GetUserByLogin(login string) (entity.User, error)
 	GetUserInfoById(userId int) (entity.User, error)
 	IsContainsLogin(login string) (bool, error)
+	IsAdmin(userId int) (bool, error)
 }
 
 type UsersRepoWrite interface {
 	InsertUser(user entity.User) error
+	SetShareActivity(userId int, share bool) error
+}
+
+type FollowsRepoRead interface {
+	GetFollowers(userId int) ([]entity.User, error)
+	GetFollowing(userId int) ([]entity.User, error)
+	IsFollowing(followerId, followeeId int) (bool, error)
+	GetFeed(userId int, cursor *entity.FeedCursor, limit int) ([]entity.FeedItem, error)
+}
+
+type FollowsRepoWrite interface {
+	InsertFollow(followerId, followeeId int) error
+	DeleteFollow(followerId, followeeId int) error
 }
 
 type CardRepoRead interface {
@@ -28,28 +43,45 @@ type CardRepoRead interface {
 	GetCardById(cardId int) (entity.Card, error)
 	GetLastInsertedCardId() (int, error)
 	GetParentModuleId(cardId int) (int, error)
+	GetTrashedCards(ownerId int) ([]entity.TrashedCard, error)
+	GetTrashedCardById(cardId int) (entity.TrashedCard, error)
+	GetCardsDeletedBefore(deletedBefore time.Time) ([]int, error)
 }
 
 type CardRepoWrite interface {
 	InsertCard(card entity.Card) error
-	UpdateCard(card entity.Card) error
+	ReorderCards(moduleId int, cardsIds []int) error
+	UpdateCard(card entity.Card) (int, error)
+	TrashCard(cardId int) error
+	RestoreCard(cardId int) error
 	DeleteCard(cardId int) error
 	DeleteCardsToParentModule(moduleId int) error
 }
 
 type ModuleRepoRead interface {
-	GetModulesWithSimilarName(name string, limit, offset int) ([]entity.Module, error)
+	GetModulesWithSimilarName(name, tag string, limit, offset int) ([]entity.Module, error)
 	GetModulesByUser(userId int) ([]entity.Module, error)
 	GetModuleById(moduleId int) (entity.Module, error)
 	GetLastInsertedModuleId() (int, error)
 	GetModuleOwnerId(moduleId int) (int, error)
-	GetPopularModules(limit, offset int) ([]entity.PopularModule, error)
+	GetPopularModules(limit, offset int, sortBy string) ([]entity.PopularModule, error)
+	GetTrashedModules(ownerId int) ([]entity.TrashedModule, error)
+	GetTrashedModuleById(moduleId int) (entity.TrashedModule, error)
+	GetModulesDeletedBefore(deletedBefore time.Time) ([]int, error)
+	GetModuleShareSlug(moduleId int) (string, error)
+	GetModuleByShareSlug(slug string) (entity.Module, error)
 }
 
 type ModuleRepoWrite interface {
 	InsertModule(module entity.ModuleToCreate) error
-	RenameModule(moduleId int, newName string) error
-	UpdateModuleType(moduleId, newType int) error
+	RenameModule(moduleId, version int, newName string) (int, error)
+	UpdateModuleType(moduleId, version, newType int) (int, error)
+	UpdateModuleInfo(moduleId, version int, info entity.Info) (int, error)
+	SetModuleShareSlug(moduleId int, slug string) error
+	TouchModule(moduleId int) error
+	SetModuleHidden(moduleId int, hidden bool) error
+	TrashModule(moduleId int) error
+	RestoreModule(moduleId int) error
 	DeleteModule(moduleId int) error
 }
 
@@ -59,32 +91,118 @@ type CategoryRepoRead interface {
 	GetCategoryById(id int) (entity.Category, error)
 	GetLastInsertedCategoryId() (int, error)
 	GetCategoryOwnerId(categoryId int) (int, error)
-	GetPopularCategories(limit, offset int) ([]entity.PopularCategory, error)
+	GetPopularCategories(limit, offset int, sortBy string) ([]entity.PopularCategory, error)
+	GetTrashedCategories(ownerId int) ([]entity.TrashedCategory, error)
+	GetTrashedCategoryById(categoryId int) (entity.TrashedCategory, error)
+	GetCategoriesDeletedBefore(deletedBefore time.Time) ([]int, error)
+	GetCategoryShareSlug(categoryId int) (string, error)
+	GetCategoryByShareSlug(slug string) (entity.Category, error)
 }
 
 type CategoryRepoWrite interface {
 	InsertCategory(category entity.CategoryToCreate) error
 	RenameCategory(categoryId int, newName string) error
-	UpdateCategoryType(categoryId, categoryType int) error
-	TurnDownCategoryType(categoryId int) error
+	UpdateCategoryVisibility(categoryId, visibility int) error
+	UpdateCategoryInfo(categoryId int, info entity.Info) error
+	SetCategoryShareSlug(categoryId int, slug string) error
+	TouchCategory(categoryId int) error
+	SetCategoryHidden(categoryId int, hidden bool) error
+	TrashCategory(categoryId int) error
+	RestoreCategory(categoryId int) error
 	DeleteCategory(categoryId int) error
 }
 
 type CategoryModulesRepoRead interface {
 	GetModulesToCategory(categoryId int) ([]entity.Module, error)
-	GetCategoriesContainsModule(moduleId int) ([]entity.Category, error)
 }
 
 type CategoryModulesRepoWrite interface {
 	InsertModulesToCategory(categoryId, moduleId int) error
+	ReorderModules(categoryId int, modulesIds []int) error
 	DeleteModuleFromCategory(categoryId, moduleId int) error
 	DeleteAllModulesFromCategory(categoryId int) error
 	DeleteModuleFromCategories(moduleId int) error
 }
 
+type TagsRepoRead interface {
+	GetTagsByPrefix(prefix string, userId, limit int) ([]entity.TagCount, error)
+	GetPopularTags(limit, offset int) ([]entity.TagCount, error)
+}
+
+type TagsRepoWrite interface {
+	SetModuleTags(moduleId int, tags []string) error
+	SetCardTags(cardId int, tags []string) error
+}
+
+type GrantsRepoRead interface {
+	GetModuleGrants(moduleId int) ([]entity.Grant, error)
+	GetCategoryGrants(categoryId int) ([]entity.Grant, error)
+	GetModuleRoles(moduleId int) (map[int]string, error)
+	GetCategoryRoles(categoryId int) (map[int]string, error)
+	GetModulesSharedWithUser(userId int) ([]entity.Module, error)
+	GetCategoriesSharedWithUser(userId int) ([]entity.Category, error)
+}
+
+type GrantsRepoWrite interface {
+	SetModuleGrant(moduleId, userId int, role string) error
+	DeleteModuleGrant(moduleId, userId int) error
+	SetCategoryGrant(categoryId, userId int, role string) error
+	DeleteCategoryGrant(categoryId, userId int) error
+}
+
+type GroupsRepoRead interface {
+	GetGroupById(groupId int) (entity.Group, error)
+	GetGroupByJoinCode(code string) (entity.Group, error)
+	GetGroupsByUser(userId int) ([]entity.Group, error)
+	GetLastInsertedGroupId() (int, error)
+	GetGroupMembers(groupId int) ([]entity.GroupMember, error)
+	GetGroupRoles(groupId int) (map[int]string, error)
+	GetGroupModules(groupId int) ([]entity.Module, error)
+	GetGroupCategories(groupId int) ([]entity.Category, error)
+	GetGroupModulesResults(groupId int) ([]entity.ModuleResult, error)
+	GetGroupCategoriesResults(groupId int) ([]entity.CategoryModulesResult, error)
+	GetModuleTeachers(studentId, moduleId int) ([]int, error)
+	GetCategoryTeachers(studentId, categoryId int) ([]int, error)
+}
+
+type GroupsRepoWrite interface {
+	InsertGroup(group entity.Group) error
+	RenameGroup(groupId int, name string) error
+	SetGroupJoinCode(groupId int, code string) error
+	DeleteGroup(groupId int) error
+	InsertGroupMember(groupId, userId int, role string) error
+	DeleteGroupMember(groupId, userId int) error
+	InsertGroupModule(groupId, moduleId int) error
+	DeleteGroupModule(groupId, moduleId int) error
+	InsertGroupCategory(groupId, categoryId int) error
+	DeleteGroupCategory(groupId, categoryId int) error
+}
+
+type AssignmentsRepoRead interface {
+	GetAssignmentById(assignmentId int) (entity.Assignment, error)
+	GetLastInsertedAssignmentId() (int, error)
+	GetGroupAssignments(groupId int) ([]entity.Assignment, error)
+	GetStudentModuleAssignments(userId, moduleId int) ([]entity.Assignment, error)
+	GetStudentCategoryAssignments(userId, categoryId int) ([]entity.Assignment, error)
+	GetStudentAssignments(userId int) ([]entity.StudentAssignment, error)
+	GetSubmissions(assignmentId int) ([]entity.Submission, error)
+	GetUserSubmissions(userId int) ([]entity.Submission, error)
+}
+
+type AssignmentsRepoWrite interface {
+	InsertAssignment(assignment entity.Assignment) error
+	DeleteAssignment(assignmentId int) error
+	InsertSubmission(submission entity.Submission) error
+	DeleteModuleResultSubmissions(resultId int) error
+	DeleteCategoryResultSubmissions(categoryResultId int) error
+	// удаляет засчитанные прохождения категорий, от которых не осталось результатов
+	DeleteOrphanedCategorySubmissions() error
+}
+
 type ResultsRepoRead interface {
 	GetResultsByOwner(ownerId int) ([]entity.Result, error)
 	GetResultById(id int) (entity.Result, error)
+	GetResultOwnerAndModule(resultId int) (int, int, error)
 	GetLastInsertedResultId() (int, error)
 }
 
@@ -98,10 +216,9 @@ type CardsResultsRepoRead interface {
 }
 
 type CardsResultsRepoWrite interface {
-	InsertCardResult(resultId, cardId int, result string) error
+	InsertCardResult(resultId int, cardResult entity.CardsResult) error
 	DeleteCardResult(resultId, cardId int) error
 	DeleteCardsToResult(resultId int) error
-	DeleteResultsToCard(cardId int) error
 }
 
 type ModulesResultsRepoRead interface {
@@ -149,3 +266,103 @@ type SelectedRepoWrite interface {
 	DeleteModuleToUser(userId, moduleId int) error
 	DeleteCategoryToUser(userId, categoryId int) error
 }
+
+type IdempotencyRepoRead interface {
+	GetIdempotencyKey(ownerId int, key string, createdAfter time.Time) (entity.IdempotencyKey, error)
+}
+
+type IdempotencyRepoWrite interface {
+	InsertIdempotencyKey(key entity.IdempotencyKey) error
+	DeleteExpiredIdempotencyKeys(createdBefore time.Time) error
+}
+
+type SyncedItemsRepoRead interface {
+	GetSyncedItem(ownerId int, clientId string) (entity.SyncedItem, error)
+}
+
+type SyncedItemsRepoWrite interface {
+	InsertSyncedItem(item entity.SyncedItem) error
+}
+
+type AttachmentsRepoRead interface {
+	GetAttachmentById(attachmentId int) (entity.Attachment, error)
+	GetAttachmentsToCard(cardId int) ([]entity.Attachment, error)
+	GetAttachmentsToModule(moduleId int) ([]entity.Attachment, error)
+	GetLastInsertedAttachmentId() (int, error)
+}
+
+type AttachmentsRepoWrite interface {
+	InsertAttachment(attachment entity.Attachment) error
+	DeleteAttachment(attachmentId int) error
+	DeleteAttachmentsToCard(cardId int) error
+	DeleteAttachmentsToModule(moduleId int) error
+}
+
+// BlobStore хранит содержимое файлов по ключу
+type BlobStore interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+type RevisionsRepoRead interface {
+	GetCardRevisions(cardId int) ([]entity.CardRevision, error)
+	GetCardRevisionById(revisionId int) (entity.CardRevision, error)
+	GetModuleRevisions(moduleId int) ([]entity.ModuleRevision, error)
+}
+
+type RevisionsRepoWrite interface {
+	InsertCardRevision(revision entity.CardRevision) error
+	InsertModuleRevision(revision entity.ModuleRevision) error
+}
+
+type RatingsRepoRead interface {
+	GetModuleRating(moduleId, userId int) (entity.RatingSummary, error)
+	GetCategoryRating(categoryId, userId int) (entity.RatingSummary, error)
+}
+
+type RatingsRepoWrite interface {
+	SetModuleRating(moduleId, userId, rating int) error
+	SetCategoryRating(categoryId, userId, rating int) error
+	DeleteModuleRating(moduleId, userId int) error
+	DeleteCategoryRating(categoryId, userId int) error
+}
+
+type CommentsRepoRead interface {
+	GetCommentById(id int) (entity.Comment, error)
+	GetModuleComments(moduleId, limit, offset int) ([]entity.Comment, error)
+	GetCategoryComments(categoryId, limit, offset int) ([]entity.Comment, error)
+	GetLastInsertedCommentId() (int, error)
+}
+
+type CommentsRepoWrite interface {
+	InsertModuleComment(moduleId, userId int, text string) error
+	InsertCategoryComment(categoryId, userId int, text string) error
+	UpdateCommentText(id int, text string) error
+	SetCommentHidden(id int, hidden bool) error
+	DeleteComment(id int) error
+}
+
+type ModerationRepoRead interface {
+	GetReportById(reportId int) (entity.Report, error)
+	GetReports(status string, limit, offset int) ([]entity.Report, error)
+	HasOpenReport(targetKind string, targetId, reporterId int) (bool, error)
+	GetLastInsertedReportId() (int, error)
+	GetModerationLog(limit, offset int) ([]entity.ModerationEntry, error)
+}
+
+type ModerationRepoWrite interface {
+	InsertReport(report entity.Report) error
+	ResolveReport(reportId, adminId int, status string) error
+	ResolveTargetReports(targetKind string, targetId, adminId int, status string) error
+	InsertModerationEntry(entry entity.ModerationEntry) error
+}
+
+type NotificationsRepoRead interface {
+	GetNotifications(userId, limit, offset int) ([]entity.Notification, error)
+}
+
+type NotificationsRepoWrite interface {
+	InsertNotification(notification entity.Notification) error
+	MarkNotificationRead(notificationId, userId int) error
+}
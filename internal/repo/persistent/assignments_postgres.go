@@ -0,0 +1,206 @@
+package persistent
+
+import (
+	"database/sql"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type AssignmentsRepo struct {
+	psql repo.PSQL
+}
+
+func NewAssignmentsRepo(psql repo.PSQL) *AssignmentsRepo {
+	return &AssignmentsRepo{psql: psql}
+}
+
+const assignmentsColumns = "assignments.id, assignments.group_id, assignments.module_id, assignments.category_id, " +
+	"assignments.mode, assignments.min_score, assignments.due_at, assignments.created_at"
+
+func scanAssignment(row rowScanner, a *entity.Assignment, extra ...any) error {
+	dest := append([]any{&a.Id, &a.GroupId, &a.ModuleId, &a.CategoryId, &a.Mode, &a.MinScore, &a.DueAt, &a.CreatedAt}, extra...)
+	return row.Scan(dest...)
+}
+
+func (ar *AssignmentsRepo) GetAssignmentById(assignmentId int) (entity.Assignment, error) {
+	row := ar.psql.QueryRow("SELECT "+assignmentsColumns+" FROM assignments WHERE assignments.id = $1", assignmentId)
+
+	a := entity.Assignment{}
+	if err := scanAssignment(row, &a); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Assignment{}, repo.NoSuchRecordToSelect
+		}
+		return entity.Assignment{}, repo.NewDBError("assignments", "select", err)
+	}
+	return a, nil
+}
+
+func (ar *AssignmentsRepo) GetLastInsertedAssignmentId() (int, error) {
+	row := ar.psql.QueryRow("SELECT MAX(id) FROM assignments")
+
+	var last_id int
+	if err := row.Scan(&last_id); err != nil {
+		return -1, repo.NewDBError("assignments", "select", err)
+	}
+	return last_id, nil
+}
+
+func (ar *AssignmentsRepo) GetGroupAssignments(groupId int) ([]entity.Assignment, error) {
+	return ar.getAssignments("SELECT "+assignmentsColumns+" FROM assignments "+
+		"WHERE assignments.group_id = $1 ORDER BY assignments.due_at", groupId)
+}
+
+// GetStudentModuleAssignments возвращает задания по модулю в группах, где пользователь учится
+func (ar *AssignmentsRepo) GetStudentModuleAssignments(userId, moduleId int) ([]entity.Assignment, error) {
+	return ar.getAssignments("SELECT "+assignmentsColumns+" FROM assignments "+
+		"INNER JOIN group_members ON group_members.group_id = assignments.group_id "+
+		"WHERE group_members.user_id = $1 AND group_members.role = $3 AND assignments.module_id = $2",
+		userId, moduleId, entity.StudentRole)
+}
+
+// GetStudentCategoryAssignments возвращает задания по категории в группах, где пользователь учится
+func (ar *AssignmentsRepo) GetStudentCategoryAssignments(userId, categoryId int) ([]entity.Assignment, error) {
+	return ar.getAssignments("SELECT "+assignmentsColumns+" FROM assignments "+
+		"INNER JOIN group_members ON group_members.group_id = assignments.group_id "+
+		"WHERE group_members.user_id = $1 AND group_members.role = $3 AND assignments.category_id = $2",
+		userId, categoryId, entity.StudentRole)
+}
+
+func (ar *AssignmentsRepo) getAssignments(query string, args ...any) ([]entity.Assignment, error) {
+	rows, err := ar.psql.Query(query, args...)
+	if err != nil {
+		return []entity.Assignment{}, repo.NewDBError("assignments", "select", err)
+	}
+	defer rows.Close()
+
+	assignments := []entity.Assignment{}
+	for rows.Next() {
+		a := entity.Assignment{}
+		if err = scanAssignment(rows, &a); err != nil {
+			return []entity.Assignment{}, repo.NewDBError("assignments", "select", err)
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, nil
+}
+
+// GetStudentAssignments возвращает задания всех групп, где пользователь учится
+func (ar *AssignmentsRepo) GetStudentAssignments(userId int) ([]entity.StudentAssignment, error) {
+	rows, err := ar.psql.Query("SELECT "+assignmentsColumns+", groups.name FROM assignments "+
+		"INNER JOIN groups ON groups.id = assignments.group_id "+
+		"INNER JOIN group_members ON group_members.group_id = assignments.group_id "+
+		"WHERE group_members.user_id = $1 AND group_members.role = $2 "+
+		"ORDER BY assignments.due_at", userId, entity.StudentRole)
+	if err != nil {
+		return []entity.StudentAssignment{}, repo.NewDBError("assignments", "select", err)
+	}
+	defer rows.Close()
+
+	assignments := []entity.StudentAssignment{}
+	for rows.Next() {
+		a := entity.StudentAssignment{}
+		if err = scanAssignment(rows, &a.Assignment, &a.GroupName); err != nil {
+			return []entity.StudentAssignment{}, repo.NewDBError("assignments", "select", err)
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, nil
+}
+
+const submissionsQuery = "SELECT assignment_submissions.id, assignment_submissions.assignment_id, assignment_submissions.user_id, users.login, " +
+	"assignment_submissions.result_id, assignment_submissions.category_result_id, assignment_submissions.score, assignment_submissions.passed, " +
+	"assignment_submissions.submitted_at > assignments.due_at, assignment_submissions.submitted_at " +
+	"FROM assignment_submissions " +
+	"INNER JOIN assignments ON assignments.id = assignment_submissions.assignment_id " +
+	"INNER JOIN users ON users.id = assignment_submissions.user_id "
+
+func (ar *AssignmentsRepo) GetSubmissions(assignmentId int) ([]entity.Submission, error) {
+	return ar.getSubmissions(submissionsQuery+
+		"WHERE assignment_submissions.assignment_id = $1 ORDER BY assignment_submissions.submitted_at", assignmentId)
+}
+
+func (ar *AssignmentsRepo) GetUserSubmissions(userId int) ([]entity.Submission, error) {
+	return ar.getSubmissions(submissionsQuery+
+		"WHERE assignment_submissions.user_id = $1 ORDER BY assignment_submissions.submitted_at", userId)
+}
+
+func (ar *AssignmentsRepo) getSubmissions(query string, args ...any) ([]entity.Submission, error) {
+	rows, err := ar.psql.Query(query, args...)
+	if err != nil {
+		return []entity.Submission{}, repo.NewDBError("assignment_submissions", "select", err)
+	}
+	defer rows.Close()
+
+	submissions := []entity.Submission{}
+	for rows.Next() {
+		s := entity.Submission{}
+		err = rows.Scan(&s.Id, &s.AssignmentId, &s.UserId, &s.Login,
+			&s.ResultId, &s.CategoryResultId, &s.Score, &s.Passed, &s.Late, &s.SubmittedAt)
+		if err != nil {
+			return []entity.Submission{}, repo.NewDBError("assignment_submissions", "select", err)
+		}
+		submissions = append(submissions, s)
+	}
+	return submissions, nil
+}
+
+func (ar *AssignmentsRepo) InsertAssignment(assignment entity.Assignment) error {
+	result, err := ar.psql.Exec("INSERT INTO assignments(group_id, module_id, category_id, mode, min_score, due_at) "+
+		"VALUES($1, $2, $3, $4, $5, $6)",
+		assignment.GroupId, assignment.ModuleId, assignment.CategoryId, assignment.Mode, assignment.MinScore, assignment.DueAt)
+	if err != nil {
+		return repo.NewDBError("assignments", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (ar *AssignmentsRepo) DeleteAssignment(assignmentId int) error {
+	result, err := ar.psql.Exec("DELETE FROM assignments WHERE id = $1", assignmentId)
+	if err != nil {
+		return repo.NewDBError("assignments", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
+
+func (ar *AssignmentsRepo) InsertSubmission(submission entity.Submission) error {
+	result, err := ar.psql.Exec("INSERT INTO assignment_submissions(assignment_id, user_id, result_id, category_result_id, score, passed) "+
+		"VALUES($1, $2, $3, $4, $5, $6)",
+		submission.AssignmentId, submission.UserId, submission.ResultId, submission.CategoryResultId, submission.Score, submission.Passed)
+	if err != nil {
+		return repo.NewDBError("assignment_submissions", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+// удаленный результат перестает засчитываться в задания
+func (ar *AssignmentsRepo) DeleteModuleResultSubmissions(resultId int) error {
+	_, err := ar.psql.Exec("DELETE FROM assignment_submissions WHERE result_id = $1", resultId)
+	if err != nil {
+		return repo.NewDBError("assignment_submissions", "delete", err)
+	}
+	return nil
+}
+
+func (ar *AssignmentsRepo) DeleteCategoryResultSubmissions(categoryResultId int) error {
+	_, err := ar.psql.Exec("DELETE FROM assignment_submissions WHERE category_result_id = $1", categoryResultId)
+	if err != nil {
+		return repo.NewDBError("assignment_submissions", "delete", err)
+	}
+	return nil
+}
+
+func (ar *AssignmentsRepo) DeleteOrphanedCategorySubmissions() error {
+	_, err := ar.psql.Exec("DELETE FROM assignment_submissions WHERE category_result_id IS NOT NULL " +
+		"AND NOT EXISTS (SELECT 1 FROM category_res WHERE category_res.category_result_id = assignment_submissions.category_result_id)")
+	if err != nil {
+		return repo.NewDBError("assignment_submissions", "delete", err)
+	}
+	return nil
+}
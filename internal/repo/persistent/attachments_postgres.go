@@ -0,0 +1,110 @@
+package persistent
+
+import (
+	"database/sql"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type AttachmentsRepo struct {
+	psql repo.PSQL
+}
+
+func NewAttachmentsRepo(psql repo.PSQL) *AttachmentsRepo {
+	return &AttachmentsRepo{psql: psql}
+}
+
+const attachmentsColumns = "card_attachments.id, card_attachments.card_id, card_attachments.owner, card_attachments.side, " +
+	"card_attachments.kind, card_attachments.content_type, card_attachments.size, card_attachments.blob_key, card_attachments.created_at"
+
+func (ar *AttachmentsRepo) GetAttachmentById(attachmentId int) (entity.Attachment, error) {
+	row := ar.psql.QueryRow("SELECT "+attachmentsColumns+" FROM card_attachments WHERE id = $1", attachmentId)
+
+	a := entity.Attachment{}
+	err := row.Scan(&a.Id, &a.CardId, &a.OwnerId, &a.Side, &a.Kind, &a.ContentType, &a.Size, &a.Key, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Attachment{}, repo.NoSuchRecordToSelect
+		}
+		return entity.Attachment{}, repo.NewDBError("card_attachments", "select", err)
+	}
+	return a, nil
+}
+
+func (ar *AttachmentsRepo) GetAttachmentsToCard(cardId int) ([]entity.Attachment, error) {
+	return ar.getAttachments("SELECT "+attachmentsColumns+" FROM card_attachments "+
+		"WHERE card_id = $1 ORDER BY id", cardId)
+}
+
+func (ar *AttachmentsRepo) GetAttachmentsToModule(moduleId int) ([]entity.Attachment, error) {
+	return ar.getAttachments("SELECT "+attachmentsColumns+" FROM card_attachments "+
+		"INNER JOIN cards ON cards.id = card_attachments.card_id "+
+		"WHERE cards.module_id = $1 ORDER BY card_attachments.id", moduleId)
+}
+
+func (ar *AttachmentsRepo) getAttachments(query string, args ...any) ([]entity.Attachment, error) {
+	rows, err := ar.psql.Query(query, args...)
+	if err != nil {
+		return []entity.Attachment{}, repo.NewDBError("card_attachments", "select", err)
+	}
+	defer rows.Close()
+
+	attachments := []entity.Attachment{}
+	for rows.Next() {
+		a := entity.Attachment{}
+		err = rows.Scan(&a.Id, &a.CardId, &a.OwnerId, &a.Side, &a.Kind, &a.ContentType, &a.Size, &a.Key, &a.CreatedAt)
+		if err != nil {
+			return []entity.Attachment{}, repo.NewDBError("card_attachments", "select", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+func (ar *AttachmentsRepo) GetLastInsertedAttachmentId() (int, error) {
+	row := ar.psql.QueryRow("SELECT MAX(id) FROM card_attachments")
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return -1, repo.NewDBError("card_attachments", "select", err)
+	}
+	return id, nil
+}
+
+func (ar *AttachmentsRepo) InsertAttachment(a entity.Attachment) error {
+	result, err := ar.psql.Exec("INSERT INTO card_attachments(card_id, owner, side, kind, content_type, size, blob_key) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7)", a.CardId, a.OwnerId, a.Side, a.Kind, a.ContentType, a.Size, a.Key)
+	if err != nil {
+		return repo.NewDBError("card_attachments", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (ar *AttachmentsRepo) DeleteAttachment(attachmentId int) error {
+	result, err := ar.psql.Exec("DELETE FROM card_attachments WHERE id = $1", attachmentId)
+	if err != nil {
+		return repo.NewDBError("card_attachments", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
+
+func (ar *AttachmentsRepo) DeleteAttachmentsToCard(cardId int) error {
+	_, err := ar.psql.Exec("DELETE FROM card_attachments WHERE card_id = $1", cardId)
+	if err != nil {
+		return repo.NewDBError("card_attachments", "delete", err)
+	}
+	return nil
+}
+
+func (ar *AttachmentsRepo) DeleteAttachmentsToModule(moduleId int) error {
+	_, err := ar.psql.Exec("DELETE FROM card_attachments "+
+		"USING cards WHERE cards.id = card_attachments.card_id AND cards.module_id = $1", moduleId)
+	if err != nil {
+		return repo.NewDBError("card_attachments", "delete", err)
+	}
+	return nil
+}
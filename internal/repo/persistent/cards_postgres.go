@@ -1,10 +1,32 @@
 package persistent
 
 import (
+	"database/sql"
+	"errors"
 	"interactive_learning/internal/entity"
 	"interactive_learning/internal/repo"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const cardsColumns = "cards.id, cards.module_id, cards.term_lang, cards.term_text, cards.def_lang, cards.def_text, cards.position, " +
+	"ARRAY(SELECT card_tags.tag FROM card_tags WHERE card_tags.card_id = cards.id ORDER BY card_tags.tag), cards.version"
+
+// scanCard читает колонки cardsColumns, extra - колонки после них
+func scanCard(row rowScanner, c *entity.Card, extra ...any) error {
+	dest := append([]any{&c.Id,
+		&c.ParentModule,
+		&c.Term.Lang,
+		&c.Term.Text,
+		&c.Definition.Lang,
+		&c.Definition.Text,
+		&c.Position,
+		pq.Array(&c.Tags),
+		&c.Version}, extra...)
+	return row.Scan(dest...)
+}
+
 type CardsRepo struct {
 	psql repo.PSQL
 }
@@ -14,7 +36,8 @@ func NewCardsRepo(psql repo.PSQL) *CardsRepo {
 }
 
 func (cr *CardsRepo) GetCardsByModule(moduleId int) ([]entity.Card, error) {
-	rows, err := cr.psql.Query("SELECT * FROM cards WHERE module_id = $1", moduleId)
+	rows, err := cr.psql.Query("SELECT "+cardsColumns+" FROM cards WHERE module_id = $1 AND deleted_at IS NULL "+
+		"ORDER BY position, id", moduleId)
 	if err != nil {
 		return []entity.Card{}, repo.NewDBError("cards", "select", err)
 	}
@@ -23,12 +46,7 @@ func (cr *CardsRepo) GetCardsByModule(moduleId int) ([]entity.Card, error) {
 	cards := []entity.Card{}
 	for rows.Next() {
 		c := entity.Card{}
-		err = rows.Scan(&c.Id,
-			&c.ParentModule,
-			&c.Term.Lang,
-			&c.Term.Text,
-			&c.Definition.Lang,
-			&c.Definition.Text)
+		err = scanCard(rows, &c)
 		if err != nil {
 			return []entity.Card{}, repo.NewDBError("cards", "select", err)
 		}
@@ -39,15 +57,14 @@ func (cr *CardsRepo) GetCardsByModule(moduleId int) ([]entity.Card, error) {
 }
 
 func (cr *CardsRepo) GetCardById(cardId int) (entity.Card, error) {
-	row := cr.psql.QueryRow("SELECT * FROM cards WHERE id = $1", cardId)
+	row := cr.psql.QueryRow("SELECT "+cardsColumns+" FROM cards INNER JOIN modules ON modules.id = cards.module_id "+
+		"WHERE cards.id = $1 AND cards.deleted_at IS NULL AND modules.deleted_at IS NULL", cardId)
 	c := entity.Card{}
-	err := row.Scan(&c.Id,
-		&c.ParentModule,
-		&c.Term.Lang,
-		&c.Term.Text,
-		&c.Definition.Lang,
-		&c.Definition.Text)
+	err := scanCard(row, &c)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Card{}, repo.NoSuchRecordToSelect
+		}
 		return entity.Card{}, repo.NewDBError("cards", "select", err)
 	}
 	return c, nil
@@ -64,18 +81,63 @@ func (cr *CardsRepo) GetLastInsertedCardId() (int, error) {
 }
 
 func (cr *CardsRepo) GetParentModuleId(cardId int) (int, error) {
-	row := cr.psql.QueryRow("SELECT module_id FROM cards WHERE id = $1", cardId)
+	row := cr.psql.QueryRow("SELECT module_id FROM cards WHERE id = $1 AND deleted_at IS NULL", cardId)
 	var id int
 	err := row.Scan(&id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, repo.NoSuchRecordToSelect
+		}
 		return -1, repo.NewDBError("cards", "select", err)
 	}
 	return id, nil
 }
 
+// в корзине показываются карточки, удаленные по одной: карточки удаленного модуля лежат в нем
+func (cr *CardsRepo) GetTrashedCards(ownerId int) ([]entity.TrashedCard, error) {
+	rows, err := cr.psql.Query("SELECT "+cardsColumns+", cards.deleted_at "+
+		"FROM cards INNER JOIN modules ON modules.id = cards.module_id "+
+		"WHERE modules.owner_id = $1 AND modules.deleted_at IS NULL AND cards.deleted_at IS NOT NULL "+
+		"ORDER BY cards.deleted_at DESC", ownerId)
+	if err != nil {
+		return []entity.TrashedCard{}, repo.NewDBError("cards", "select", err)
+	}
+	defer rows.Close()
+
+	cards := []entity.TrashedCard{}
+	for rows.Next() {
+		c := entity.TrashedCard{}
+		err = scanCard(rows, &c.Card, &c.DeletedAt)
+		if err != nil {
+			return []entity.TrashedCard{}, repo.NewDBError("cards", "select", err)
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+func (cr *CardsRepo) GetTrashedCardById(cardId int) (entity.TrashedCard, error) {
+	row := cr.psql.QueryRow("SELECT "+cardsColumns+", deleted_at FROM cards WHERE id = $1 AND deleted_at IS NOT NULL", cardId)
+	c := entity.TrashedCard{}
+	err := scanCard(row, &c.Card, &c.DeletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.TrashedCard{}, repo.NoSuchRecordToSelect
+		}
+		return entity.TrashedCard{}, repo.NewDBError("cards", "select", err)
+	}
+	return c, nil
+}
+
+func (cr *CardsRepo) GetCardsDeletedBefore(deletedBefore time.Time) ([]int, error) {
+	return selectIds(cr.psql, "cards", "SELECT id FROM cards WHERE deleted_at < $1", deletedBefore)
+}
+
 func (cr *CardsRepo) InsertCard(card entity.Card) error {
-	result, err := cr.psql.Exec("INSERT INTO cards(module_id, term_lang, term_text, def_lang, def_text) "+
-		"VALUES($1, $2, $3, $4, $5)", card.ParentModule, card.Term.Lang, card.Term.Text, card.Definition.Lang, card.Definition.Text)
+	// новая карточка встает в конец модуля
+	result, err := cr.psql.Exec("INSERT INTO cards(module_id, term_lang, term_text, def_lang, def_text, position) "+
+		"VALUES($1, $2, $3, $4, $5, (SELECT COALESCE(MAX(position) + 1, 0) FROM cards WHERE module_id = $1))",
+		card.ParentModule, card.Term.Lang, card.Term.Text, card.Definition.Lang, card.Definition.Text)
 	if err != nil {
 		return repo.NewDBError("cards", "insert", err)
 	} else if count, _ := result.RowsAffected(); count == 0 {
@@ -84,10 +146,51 @@ func (cr *CardsRepo) InsertCard(card entity.Card) error {
 	return nil
 }
 
-func (cr *CardsRepo) UpdateCard(card entity.Card) error {
+// ReorderCards расставляет карточки модуля в порядке cardsIds
+func (cr *CardsRepo) ReorderCards(moduleId int, cardsIds []int) error {
+	result, err := cr.psql.Exec("UPDATE cards "+
+		"SET position = ordered.pos - 1 "+
+		"FROM unnest($2::int[]) WITH ORDINALITY AS ordered(id, pos) "+
+		"WHERE cards.id = ordered.id AND cards.module_id = $1", moduleId, pq.Array(cardsIds))
+	if err != nil {
+		return repo.NewDBError("cards", "update", err)
+	} else if count, _ := result.RowsAffected(); count != int64(len(cardsIds)) {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+// UpdateCard меняет карточку, только если ее версия все еще card.Version, и возвращает новую версию
+func (cr *CardsRepo) UpdateCard(card entity.Card) (int, error) {
+	var version int
+	err := cr.psql.QueryRow("UPDATE cards "+
+		"SET term_lang = $1, term_text = $2, def_lang = $3, def_text = $4, version = version + 1 "+
+		"WHERE id = $5 AND version = $6 "+
+		"RETURNING version", card.Term.Lang, card.Term.Text, card.Definition.Lang, card.Definition.Text, card.Id, card.Version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repo.NoSuchRecordToUpdate
+	} else if err != nil {
+		return 0, repo.NewDBError("cards", "update", err)
+	}
+	return version, nil
+}
+
+func (cr *CardsRepo) TrashCard(cardId int) error {
+	result, err := cr.psql.Exec("UPDATE cards "+
+		"SET deleted_at = NOW() "+
+		"WHERE id = $1 AND deleted_at IS NULL", cardId)
+	if err != nil {
+		return repo.NewDBError("cards", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (cr *CardsRepo) RestoreCard(cardId int) error {
 	result, err := cr.psql.Exec("UPDATE cards "+
-		"SET term_lang = $1, term_text = $2, def_lang = $3, def_text = $4 "+
-		"WHERE id = $5", card.Term.Lang, card.Term.Text, card.Definition.Lang, card.Definition.Text, card.Id)
+		"SET deleted_at = NULL "+
+		"WHERE id = $1 AND deleted_at IS NOT NULL", cardId)
 	if err != nil {
 		return repo.NewDBError("cards", "update", err)
 	} else if count, _ := result.RowsAffected(); count == 0 {
@@ -14,7 +14,10 @@ func NewCardsResultsRepo(psql repo.PSQL) *CardsResultsRepo {
 }
 
 func (crr *CardsResultsRepo) GetCardsResultById(resultId int) ([]entity.CardsResult, error) {
-	rows, err := crr.psql.Query("SELECT card_id, result FROM cards_results WHERE result_id = $1", resultId)
+	rows, err := crr.psql.Query("SELECT cards_results.card_id, cards_results.result, "+
+		"cards_results.term_lang, cards_results.term_text, cards_results.def_lang, cards_results.def_text, (cards.id IS NULL OR cards.deleted_at IS NOT NULL) "+
+		"FROM cards_results LEFT JOIN cards ON cards.id = cards_results.card_id "+
+		"WHERE cards_results.result_id = $1", resultId)
 	if err != nil {
 		return []entity.CardsResult{}, repo.NewDBError("cards_results", "select", err)
 	}
@@ -23,7 +26,12 @@ func (crr *CardsResultsRepo) GetCardsResultById(resultId int) ([]entity.CardsRes
 	for rows.Next() {
 		card_result := entity.CardsResult{}
 		err := rows.Scan(&card_result.CardId,
-			&card_result.Result)
+			&card_result.Result,
+			&card_result.Term.Lang,
+			&card_result.Term.Text,
+			&card_result.Definition.Lang,
+			&card_result.Definition.Text,
+			&card_result.IsCardDeleted)
 		if err != nil {
 			return []entity.CardsResult{}, repo.NewDBError("cards", "select", err)
 		}
@@ -34,9 +42,10 @@ func (crr *CardsResultsRepo) GetCardsResultById(resultId int) ([]entity.CardsRes
 	return cards_results, nil
 }
 
-func (crr *CardsResultsRepo) InsertCardResult(resultId, cardId int, resultStr string) error {
-	result, err := crr.psql.Exec("INSERT INTO cards_results(result_id, card_id, result) "+
-		"VALUES($1, $2, $3)", resultId, cardId, resultStr)
+func (crr *CardsResultsRepo) InsertCardResult(resultId int, cardResult entity.CardsResult) error {
+	result, err := crr.psql.Exec("INSERT INTO cards_results(result_id, card_id, result, term_lang, term_text, def_lang, def_text) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7)", resultId, cardResult.CardId, cardResult.Result,
+		cardResult.Term.Lang, cardResult.Term.Text, cardResult.Definition.Lang, cardResult.Definition.Text)
 	if err != nil {
 		return repo.NewDBError("cards_results", "insert", err)
 	}
@@ -63,11 +72,3 @@ func (crr *CardsResultsRepo) DeleteCardsToResult(resultId int) error {
 	}
 	return nil
 }
-
-func (crr *CardsResultsRepo) DeleteResultsToCard(cardId int) error {
-	_, err := crr.psql.Exec("DELETE FROM cards_results WHERE card_id = $1", cardId)
-	if err != nil {
-		return repo.NewDBError("cards_results", "delete", err)
-	}
-	return nil
-}
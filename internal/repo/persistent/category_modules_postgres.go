@@ -3,6 +3,8 @@ package persistent
 import (
 	"interactive_learning/internal/entity"
 	"interactive_learning/internal/repo"
+
+	"github.com/lib/pq"
 )
 
 type CategoryModulesRepo struct {
@@ -14,8 +16,9 @@ func NewCategoryModulesRepo(psql repo.PSQL) *CategoryModulesRepo {
 }
 
 func (cmr *CategoryModulesRepo) GetModulesToCategory(categoryId int) ([]entity.Module, error) {
-	rows, err := cmr.psql.Query("SELECT id, name, owner_id, type FROM category_modules LEFT JOIN modules ON modules.id = category_modules.module_id "+
-		"WHERE category_id = $1", categoryId)
+	rows, err := cmr.psql.Query("SELECT "+modulesColumns+" FROM category_modules INNER JOIN modules ON modules.id = category_modules.module_id "+
+		"WHERE category_id = $1 AND modules.deleted_at IS NULL "+
+		"ORDER BY category_modules.position, modules.id", categoryId)
 	if err != nil {
 		return []entity.Module{}, repo.NewDBError("category_modules", "select", err)
 	}
@@ -23,7 +26,7 @@ func (cmr *CategoryModulesRepo) GetModulesToCategory(categoryId int) ([]entity.M
 	modules := []entity.Module{}
 	for rows.Next() {
 		m := entity.Module{}
-		err := rows.Scan(&m.Id, &m.Name, &m.OwnerId, &m.Type)
+		err := scanModule(rows, &m)
 		if err != nil {
 			return []entity.Module{}, repo.NewDBError("category_modules", "select", err)
 		}
@@ -33,29 +36,9 @@ func (cmr *CategoryModulesRepo) GetModulesToCategory(categoryId int) ([]entity.M
 	return modules, nil
 }
 
-func (cmr *CategoryModulesRepo) GetCategoriesContainsModule(moduleId int) ([]entity.Category, error) {
-	rows, err := cmr.psql.Query("SELECT id, name, owner_id, type FROM category_modules LEFT JOIN categories ON categories.id = category_modules.category_id "+
-		"WHERE module_id = $1", moduleId)
-	if err != nil {
-		return []entity.Category{}, repo.NewDBError("category_modules", "select", err)
-	}
-
-	categories := []entity.Category{}
-	for rows.Next() {
-		c := entity.Category{}
-		err := rows.Scan(&c.Id, &c.Name, &c.OwnerId, &c.Type)
-		if err != nil {
-			return []entity.Category{}, repo.NewDBError("category_modules", "select", err)
-		}
-		categories = append(categories, c)
-	}
-
-	return categories, nil
-}
-
 func (cmr *CategoryModulesRepo) InsertModulesToCategory(categoryId, moduleId int) error {
-	result, err := cmr.psql.Exec("INSERT INTO category_modules(category_id, module_id) "+
-		"VALUES($1, $2)", categoryId, moduleId)
+	result, err := cmr.psql.Exec("INSERT INTO category_modules(category_id, module_id, position) "+
+		"VALUES($1, $2, (SELECT COALESCE(MAX(position) + 1, 0) FROM category_modules WHERE category_id = $1))", categoryId, moduleId)
 	if err != nil {
 		return repo.NewDBError("category_modules", "insert", err)
 	}
@@ -65,6 +48,20 @@ func (cmr *CategoryModulesRepo) InsertModulesToCategory(categoryId, moduleId int
 	return nil
 }
 
+// ReorderModules расставляет модули категории в порядке modulesIds
+func (cmr *CategoryModulesRepo) ReorderModules(categoryId int, modulesIds []int) error {
+	result, err := cmr.psql.Exec("UPDATE category_modules "+
+		"SET position = ordered.pos - 1 "+
+		"FROM unnest($2::int[]) WITH ORDINALITY AS ordered(id, pos) "+
+		"WHERE category_modules.category_id = $1 AND category_modules.module_id = ordered.id", categoryId, pq.Array(modulesIds))
+	if err != nil {
+		return repo.NewDBError("category_modules", "update", err)
+	} else if count, _ := result.RowsAffected(); count != int64(len(modulesIds)) {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
 func (cmr *CategoryModulesRepo) DeleteModuleFromCategory(categoryId, moduleId int) error {
 	result, err := cmr.psql.Exec("DELETE FROM category_modules "+
 		"WHERE category_id = $1 AND module_id = $2", categoryId, moduleId)
@@ -15,7 +15,7 @@ func NewCategoryModulesResultsRepo(psql repo.PSQL) *CategoryModulesResultsRepo {
 }
 
 func (cmr *CategoryModulesResultsRepo) GetCategoriesResByOwner(ownerId int) ([]entity.CategoryModulesResult, error) {
-	rows, err := cmr.psql.Query("SELECT category_res.category_result_id, category_res.category_id, category_res.module_id, category_res.time, results.* "+
+	rows, err := cmr.psql.Query("SELECT category_res.category_result_id, category_res.category_id, category_res.module_id, category_res.time, results.id, results.type, results.score, results.duration, results.questions_count "+
 		"FROM category_res INNER JOIN results ON category_res.result_id = results.id "+
 		"WHERE category_res.\"owner\" = $1 "+
 		"ORDER BY category_res.category_result_id", ownerId)
@@ -36,7 +36,10 @@ func (cmr *CategoryModulesResultsRepo) GetCategoriesResByOwner(ownerId int) ([]e
 			&moduleRes.ModuleId,
 			&tempTime,
 			&moduleRes.Result.Id,
-			&moduleRes.Result.Type)
+			&moduleRes.Result.Type,
+			&moduleRes.Result.Score,
+			&moduleRes.Result.Duration,
+			&moduleRes.Result.QuestionsCount)
 		if err != nil {
 			return []entity.CategoryModulesResult{}, repo.NewDBError("category_res", "select", err)
 		}
@@ -78,7 +81,7 @@ func (cmr *CategoryModulesResultsRepo) GetCategoriesResByOwner(ownerId int) ([]e
 }
 
 func (cmr *CategoryModulesResultsRepo) GetCategoryResById(categoryResultsId int) (entity.CategoryModulesResult, error) {
-	rows, err := cmr.psql.Query("SELECT category_res.category_id, category_res.\"owner\", category_res.module_id, category_res.time, results.* "+
+	rows, err := cmr.psql.Query("SELECT category_res.category_id, category_res.\"owner\", category_res.module_id, category_res.time, results.id, results.type, results.score, results.duration, results.questions_count "+
 		"FROM category_res INNER JOIN results ON category_res.result_id = results.id "+
 		"WHERE category_result_id = $1", categoryResultsId)
 	if err != nil {
@@ -96,7 +99,10 @@ func (cmr *CategoryModulesResultsRepo) GetCategoryResById(categoryResultsId int)
 			&moduleRes.ModuleId,
 			&categoryRes.Time,
 			&moduleRes.Result.Id,
-			&moduleRes.Result.Type)
+			&moduleRes.Result.Type,
+			&moduleRes.Result.Score,
+			&moduleRes.Result.Duration,
+			&moduleRes.Result.QuestionsCount)
 		if err != nil {
 			return entity.CategoryModulesResult{}, repo.NewDBError("category_res", "select", err)
 		}
@@ -113,7 +119,7 @@ func (cmr *CategoryModulesResultsRepo) GetCategoryResById(categoryResultsId int)
 }
 
 func (cmr *CategoryModulesResultsRepo) GetResultsByCategoryOwner(categoryId, userId int) ([]entity.CategoryModulesResult, error) {
-	rows, err := cmr.psql.Query("SELECT category_res.category_result_id, category_res.module_id, category_res.time, results.* "+
+	rows, err := cmr.psql.Query("SELECT category_res.category_result_id, category_res.module_id, category_res.time, results.id, results.type, results.score, results.duration, results.questions_count "+
 		"FROM category_res INNER JOIN results ON category_res.result_id = results.id "+
 		"WHERE category_id = $1 AND category_res.owner = $2 "+
 		"ORDER BY category_res.category_result_id", categoryId, userId)
@@ -132,7 +138,10 @@ func (cmr *CategoryModulesResultsRepo) GetResultsByCategoryOwner(categoryId, use
 			&moduleRes.ModuleId,
 			&tempTime,
 			&moduleRes.Result.Id,
-			&moduleRes.Result.Type)
+			&moduleRes.Result.Type,
+			&moduleRes.Result.Score,
+			&moduleRes.Result.Duration,
+			&moduleRes.Result.QuestionsCount)
 		if err != nil {
 			return []entity.CategoryModulesResult{}, repo.NewDBError("category_res", "select", err)
 		}
@@ -1,8 +1,11 @@
 package persistent
 
 import (
+	"database/sql"
+	"errors"
 	"interactive_learning/internal/entity"
 	"interactive_learning/internal/repo"
+	"time"
 )
 
 type CategoryRepo struct {
@@ -13,9 +16,38 @@ func NewCategoryRepo(psql repo.PSQL) *CategoryRepo {
 	return &CategoryRepo{psql: psql}
 }
 
+// categoryTypeColumn - действующая видимость категории: выбор владельца,
+// если в категории нет приватных модулей, иначе приватная
+const categoryTypeColumn = "CASE WHEN categories.visibility = 1 OR EXISTS (SELECT 1 FROM category_modules " +
+	"INNER JOIN modules ON modules.id = category_modules.module_id " +
+	"WHERE category_modules.category_id = categories.id AND modules.type = 1 AND modules.deleted_at IS NULL) " +
+	"THEN 1 ELSE categories.visibility END"
+
+const categoriesColumns = "categories.id, categories.name, categories.owner_id, " + categoryTypeColumn + ", categories.visibility, " +
+	"categories.forked_from, categories.forked_from_owner, " +
+	"categories.description, categories.difficulty, categories.created_at, categories.updated_at, " +
+	"(SELECT COUNT(*) FROM category_modules INNER JOIN modules ON modules.id = category_modules.module_id " +
+	"WHERE category_modules.category_id = categories.id AND modules.deleted_at IS NULL), " +
+	"(SELECT COUNT(*) FROM category_modules INNER JOIN modules ON modules.id = category_modules.module_id " +
+	"INNER JOIN cards ON cards.module_id = modules.id " +
+	"WHERE category_modules.category_id = categories.id AND modules.deleted_at IS NULL AND cards.deleted_at IS NULL), " +
+	"categories.hidden_at IS NOT NULL"
+
+// scanCategory читает колонки categoriesColumns, extra - колонки после них
+func scanCategory(row rowScanner, c *entity.Category, extra ...any) error {
+	fork := forkOrigin{}
+	dest := append([]any{&c.Id, &c.Name, &c.OwnerId, &c.Type, &c.Visibility, &fork.id, &fork.ownerId,
+		&c.Description, &c.Difficulty, &c.CreatedAt, &c.UpdatedAt, &c.ModulesCount, &c.CardsCount, &c.Hidden}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	c.ForkedFrom = fork.toEntity()
+	return nil
+}
+
 func (cr *CategoryRepo) GetCategoriesWithSimilarName(name string, limit, offset int) ([]entity.Category, error) {
 	name = "%" + name + "%"
-	rows, err := cr.psql.Query("SELECT categories.id, categories.name, categories.owner_id, categories.type FROM categories WHERE name LIKE $1 LIMIT $2 OFFSET $3", name, limit, offset)
+	rows, err := cr.psql.Query("SELECT "+categoriesColumns+" FROM categories WHERE name LIKE $1 AND deleted_at IS NULL AND hidden_at IS NULL AND visibility <> 2 LIMIT $2 OFFSET $3", name, limit, offset)
 	if err != nil {
 		return []entity.Category{}, repo.NewDBError("categories", "select", err)
 	}
@@ -23,7 +55,7 @@ func (cr *CategoryRepo) GetCategoriesWithSimilarName(name string, limit, offset
 	categories := []entity.Category{}
 	for rows.Next() {
 		c := entity.Category{}
-		if err = rows.Scan(&c.Id, &c.Name, &c.OwnerId, &c.Type); err != nil {
+		if err = scanCategory(rows, &c); err != nil {
 			return []entity.Category{}, repo.NewDBError("categories", "select", err)
 		}
 		categories = append(categories, c)
@@ -33,7 +65,7 @@ func (cr *CategoryRepo) GetCategoriesWithSimilarName(name string, limit, offset
 }
 
 func (cr *CategoryRepo) GetCategoriesToUser(userId int) ([]entity.Category, error) {
-	rows, err := cr.psql.Query("SELECT * FROM categories WHERE owner_id = $1", userId)
+	rows, err := cr.psql.Query("SELECT "+categoriesColumns+" FROM categories WHERE owner_id = $1 AND deleted_at IS NULL", userId)
 	if err != nil {
 		return []entity.Category{}, repo.NewDBError("categories", "select", err)
 	}
@@ -41,7 +73,7 @@ func (cr *CategoryRepo) GetCategoriesToUser(userId int) ([]entity.Category, erro
 	categories := []entity.Category{}
 	for rows.Next() {
 		c := entity.Category{}
-		err = rows.Scan(&c.Id, &c.Name, &c.OwnerId, &c.Type)
+		err = scanCategory(rows, &c)
 		if err != nil {
 			return []entity.Category{}, repo.NewDBError("categories", "select", err)
 		}
@@ -52,9 +84,9 @@ func (cr *CategoryRepo) GetCategoriesToUser(userId int) ([]entity.Category, erro
 }
 
 func (cr *CategoryRepo) GetCategoryById(id int) (entity.Category, error) {
-	row := cr.psql.QueryRow("SELECT * FROM categories WHERE id = $1", id)
+	row := cr.psql.QueryRow("SELECT "+categoriesColumns+" FROM categories WHERE id = $1 AND deleted_at IS NULL", id)
 	category := entity.Category{}
-	err := row.Scan(&category.Id, &category.Name, &category.OwnerId, &category.Type)
+	err := scanCategory(row, &category)
 	if err != nil {
 		return entity.Category{}, repo.NoSuchRecordToSelect
 	}
@@ -73,31 +105,44 @@ func (cr *CategoryRepo) GetLastInsertedCategoryId() (int, error) {
 }
 
 func (cr *CategoryRepo) GetCategoryOwnerId(categoryId int) (int, error) {
-	row := cr.psql.QueryRow("SELECT owner_id FROM categories WHERE id = $1", categoryId)
+	row := cr.psql.QueryRow("SELECT owner_id FROM categories WHERE id = $1 AND deleted_at IS NULL", categoryId)
 
 	var ownerId int
 	err := row.Scan(&ownerId)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, repo.NoSuchRecordToSelect
+		}
 		return -1, repo.NewDBError("categories", "select", err)
 	}
 	return ownerId, nil
 }
 
-func (cr *CategoryRepo) GetPopularCategories(limit, offset int) ([]entity.PopularCategory, error) {
-	rows, err := cr.psql.Query("SELECT categories.*, COUNT(DISTINCT category_res.owner) as count "+
-		"FROM categories INNER JOIN category_res ON categories.id = category_res.category_id "+
-		"WHERE categories.type = 0 AND time >= NOW() - INTERVAL '7 days' "+
-		"GROUP BY categories.id, category_res.owner "+
-		"ORDER BY count "+
+// GetPopularCategories считает учеников за последнюю неделю и среднюю оценку категории
+func (cr *CategoryRepo) GetPopularCategories(limit, offset int, sortBy string) ([]entity.PopularCategory, error) {
+	order, ok := popularOrder[sortBy]
+	if !ok {
+		order = popularOrder[entity.PopularByLearners]
+	}
+	rows, err := cr.psql.Query("SELECT * FROM (SELECT "+categoriesColumns+", COUNT(DISTINCT category_res.owner) AS learners, "+
+		"ratings.average AS rating, COALESCE(ratings.count, 0) AS ratings_count "+
+		"FROM categories LEFT JOIN category_res ON categories.id = category_res.category_id AND category_res.time >= NOW() - INTERVAL '7 days' "+
+		"LEFT JOIN (SELECT category_id, ROUND(AVG(rating), 2)::float8 AS average, COUNT(*) AS count "+
+		"FROM category_ratings GROUP BY category_id) AS ratings ON ratings.category_id = categories.id "+
+		"WHERE "+categoryTypeColumn+" = 0 AND categories.deleted_at IS NULL AND categories.hidden_at IS NULL "+
+		"GROUP BY categories.id, ratings.average, ratings.count) AS popular "+
+		"WHERE "+order.having+" "+
+		"ORDER BY "+order.orderBy+" "+
 		"LIMIT $1 OFFSET $2;", limit, offset)
 	if err != nil {
 		return []entity.PopularCategory{}, repo.NewDBError("categories", "select", err)
 	}
+	defer rows.Close()
 
 	categories := []entity.PopularCategory{}
 	for rows.Next() {
 		c := entity.PopularCategory{}
-		err = rows.Scan(&c.Cat.Id, &c.Cat.Name, &c.Cat.OwnerId, &c.Cat.Type, &c.Count)
+		err = scanCategory(rows, &c.Cat, &c.Count, &c.Rating, &c.RatingsCount)
 		if err != nil {
 			return []entity.PopularCategory{}, repo.NewDBError("categories", "select", err)
 		}
@@ -107,9 +152,49 @@ func (cr *CategoryRepo) GetPopularCategories(limit, offset int) ([]entity.Popula
 	return categories, nil
 }
 
+func (cr *CategoryRepo) GetTrashedCategories(ownerId int) ([]entity.TrashedCategory, error) {
+	rows, err := cr.psql.Query("SELECT "+categoriesColumns+", categories.deleted_at "+
+		"FROM categories WHERE owner_id = $1 AND deleted_at IS NOT NULL "+
+		"ORDER BY deleted_at DESC", ownerId)
+	if err != nil {
+		return []entity.TrashedCategory{}, repo.NewDBError("categories", "select", err)
+	}
+	defer rows.Close()
+
+	categories := []entity.TrashedCategory{}
+	for rows.Next() {
+		c := entity.TrashedCategory{}
+		err = scanCategory(rows, &c.Cat, &c.DeletedAt)
+		if err != nil {
+			return []entity.TrashedCategory{}, repo.NewDBError("categories", "select", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+func (cr *CategoryRepo) GetTrashedCategoryById(categoryId int) (entity.TrashedCategory, error) {
+	row := cr.psql.QueryRow("SELECT "+categoriesColumns+", categories.deleted_at "+
+		"FROM categories WHERE id = $1 AND deleted_at IS NOT NULL", categoryId)
+	c := entity.TrashedCategory{}
+	err := scanCategory(row, &c.Cat, &c.DeletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.TrashedCategory{}, repo.NoSuchRecordToSelect
+		}
+		return entity.TrashedCategory{}, repo.NewDBError("categories", "select", err)
+	}
+	return c, nil
+}
+
+func (cr *CategoryRepo) GetCategoriesDeletedBefore(deletedBefore time.Time) ([]int, error) {
+	return selectIds(cr.psql, "categories", "SELECT id FROM categories WHERE deleted_at < $1", deletedBefore)
+}
+
 func (cr *CategoryRepo) InsertCategory(category entity.CategoryToCreate) error {
-	result, err := cr.psql.Exec("INSERT INTO categories(name, owner_id, type) "+
-		"VALUES($1, $2, $3)", category.Name, category.OwnerId, category.Type)
+	forkedFrom, forkedFromOwner := forkOriginArgs(category.ForkedFrom)
+	result, err := cr.psql.Exec("INSERT INTO categories(name, owner_id, visibility, forked_from, forked_from_owner, description, difficulty) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7)", category.Name, category.OwnerId, category.Type, forkedFrom, forkedFromOwner, category.Description, category.Difficulty)
 	if err != nil {
 		return repo.NewDBError("categories", "insert", err)
 	} else if count, _ := result.RowsAffected(); count == 0 {
@@ -120,7 +205,7 @@ func (cr *CategoryRepo) InsertCategory(category entity.CategoryToCreate) error {
 
 func (cr *CategoryRepo) RenameCategory(categoryId int, newName string) error {
 	result, err := cr.psql.Exec("UPDATE categories "+
-		"SET name = $1 "+
+		"SET name = $1, updated_at = NOW() "+
 		"WHERE id = $2", newName, categoryId)
 	if err != nil {
 		return repo.NewDBError("categories", "update", err)
@@ -130,10 +215,11 @@ func (cr *CategoryRepo) RenameCategory(categoryId int, newName string) error {
 	return nil
 }
 
-func (cr *CategoryRepo) UpdateCategoryType(categoryId, categoryType int) error {
+// UpdateCategoryVisibility меняет выбор владельца, приватные модули все равно закрывают категорию
+func (cr *CategoryRepo) UpdateCategoryVisibility(categoryId, visibility int) error {
 	result, err := cr.psql.Exec("UPDATE categories "+
-		"SET type = $1 "+
-		"WHERE id = $2", categoryType, categoryId)
+		"SET visibility = $1 "+
+		"WHERE id = $2", visibility, categoryId)
 	if err != nil {
 		return repo.NewDBError("categories", "update", err)
 	} else if count, _ := result.RowsAffected(); count == 0 {
@@ -142,10 +228,89 @@ func (cr *CategoryRepo) UpdateCategoryType(categoryId, categoryType int) error {
 	return nil
 }
 
-func (cr *CategoryRepo) TurnDownCategoryType(categoryId int) error {
+func (cr *CategoryRepo) GetCategoryShareSlug(categoryId int) (string, error) {
+	var slug sql.NullString
+	err := cr.psql.QueryRow("SELECT share_slug FROM categories WHERE id = $1 AND deleted_at IS NULL", categoryId).Scan(&slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", repo.NoSuchRecordToSelect
+	} else if err != nil {
+		return "", repo.NewDBError("categories", "select", err)
+	}
+	return slug.String, nil
+}
+
+func (cr *CategoryRepo) GetCategoryByShareSlug(slug string) (entity.Category, error) {
+	row := cr.psql.QueryRow("SELECT "+categoriesColumns+" FROM categories WHERE share_slug = $1 AND deleted_at IS NULL", slug)
+	category := entity.Category{}
+	err := scanCategory(row, &category)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Category{}, repo.NoSuchRecordToSelect
+	} else if err != nil {
+		return entity.Category{}, repo.NewDBError("categories", "select", err)
+	}
+	return category, nil
+}
+
+// SetCategoryShareSlug задает новую ссылку, старая перестает открываться
+func (cr *CategoryRepo) SetCategoryShareSlug(categoryId int, slug string) error {
+	result, err := cr.psql.Exec("UPDATE categories SET share_slug = $1 WHERE id = $2", slug, categoryId)
+	if err != nil {
+		return repo.NewDBError("categories", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (cr *CategoryRepo) UpdateCategoryInfo(categoryId int, info entity.Info) error {
 	result, err := cr.psql.Exec("UPDATE categories "+
-		"SET type = type - 1 "+
-		"WHERE id = $1", categoryId)
+		"SET description = $1, difficulty = $2, updated_at = NOW() "+
+		"WHERE id = $3", info.Description, info.Difficulty, categoryId)
+	if err != nil {
+		return repo.NewDBError("categories", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+// TouchCategory отмечает изменение состава модулей категории
+func (cr *CategoryRepo) TouchCategory(categoryId int) error {
+	_, err := cr.psql.Exec("UPDATE categories SET updated_at = NOW() WHERE id = $1", categoryId)
+	if err != nil {
+		return repo.NewDBError("categories", "update", err)
+	}
+	return nil
+}
+
+// SetCategoryHidden скрывает категорию по решению модератора или возвращает ее
+func (cr *CategoryRepo) SetCategoryHidden(categoryId int, hidden bool) error {
+	result, err := cr.psql.Exec("UPDATE categories SET hidden_at = CASE WHEN $2 THEN NOW() END "+
+		"WHERE id = $1 AND deleted_at IS NULL", categoryId, hidden)
+	if err != nil {
+		return repo.NewDBError("categories", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (cr *CategoryRepo) TrashCategory(categoryId int) error {
+	result, err := cr.psql.Exec("UPDATE categories "+
+		"SET deleted_at = NOW() "+
+		"WHERE id = $1 AND deleted_at IS NULL", categoryId)
+	if err != nil {
+		return repo.NewDBError("categories", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (cr *CategoryRepo) RestoreCategory(categoryId int) error {
+	result, err := cr.psql.Exec("UPDATE categories "+
+		"SET deleted_at = NULL "+
+		"WHERE id = $1 AND deleted_at IS NOT NULL", categoryId)
 	if err != nil {
 		return repo.NewDBError("categories", "update", err)
 	} else if count, _ := result.RowsAffected(); count == 0 {
@@ -164,3 +329,43 @@ func (cr *CategoryRepo) DeleteCategory(id int) error {
 	}
 	return nil
 }
+
+// GetVisibilityIssues находит категории, которые выглядят для других не так,
+// как их открыл владелец, приватные категории без приватных модулей
+// и модули с неизвестным типом
+func (cr *CategoryRepo) GetVisibilityIssues() ([]entity.VisibilityIssue, error) {
+	rows, err := cr.psql.Query("SELECT $1, categories.id, modules.id FROM categories "+
+		"INNER JOIN category_modules ON category_modules.category_id = categories.id "+
+		"INNER JOIN modules ON modules.id = category_modules.module_id "+
+		"WHERE categories.visibility <> 1 AND modules.type = 1 "+
+		"AND categories.deleted_at IS NULL AND modules.deleted_at IS NULL "+
+		"UNION ALL "+
+		"SELECT $2, categories.id, modules.id FROM categories "+
+		"INNER JOIN category_modules ON category_modules.category_id = categories.id "+
+		"INNER JOIN modules ON modules.id = category_modules.module_id "+
+		"WHERE modules.type = 1 AND modules.owner_id <> categories.owner_id "+
+		"AND categories.deleted_at IS NULL AND modules.deleted_at IS NULL "+
+		"UNION ALL "+
+		"SELECT $3, 0, modules.id FROM modules WHERE modules.type NOT IN (0, 1, 2) "+
+		"UNION ALL "+
+		"SELECT $4, categories.id, 0 FROM categories WHERE categories.visibility = 1 AND categories.deleted_at IS NULL "+
+		"AND NOT EXISTS (SELECT 1 FROM category_modules INNER JOIN modules ON modules.id = category_modules.module_id "+
+		"WHERE category_modules.category_id = categories.id AND modules.type = 1 AND modules.deleted_at IS NULL) "+
+		"ORDER BY 2, 3",
+		entity.HiddenByPrivateModuleIssue, entity.ForeignPrivateModuleIssue, entity.UnknownModuleTypeIssue,
+		entity.PrivateWithoutPrivateModulesIssue)
+	if err != nil {
+		return []entity.VisibilityIssue{}, repo.NewDBError("categories", "select", err)
+	}
+	defer rows.Close()
+
+	issues := []entity.VisibilityIssue{}
+	for rows.Next() {
+		issue := entity.VisibilityIssue{}
+		if err = rows.Scan(&issue.Kind, &issue.CategoryId, &issue.ModuleId); err != nil {
+			return []entity.VisibilityIssue{}, repo.NewDBError("categories", "select", err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
@@ -0,0 +1,125 @@
+package persistent
+
+import (
+	"database/sql"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type CommentsRepo struct {
+	psql repo.PSQL
+}
+
+func NewCommentsRepo(psql repo.PSQL) *CommentsRepo {
+	return &CommentsRepo{psql: psql}
+}
+
+const commentsColumns = "comments.id, comments.module_id, comments.category_id, comments.user_id, users.login, " +
+	"comments.text, comments.created_at, comments.updated_at"
+
+func scanComment(row rowScanner, c *entity.Comment) error {
+	return row.Scan(&c.Id, &c.ModuleId, &c.CategoryId, &c.UserId, &c.Login, &c.Text, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func (cr *CommentsRepo) GetCommentById(id int) (entity.Comment, error) {
+	row := cr.psql.QueryRow("SELECT "+commentsColumns+" FROM comments "+
+		"INNER JOIN users ON users.id = comments.user_id WHERE comments.id = $1", id)
+
+	c := entity.Comment{}
+	if err := scanComment(row, &c); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Comment{}, repo.NoSuchRecordToSelect
+		}
+		return entity.Comment{}, repo.NewDBError("comments", "select", err)
+	}
+	return c, nil
+}
+
+func (cr *CommentsRepo) GetModuleComments(moduleId, limit, offset int) ([]entity.Comment, error) {
+	return cr.getComments("module_id", moduleId, limit, offset)
+}
+
+func (cr *CommentsRepo) GetCategoryComments(categoryId, limit, offset int) ([]entity.Comment, error) {
+	return cr.getComments("category_id", categoryId, limit, offset)
+}
+
+// getComments возвращает не скрытые модератором комментарии от новых к старым
+func (cr *CommentsRepo) getComments(column string, id, limit, offset int) ([]entity.Comment, error) {
+	rows, err := cr.psql.Query("SELECT "+commentsColumns+" FROM comments "+
+		"INNER JOIN users ON users.id = comments.user_id WHERE comments."+column+" = $1 AND comments.hidden_at IS NULL "+
+		"ORDER BY comments.created_at DESC, comments.id DESC LIMIT $2 OFFSET $3", id, limit, offset)
+	if err != nil {
+		return []entity.Comment{}, repo.NewDBError("comments", "select", err)
+	}
+	defer rows.Close()
+
+	comments := []entity.Comment{}
+	for rows.Next() {
+		c := entity.Comment{}
+		if err = scanComment(rows, &c); err != nil {
+			return []entity.Comment{}, repo.NewDBError("comments", "select", err)
+		}
+		comments = append(comments, c)
+	}
+	return comments, nil
+}
+
+func (cr *CommentsRepo) GetLastInsertedCommentId() (int, error) {
+	row := cr.psql.QueryRow("SELECT MAX(id) FROM comments")
+
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return 0, repo.NewDBError("comments", "select", err)
+	}
+	return id, nil
+}
+
+func (cr *CommentsRepo) InsertModuleComment(moduleId, userId int, text string) error {
+	return cr.insertComment("module_id", moduleId, userId, text)
+}
+
+func (cr *CommentsRepo) InsertCategoryComment(categoryId, userId int, text string) error {
+	return cr.insertComment("category_id", categoryId, userId, text)
+}
+
+func (cr *CommentsRepo) insertComment(column string, id, userId int, text string) error {
+	result, err := cr.psql.Exec("INSERT INTO comments("+column+", user_id, text) VALUES($1, $2, $3)", id, userId, text)
+	if err != nil {
+		return repo.NewDBError("comments", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (cr *CommentsRepo) UpdateCommentText(id int, text string) error {
+	result, err := cr.psql.Exec("UPDATE comments SET text = $1, updated_at = NOW() WHERE id = $2", text, id)
+	if err != nil {
+		return repo.NewDBError("comments", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+// SetCommentHidden скрывает комментарий по решению модератора или возвращает его
+func (cr *CommentsRepo) SetCommentHidden(id int, hidden bool) error {
+	result, err := cr.psql.Exec("UPDATE comments SET hidden_at = CASE WHEN $2 THEN NOW() END WHERE id = $1", id, hidden)
+	if err != nil {
+		return repo.NewDBError("comments", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (cr *CommentsRepo) DeleteComment(id int) error {
+	result, err := cr.psql.Exec("DELETE FROM comments WHERE id = $1", id)
+	if err != nil {
+		return repo.NewDBError("comments", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
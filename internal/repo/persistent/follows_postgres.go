@@ -0,0 +1,132 @@
+package persistent
+
+import (
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"time"
+)
+
+type FollowsRepo struct {
+	psql repo.PSQL
+}
+
+func NewFollowsRepo(psql repo.PSQL) *FollowsRepo {
+	return &FollowsRepo{psql: psql}
+}
+
+func (fr *FollowsRepo) GetFollowers(userId int) ([]entity.User, error) {
+	return fr.getUsers("SELECT users.id, users.login, users.name FROM follows "+
+		"INNER JOIN users ON users.id = follows.follower_id "+
+		"WHERE follows.followee_id = $1 ORDER BY follows.created_at DESC", userId)
+}
+
+func (fr *FollowsRepo) GetFollowing(userId int) ([]entity.User, error) {
+	return fr.getUsers("SELECT users.id, users.login, users.name FROM follows "+
+		"INNER JOIN users ON users.id = follows.followee_id "+
+		"WHERE follows.follower_id = $1 ORDER BY follows.created_at DESC", userId)
+}
+
+func (fr *FollowsRepo) getUsers(query string, args ...any) ([]entity.User, error) {
+	rows, err := fr.psql.Query(query, args...)
+	if err != nil {
+		return []entity.User{}, repo.NewDBError("follows", "select", err)
+	}
+	defer rows.Close()
+
+	users := []entity.User{}
+	for rows.Next() {
+		u := entity.User{}
+		if err = rows.Scan(&u.Id, &u.Login, &u.Name); err != nil {
+			return []entity.User{}, repo.NewDBError("follows", "select", err)
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+func (fr *FollowsRepo) IsFollowing(followerId, followeeId int) (bool, error) {
+	row := fr.psql.QueryRow("SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)", followerId, followeeId)
+
+	var isFollowing bool
+	if err := row.Scan(&isFollowing); err != nil {
+		return false, repo.NewDBError("follows", "select", err)
+	}
+	return isFollowing, nil
+}
+
+// followeesQuery - на кого подписан пользователь $1
+const followeesQuery = "SELECT follows.followee_id FROM follows WHERE follows.follower_id = $1"
+
+// feedQuery собирает события подписок: новые открытые и не скрытые модули и категории и,
+// если автор разрешил, первое прохождение открытого модуля
+const feedQuery = "SELECT $6::varchar AS kind, modules.id, modules.owner_id AS user_id, modules.id AS module_id, NULL::integer AS category_id, " +
+	"modules.name, NULL::varchar AS result_type, NULL::integer AS score, NULL::integer AS questions_count, modules.created_at AS at " +
+	"FROM modules WHERE modules.type = 0 AND modules.deleted_at IS NULL AND modules.hidden_at IS NULL AND modules.owner_id IN (" + followeesQuery + ") " +
+	"UNION ALL " +
+	"SELECT $7::varchar, categories.id, categories.owner_id, NULL, categories.id, " +
+	"categories.name, NULL, NULL, NULL, categories.created_at " +
+	"FROM categories WHERE " + categoryTypeColumn + " = 0 AND categories.deleted_at IS NULL AND categories.hidden_at IS NULL AND categories.owner_id IN (" + followeesQuery + ") " +
+	"UNION ALL " +
+	"SELECT $8::varchar, first_res.result_id, first_res.owner, first_res.module_id, NULL, " +
+	"modules.name, results.type, results.score, results.questions_count, first_res.time AT TIME ZONE 'UTC' " +
+	"FROM (SELECT DISTINCT ON (modules_res.owner, modules_res.module_id) modules_res.owner, modules_res.module_id, modules_res.result_id, modules_res.time " +
+	"FROM modules_res INNER JOIN users ON users.id = modules_res.owner " +
+	"WHERE users.share_activity AND modules_res.owner IN (" + followeesQuery + ") " +
+	"ORDER BY modules_res.owner, modules_res.module_id, modules_res.time) AS first_res " +
+	"INNER JOIN modules ON modules.id = first_res.module_id " +
+	"INNER JOIN results ON results.id = first_res.result_id " +
+	"WHERE modules.type = 0 AND modules.deleted_at IS NULL AND modules.hidden_at IS NULL"
+
+// GetFeed возвращает события подписок от новых к старым, начиная после cursor
+func (fr *FollowsRepo) GetFeed(userId int, cursor *entity.FeedCursor, limit int) ([]entity.FeedItem, error) {
+	var at *time.Time
+	var kind *string
+	var id *int
+	if cursor != nil {
+		at, kind, id = &cursor.At, &cursor.Kind, &cursor.Id
+	}
+
+	rows, err := fr.psql.Query("SELECT feed.kind, feed.id, feed.user_id, users.login, feed.module_id, feed.category_id, "+
+		"feed.name, feed.result_type, feed.score, feed.questions_count, feed.at "+
+		"FROM ("+feedQuery+") AS feed INNER JOIN users ON users.id = feed.user_id "+
+		"WHERE $2::timestamptz IS NULL OR (feed.at, feed.kind, feed.id) < ($2::timestamptz, $3::varchar, $4::integer) "+
+		"ORDER BY feed.at DESC, feed.kind DESC, feed.id DESC LIMIT $5",
+		userId, at, kind, id, limit,
+		entity.ModuleCreatedEvent, entity.CategoryCreatedEvent, entity.StudyMilestoneEvent)
+	if err != nil {
+		return []entity.FeedItem{}, repo.NewDBError("follows", "select", err)
+	}
+	defer rows.Close()
+
+	items := []entity.FeedItem{}
+	for rows.Next() {
+		item := entity.FeedItem{}
+		err = rows.Scan(&item.Kind, &item.Id, &item.UserId, &item.Login, &item.ModuleId, &item.CategoryId,
+			&item.Name, &item.ResultType, &item.Score, &item.QuestionsCount, &item.At)
+		if err != nil {
+			return []entity.FeedItem{}, repo.NewDBError("follows", "select", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (fr *FollowsRepo) InsertFollow(followerId, followeeId int) error {
+	result, err := fr.psql.Exec("INSERT INTO follows(follower_id, followee_id) VALUES($1, $2)", followerId, followeeId)
+	if err != nil {
+		return repo.NewDBError("follows", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (fr *FollowsRepo) DeleteFollow(followerId, followeeId int) error {
+	result, err := fr.psql.Exec("DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2", followerId, followeeId)
+	if err != nil {
+		return repo.NewDBError("follows", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package persistent
+
+import (
+	"database/sql"
+	"interactive_learning/internal/entity"
+)
+
+// forkOrigin сканирует колонки forked_from, forked_from_owner
+type forkOrigin struct {
+	id      sql.NullInt64
+	ownerId sql.NullInt64
+}
+
+func (f *forkOrigin) toEntity() *entity.ForkOrigin {
+	if !f.ownerId.Valid {
+		return nil
+	}
+	origin := &entity.ForkOrigin{OwnerId: int(f.ownerId.Int64)}
+	if f.id.Valid {
+		id := int(f.id.Int64)
+		origin.Id = &id
+	}
+	return origin
+}
+
+func forkOriginArgs(origin *entity.ForkOrigin) (any, any) {
+	if origin == nil {
+		return nil, nil
+	}
+	var id any
+	if origin.Id != nil {
+		id = *origin.Id
+	}
+	return id, origin.OwnerId
+}
@@ -0,0 +1,179 @@
+package persistent
+
+import (
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type GrantsRepo struct {
+	psql repo.PSQL
+}
+
+func NewGrantsRepo(psql repo.PSQL) *GrantsRepo {
+	return &GrantsRepo{psql: psql}
+}
+
+func (gr *GrantsRepo) GetModuleGrants(moduleId int) ([]entity.Grant, error) {
+	return gr.getGrants("module_grants", "SELECT module_grants.user_id, users.login, module_grants.role, module_grants.created_at "+
+		"FROM module_grants INNER JOIN users ON users.id = module_grants.user_id "+
+		"WHERE module_grants.module_id = $1 ORDER BY module_grants.created_at", moduleId)
+}
+
+func (gr *GrantsRepo) GetCategoryGrants(categoryId int) ([]entity.Grant, error) {
+	return gr.getGrants("category_grants", "SELECT category_grants.user_id, users.login, category_grants.role, category_grants.created_at "+
+		"FROM category_grants INNER JOIN users ON users.id = category_grants.user_id "+
+		"WHERE category_grants.category_id = $1 ORDER BY category_grants.created_at", categoryId)
+}
+
+func (gr *GrantsRepo) getGrants(table, query string, args ...any) ([]entity.Grant, error) {
+	rows, err := gr.psql.Query(query, args...)
+	if err != nil {
+		return []entity.Grant{}, repo.NewDBError(table, "select", err)
+	}
+	defer rows.Close()
+
+	grants := []entity.Grant{}
+	for rows.Next() {
+		g := entity.Grant{}
+		if err = rows.Scan(&g.UserId, &g.Login, &g.Role, &g.CreatedAt); err != nil {
+			return []entity.Grant{}, repo.NewDBError(table, "select", err)
+		}
+		grants = append(grants, g)
+	}
+	return grants, nil
+}
+
+// GetModuleRoles возвращает роли пользователей в модуле. Доступ к категории
+// дает право просмотра ее модулей, если их автор - владелец категории.
+// Участники учебной группы просматривают открытые группе модули и категории.
+func (gr *GrantsRepo) GetModuleRoles(moduleId int) (map[int]string, error) {
+	return gr.getRoles("module_grants", "SELECT user_id, role FROM module_grants WHERE module_id = $1 "+
+		"UNION ALL "+
+		"SELECT category_grants.user_id, $2 FROM category_grants "+
+		"INNER JOIN categories ON categories.id = category_grants.category_id "+
+		"INNER JOIN category_modules ON category_modules.category_id = categories.id "+
+		"INNER JOIN modules ON modules.id = category_modules.module_id "+
+		"WHERE modules.id = $1 AND categories.owner_id = modules.owner_id AND categories.deleted_at IS NULL "+
+		"UNION ALL "+
+		"SELECT group_members.user_id, $2 FROM group_modules "+
+		"INNER JOIN group_members ON group_members.group_id = group_modules.group_id "+
+		"WHERE group_modules.module_id = $1 "+
+		"UNION ALL "+
+		"SELECT group_members.user_id, $2 FROM group_categories "+
+		"INNER JOIN group_members ON group_members.group_id = group_categories.group_id "+
+		"INNER JOIN categories ON categories.id = group_categories.category_id "+
+		"INNER JOIN category_modules ON category_modules.category_id = categories.id "+
+		"INNER JOIN modules ON modules.id = category_modules.module_id "+
+		"WHERE modules.id = $1 AND categories.owner_id = modules.owner_id AND categories.deleted_at IS NULL",
+		moduleId, entity.ViewerRole)
+}
+
+func (gr *GrantsRepo) GetCategoryRoles(categoryId int) (map[int]string, error) {
+	return gr.getRoles("category_grants", "SELECT user_id, role FROM category_grants WHERE category_id = $1 "+
+		"UNION ALL "+
+		"SELECT group_members.user_id, $2 FROM group_categories "+
+		"INNER JOIN group_members ON group_members.group_id = group_categories.group_id "+
+		"WHERE group_categories.category_id = $1",
+		categoryId, entity.ViewerRole)
+}
+
+// getRoles собирает роли по пользователям, роль редактора сильнее роли зрителя
+func (gr *GrantsRepo) getRoles(table, query string, args ...any) (map[int]string, error) {
+	rows, err := gr.psql.Query(query, args...)
+	if err != nil {
+		return map[int]string{}, repo.NewDBError(table, "select", err)
+	}
+	defer rows.Close()
+
+	roles := map[int]string{}
+	for rows.Next() {
+		var userId int
+		var role string
+		if err = rows.Scan(&userId, &role); err != nil {
+			return map[int]string{}, repo.NewDBError(table, "select", err)
+		}
+		if roles[userId] != entity.EditorRole {
+			roles[userId] = role
+		}
+	}
+	return roles, nil
+}
+
+func (gr *GrantsRepo) GetModulesSharedWithUser(userId int) ([]entity.Module, error) {
+	rows, err := gr.psql.Query("SELECT "+modulesColumns+" FROM module_grants INNER JOIN modules ON modules.id = module_grants.module_id "+
+		"WHERE module_grants.user_id = $1 AND modules.deleted_at IS NULL "+
+		"ORDER BY module_grants.created_at DESC", userId)
+	if err != nil {
+		return []entity.Module{}, repo.NewDBError("module_grants", "select", err)
+	}
+	defer rows.Close()
+
+	modules := []entity.Module{}
+	for rows.Next() {
+		m := entity.Module{}
+		if err = scanModule(rows, &m); err != nil {
+			return []entity.Module{}, repo.NewDBError("module_grants", "select", err)
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+func (gr *GrantsRepo) GetCategoriesSharedWithUser(userId int) ([]entity.Category, error) {
+	rows, err := gr.psql.Query("SELECT "+categoriesColumns+" FROM category_grants INNER JOIN categories ON categories.id = category_grants.category_id "+
+		"WHERE category_grants.user_id = $1 AND categories.deleted_at IS NULL "+
+		"ORDER BY category_grants.created_at DESC", userId)
+	if err != nil {
+		return []entity.Category{}, repo.NewDBError("category_grants", "select", err)
+	}
+	defer rows.Close()
+
+	categories := []entity.Category{}
+	for rows.Next() {
+		c := entity.Category{}
+		if err = scanCategory(rows, &c); err != nil {
+			return []entity.Category{}, repo.NewDBError("category_grants", "select", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+// SetModuleGrant выдает пользователю роль в модуле или меняет уже выданную
+func (gr *GrantsRepo) SetModuleGrant(moduleId, userId int, role string) error {
+	_, err := gr.psql.Exec("INSERT INTO module_grants(module_id, user_id, role) VALUES($1, $2, $3) "+
+		"ON CONFLICT (module_id, user_id) DO UPDATE SET role = EXCLUDED.role", moduleId, userId, role)
+	if err != nil {
+		return repo.NewDBError("module_grants", "insert", err)
+	}
+	return nil
+}
+
+func (gr *GrantsRepo) DeleteModuleGrant(moduleId, userId int) error {
+	result, err := gr.psql.Exec("DELETE FROM module_grants WHERE module_id = $1 AND user_id = $2", moduleId, userId)
+	if err != nil {
+		return repo.NewDBError("module_grants", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
+
+func (gr *GrantsRepo) SetCategoryGrant(categoryId, userId int, role string) error {
+	_, err := gr.psql.Exec("INSERT INTO category_grants(category_id, user_id, role) VALUES($1, $2, $3) "+
+		"ON CONFLICT (category_id, user_id) DO UPDATE SET role = EXCLUDED.role", categoryId, userId, role)
+	if err != nil {
+		return repo.NewDBError("category_grants", "insert", err)
+	}
+	return nil
+}
+
+func (gr *GrantsRepo) DeleteCategoryGrant(categoryId, userId int) error {
+	result, err := gr.psql.Exec("DELETE FROM category_grants WHERE category_id = $1 AND user_id = $2", categoryId, userId)
+	if err != nil {
+		return repo.NewDBError("category_grants", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+package persistent
+
+import (
+	"database/sql"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type GroupsRepo struct {
+	psql repo.PSQL
+}
+
+func NewGroupsRepo(psql repo.PSQL) *GroupsRepo {
+	return &GroupsRepo{psql: psql}
+}
+
+const groupsColumns = "groups.id, groups.name, groups.owner_id, groups.join_code, " +
+	"(SELECT COUNT(*) FROM group_members WHERE group_members.group_id = groups.id), groups.created_at"
+
+func scanGroup(row rowScanner, g *entity.Group) error {
+	return row.Scan(&g.Id, &g.Name, &g.OwnerId, &g.JoinCode, &g.MembersCount, &g.CreatedAt)
+}
+
+func (gr *GroupsRepo) GetGroupById(groupId int) (entity.Group, error) {
+	return gr.getGroup("SELECT "+groupsColumns+" FROM groups WHERE groups.id = $1", groupId)
+}
+
+func (gr *GroupsRepo) GetGroupByJoinCode(code string) (entity.Group, error) {
+	return gr.getGroup("SELECT "+groupsColumns+" FROM groups WHERE groups.join_code = $1", code)
+}
+
+func (gr *GroupsRepo) getGroup(query string, args ...any) (entity.Group, error) {
+	g := entity.Group{}
+	if err := scanGroup(gr.psql.QueryRow(query, args...), &g); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Group{}, repo.NoSuchRecordToSelect
+		}
+		return entity.Group{}, repo.NewDBError("groups", "select", err)
+	}
+	return g, nil
+}
+
+// GetGroupsByUser возвращает группы, в которых пользователь преподает или учится
+func (gr *GroupsRepo) GetGroupsByUser(userId int) ([]entity.Group, error) {
+	rows, err := gr.psql.Query("SELECT "+groupsColumns+" FROM groups "+
+		"INNER JOIN group_members ON group_members.group_id = groups.id "+
+		"WHERE group_members.user_id = $1 ORDER BY group_members.joined_at DESC", userId)
+	if err != nil {
+		return []entity.Group{}, repo.NewDBError("groups", "select", err)
+	}
+	defer rows.Close()
+
+	groups := []entity.Group{}
+	for rows.Next() {
+		g := entity.Group{}
+		if err = scanGroup(rows, &g); err != nil {
+			return []entity.Group{}, repo.NewDBError("groups", "select", err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func (gr *GroupsRepo) GetLastInsertedGroupId() (int, error) {
+	row := gr.psql.QueryRow("SELECT MAX(id) FROM groups")
+
+	var last_id int
+	if err := row.Scan(&last_id); err != nil {
+		return -1, repo.NewDBError("groups", "select", err)
+	}
+	return last_id, nil
+}
+
+func (gr *GroupsRepo) GetGroupMembers(groupId int) ([]entity.GroupMember, error) {
+	rows, err := gr.psql.Query("SELECT group_members.user_id, users.login, group_members.role, group_members.joined_at "+
+		"FROM group_members INNER JOIN users ON users.id = group_members.user_id "+
+		"WHERE group_members.group_id = $1 ORDER BY group_members.joined_at", groupId)
+	if err != nil {
+		return []entity.GroupMember{}, repo.NewDBError("group_members", "select", err)
+	}
+	defer rows.Close()
+
+	members := []entity.GroupMember{}
+	for rows.Next() {
+		m := entity.GroupMember{}
+		if err = rows.Scan(&m.UserId, &m.Login, &m.Role, &m.JoinedAt); err != nil {
+			return []entity.GroupMember{}, repo.NewDBError("group_members", "select", err)
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// GetGroupRoles возвращает роли участников группы
+func (gr *GroupsRepo) GetGroupRoles(groupId int) (map[int]string, error) {
+	rows, err := gr.psql.Query("SELECT user_id, role FROM group_members WHERE group_id = $1", groupId)
+	if err != nil {
+		return map[int]string{}, repo.NewDBError("group_members", "select", err)
+	}
+	defer rows.Close()
+
+	roles := map[int]string{}
+	for rows.Next() {
+		var userId int
+		var role string
+		if err = rows.Scan(&userId, &role); err != nil {
+			return map[int]string{}, repo.NewDBError("group_members", "select", err)
+		}
+		roles[userId] = role
+	}
+	return roles, nil
+}
+
+func (gr *GroupsRepo) GetGroupModules(groupId int) ([]entity.Module, error) {
+	rows, err := gr.psql.Query("SELECT "+modulesColumns+" FROM group_modules INNER JOIN modules ON modules.id = group_modules.module_id "+
+		"WHERE group_modules.group_id = $1 AND modules.deleted_at IS NULL "+
+		"ORDER BY group_modules.added_at", groupId)
+	if err != nil {
+		return []entity.Module{}, repo.NewDBError("group_modules", "select", err)
+	}
+	defer rows.Close()
+
+	modules := []entity.Module{}
+	for rows.Next() {
+		m := entity.Module{}
+		if err = scanModule(rows, &m); err != nil {
+			return []entity.Module{}, repo.NewDBError("group_modules", "select", err)
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+func (gr *GroupsRepo) GetGroupCategories(groupId int) ([]entity.Category, error) {
+	rows, err := gr.psql.Query("SELECT "+categoriesColumns+" FROM group_categories INNER JOIN categories ON categories.id = group_categories.category_id "+
+		"WHERE group_categories.group_id = $1 AND categories.deleted_at IS NULL "+
+		"ORDER BY group_categories.added_at", groupId)
+	if err != nil {
+		return []entity.Category{}, repo.NewDBError("group_categories", "select", err)
+	}
+	defer rows.Close()
+
+	categories := []entity.Category{}
+	for rows.Next() {
+		c := entity.Category{}
+		if err = scanCategory(rows, &c); err != nil {
+			return []entity.Category{}, repo.NewDBError("group_categories", "select", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+// groupModulesQuery - модули группы: открытые ей напрямую и модули автора
+// открытых ей категорий. $1 - группа.
+const groupModulesQuery = "SELECT group_modules.module_id FROM group_modules WHERE group_modules.group_id = $1 " +
+	"UNION " +
+	"SELECT category_modules.module_id FROM group_categories " +
+	"INNER JOIN categories ON categories.id = group_categories.category_id " +
+	"INNER JOIN category_modules ON category_modules.category_id = categories.id " +
+	"INNER JOIN modules ON modules.id = category_modules.module_id " +
+	"WHERE group_categories.group_id = $1 AND categories.owner_id = modules.owner_id"
+
+// GetGroupModulesResults возвращает результаты учеников группы по ее модулям
+func (gr *GroupsRepo) GetGroupModulesResults(groupId int) ([]entity.ModuleResult, error) {
+	rows, err := gr.psql.Query("SELECT modules_res.module_id, modules_res.owner, modules_res.time, results.id, results.type, results.score, results.duration, results.questions_count "+
+		"FROM modules_res INNER JOIN results ON modules_res.result_id = results.id "+
+		"INNER JOIN group_members ON group_members.user_id = modules_res.owner "+
+		"WHERE group_members.group_id = $1 AND group_members.role = $2 "+
+		"AND modules_res.module_id IN ("+groupModulesQuery+") "+
+		"ORDER BY modules_res.time DESC", groupId, entity.StudentRole)
+	if err != nil {
+		return []entity.ModuleResult{}, repo.NewDBError("modules_res", "select", err)
+	}
+	defer rows.Close()
+
+	modulesResults := []entity.ModuleResult{}
+	for rows.Next() {
+		mr := entity.ModuleResult{}
+		err = rows.Scan(&mr.ModuleId,
+			&mr.Owner,
+			&mr.Time,
+			&mr.Result.Id,
+			&mr.Result.Type,
+			&mr.Result.Score,
+			&mr.Result.Duration,
+			&mr.Result.QuestionsCount)
+		if err != nil {
+			return []entity.ModuleResult{}, repo.NewDBError("modules_res", "select", err)
+		}
+		modulesResults = append(modulesResults, mr)
+	}
+	return modulesResults, nil
+}
+
+// GetGroupCategoriesResults возвращает результаты учеников группы по ее категориям
+func (gr *GroupsRepo) GetGroupCategoriesResults(groupId int) ([]entity.CategoryModulesResult, error) {
+	rows, err := gr.psql.Query("SELECT category_res.category_result_id, category_res.category_id, category_res.owner, category_res.time, "+
+		"category_res.module_id, results.id, results.type, results.score, results.duration, results.questions_count "+
+		"FROM category_res INNER JOIN results ON category_res.result_id = results.id "+
+		"INNER JOIN group_members ON group_members.user_id = category_res.owner "+
+		"INNER JOIN group_categories ON group_categories.category_id = category_res.category_id "+
+		"AND group_categories.group_id = group_members.group_id "+
+		"WHERE group_members.group_id = $1 AND group_members.role = $2 "+
+		"ORDER BY category_res.time DESC, category_res.category_result_id", groupId, entity.StudentRole)
+	if err != nil {
+		return []entity.CategoryModulesResult{}, repo.NewDBError("category_res", "select", err)
+	}
+	defer rows.Close()
+
+	// строки одного прохождения категории идут подряд
+	categoriesResults := []entity.CategoryModulesResult{}
+	for rows.Next() {
+		cr := entity.CategoryModulesResult{}
+		mr := entity.ModuleResult{}
+		err = rows.Scan(&cr.CategoryResultId,
+			&cr.CategoryId,
+			&cr.Owner,
+			&cr.Time,
+			&mr.ModuleId,
+			&mr.Result.Id,
+			&mr.Result.Type,
+			&mr.Result.Score,
+			&mr.Result.Duration,
+			&mr.Result.QuestionsCount)
+		if err != nil {
+			return []entity.CategoryModulesResult{}, repo.NewDBError("category_res", "select", err)
+		}
+
+		last := len(categoriesResults) - 1
+		if last < 0 || categoriesResults[last].CategoryResultId != cr.CategoryResultId {
+			categoriesResults = append(categoriesResults, cr)
+			last++
+		}
+		categoriesResults[last].Modules = append(categoriesResults[last].Modules, mr)
+	}
+	return categoriesResults, nil
+}
+
+// GetModuleTeachers возвращает преподавателей групп ученика, которым открыт модуль
+func (gr *GroupsRepo) GetModuleTeachers(studentId, moduleId int) ([]int, error) {
+	return gr.getTeachers("SELECT DISTINCT teachers.user_id FROM group_members AS students "+
+		"INNER JOIN group_members AS teachers ON teachers.group_id = students.group_id AND teachers.role = $3 "+
+		"WHERE students.user_id = $1 AND students.role = $4 "+
+		"AND $2 IN (SELECT group_modules.module_id FROM group_modules WHERE group_modules.group_id = students.group_id "+
+		"UNION "+
+		"SELECT category_modules.module_id FROM group_categories "+
+		"INNER JOIN categories ON categories.id = group_categories.category_id "+
+		"INNER JOIN category_modules ON category_modules.category_id = categories.id "+
+		"INNER JOIN modules ON modules.id = category_modules.module_id "+
+		"WHERE group_categories.group_id = students.group_id AND categories.owner_id = modules.owner_id)",
+		studentId, moduleId, entity.TeacherRole, entity.StudentRole)
+}
+
+// GetCategoryTeachers возвращает преподавателей групп ученика, которым открыта категория
+func (gr *GroupsRepo) GetCategoryTeachers(studentId, categoryId int) ([]int, error) {
+	return gr.getTeachers("SELECT DISTINCT teachers.user_id FROM group_members AS students "+
+		"INNER JOIN group_members AS teachers ON teachers.group_id = students.group_id AND teachers.role = $3 "+
+		"INNER JOIN group_categories ON group_categories.group_id = students.group_id "+
+		"WHERE students.user_id = $1 AND students.role = $4 AND group_categories.category_id = $2",
+		studentId, categoryId, entity.TeacherRole, entity.StudentRole)
+}
+
+func (gr *GroupsRepo) getTeachers(query string, args ...any) ([]int, error) {
+	rows, err := gr.psql.Query(query, args...)
+	if err != nil {
+		return []int{}, repo.NewDBError("group_members", "select", err)
+	}
+	defer rows.Close()
+
+	teachers := []int{}
+	for rows.Next() {
+		var userId int
+		if err = rows.Scan(&userId); err != nil {
+			return []int{}, repo.NewDBError("group_members", "select", err)
+		}
+		teachers = append(teachers, userId)
+	}
+	return teachers, nil
+}
+
+func (gr *GroupsRepo) InsertGroup(group entity.Group) error {
+	result, err := gr.psql.Exec("INSERT INTO groups(name, owner_id, join_code) VALUES($1, $2, $3)",
+		group.Name, group.OwnerId, group.JoinCode)
+	if err != nil {
+		return repo.NewDBError("groups", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) RenameGroup(groupId int, name string) error {
+	result, err := gr.psql.Exec("UPDATE groups SET name = $2 WHERE id = $1", groupId, name)
+	if err != nil {
+		return repo.NewDBError("groups", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) SetGroupJoinCode(groupId int, code string) error {
+	result, err := gr.psql.Exec("UPDATE groups SET join_code = $2 WHERE id = $1", groupId, code)
+	if err != nil {
+		return repo.NewDBError("groups", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+// DeleteGroup удаляет группу вместе с участниками и списком материалов
+func (gr *GroupsRepo) DeleteGroup(groupId int) error {
+	result, err := gr.psql.Exec("DELETE FROM groups WHERE id = $1", groupId)
+	if err != nil {
+		return repo.NewDBError("groups", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) InsertGroupMember(groupId, userId int, role string) error {
+	result, err := gr.psql.Exec("INSERT INTO group_members(group_id, user_id, role) VALUES($1, $2, $3)", groupId, userId, role)
+	if err != nil {
+		return repo.NewDBError("group_members", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) DeleteGroupMember(groupId, userId int) error {
+	result, err := gr.psql.Exec("DELETE FROM group_members WHERE group_id = $1 AND user_id = $2", groupId, userId)
+	if err != nil {
+		return repo.NewDBError("group_members", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) InsertGroupModule(groupId, moduleId int) error {
+	result, err := gr.psql.Exec("INSERT INTO group_modules(group_id, module_id) VALUES($1, $2)", groupId, moduleId)
+	if err != nil {
+		return repo.NewDBError("group_modules", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) DeleteGroupModule(groupId, moduleId int) error {
+	result, err := gr.psql.Exec("DELETE FROM group_modules WHERE group_id = $1 AND module_id = $2", groupId, moduleId)
+	if err != nil {
+		return repo.NewDBError("group_modules", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) InsertGroupCategory(groupId, categoryId int) error {
+	result, err := gr.psql.Exec("INSERT INTO group_categories(group_id, category_id) VALUES($1, $2)", groupId, categoryId)
+	if err != nil {
+		return repo.NewDBError("group_categories", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (gr *GroupsRepo) DeleteGroupCategory(groupId, categoryId int) error {
+	result, err := gr.psql.Exec("DELETE FROM group_categories WHERE group_id = $1 AND category_id = $2", groupId, categoryId)
+	if err != nil {
+		return repo.NewDBError("group_categories", "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package persistent
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"time"
+)
+
+type IdempotencyRepo struct {
+	psql repo.PSQL
+}
+
+func NewIdempotencyRepo(psql repo.PSQL) *IdempotencyRepo {
+	return &IdempotencyRepo{psql: psql}
+}
+
+func (ir *IdempotencyRepo) GetIdempotencyKey(ownerId int, key string, createdAfter time.Time) (entity.IdempotencyKey, error) {
+	row := ir.psql.QueryRow("SELECT owner, key, kind, request_hash, response, created_at FROM idempotency_keys "+
+		"WHERE owner = $1 AND key = $2 AND created_at >= $3", ownerId, key, createdAfter)
+
+	k := entity.IdempotencyKey{}
+	var response []byte
+	err := row.Scan(&k.Owner, &k.Key, &k.Kind, &k.RequestHash, &response, &k.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.IdempotencyKey{}, repo.NoSuchRecordToSelect
+		}
+		return entity.IdempotencyKey{}, repo.NewDBError("idempotency_keys", "select", err)
+	}
+
+	if err = json.Unmarshal(response, &k.Response); err != nil {
+		return entity.IdempotencyKey{}, repo.NewDBError("idempotency_keys", "select", err)
+	}
+	return k, nil
+}
+
+func (ir *IdempotencyRepo) InsertIdempotencyKey(key entity.IdempotencyKey) error {
+	response, err := json.Marshal(key.Response)
+	if err != nil {
+		return repo.NewDBError("idempotency_keys", "insert", err)
+	}
+
+	result, err := ir.psql.Exec("INSERT INTO idempotency_keys(owner, key, kind, request_hash, response, created_at) "+
+		"VALUES($1, $2, $3, $4, $5, $6)", key.Owner, key.Key, key.Kind, key.RequestHash, response, key.CreatedAt)
+	if err != nil {
+		return repo.NewDBError("idempotency_keys", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (ir *IdempotencyRepo) DeleteExpiredIdempotencyKeys(createdBefore time.Time) error {
+	_, err := ir.psql.Exec("DELETE FROM idempotency_keys WHERE created_at < $1", createdBefore)
+	if err != nil {
+		return repo.NewDBError("idempotency_keys", "delete", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package persistent
+
+import (
+	"database/sql"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type ModerationRepo struct {
+	psql repo.PSQL
+}
+
+func NewModerationRepo(psql repo.PSQL) *ModerationRepo {
+	return &ModerationRepo{psql: psql}
+}
+
+const reportsColumns = "id, target_kind, target_id, reporter_id, reason, note, status, created_at, resolved_at, resolved_by"
+
+func scanReport(row rowScanner, r *entity.Report) error {
+	return row.Scan(&r.Id, &r.TargetKind, &r.TargetId, &r.ReporterId, &r.Reason, &r.Note, &r.Status,
+		&r.CreatedAt, &r.ResolvedAt, &r.ResolvedBy)
+}
+
+func (mr *ModerationRepo) GetReportById(reportId int) (entity.Report, error) {
+	r := entity.Report{}
+	err := scanReport(mr.psql.QueryRow("SELECT "+reportsColumns+" FROM reports WHERE id = $1", reportId), &r)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Report{}, repo.NoSuchRecordToSelect
+		}
+		return entity.Report{}, repo.NewDBError("reports", "select", err)
+	}
+	return r, nil
+}
+
+// GetReports возвращает очередь жалоб в порядке поступления
+func (mr *ModerationRepo) GetReports(status string, limit, offset int) ([]entity.Report, error) {
+	rows, err := mr.psql.Query("SELECT "+reportsColumns+" FROM reports WHERE status = $1 "+
+		"ORDER BY created_at, id LIMIT $2 OFFSET $3", status, limit, offset)
+	if err != nil {
+		return []entity.Report{}, repo.NewDBError("reports", "select", err)
+	}
+	defer rows.Close()
+
+	reports := []entity.Report{}
+	for rows.Next() {
+		r := entity.Report{}
+		if err = scanReport(rows, &r); err != nil {
+			return []entity.Report{}, repo.NewDBError("reports", "select", err)
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+func (mr *ModerationRepo) HasOpenReport(targetKind string, targetId, reporterId int) (bool, error) {
+	row := mr.psql.QueryRow("SELECT EXISTS (SELECT 1 FROM reports "+
+		"WHERE target_kind = $1 AND target_id = $2 AND reporter_id = $3 AND status = $4)",
+		targetKind, targetId, reporterId, entity.OpenReport)
+
+	var exists bool
+	if err := row.Scan(&exists); err != nil {
+		return false, repo.NewDBError("reports", "select", err)
+	}
+	return exists, nil
+}
+
+func (mr *ModerationRepo) GetLastInsertedReportId() (int, error) {
+	row := mr.psql.QueryRow("SELECT MAX(id) FROM reports")
+
+	var id int
+	if err := row.Scan(&id); err != nil {
+		return 0, repo.NewDBError("reports", "select", err)
+	}
+	return id, nil
+}
+
+// GetModerationLog возвращает журнал модерации от новых записей к старым
+func (mr *ModerationRepo) GetModerationLog(limit, offset int) ([]entity.ModerationEntry, error) {
+	rows, err := mr.psql.Query("SELECT id, admin_id, action, target_kind, target_id, report_id, note, created_at "+
+		"FROM moderation_log ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2", limit, offset)
+	if err != nil {
+		return []entity.ModerationEntry{}, repo.NewDBError("moderation_log", "select", err)
+	}
+	defer rows.Close()
+
+	entries := []entity.ModerationEntry{}
+	for rows.Next() {
+		e := entity.ModerationEntry{}
+		err = rows.Scan(&e.Id, &e.AdminId, &e.Action, &e.TargetKind, &e.TargetId, &e.ReportId, &e.Note, &e.CreatedAt)
+		if err != nil {
+			return []entity.ModerationEntry{}, repo.NewDBError("moderation_log", "select", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (mr *ModerationRepo) InsertReport(report entity.Report) error {
+	result, err := mr.psql.Exec("INSERT INTO reports(target_kind, target_id, reporter_id, reason, note) "+
+		"VALUES($1, $2, $3, $4, $5)", report.TargetKind, report.TargetId, report.ReporterId, report.Reason, report.Note)
+	if err != nil {
+		return repo.NewDBError("reports", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+// ResolveReport закрывает открытую жалобу
+func (mr *ModerationRepo) ResolveReport(reportId, adminId int, status string) error {
+	result, err := mr.psql.Exec("UPDATE reports SET status = $1, resolved_at = NOW(), resolved_by = $2 "+
+		"WHERE id = $3 AND status = $4", status, adminId, reportId, entity.OpenReport)
+	if err != nil {
+		return repo.NewDBError("reports", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+// ResolveTargetReports закрывает все открытые жалобы на объект
+func (mr *ModerationRepo) ResolveTargetReports(targetKind string, targetId, adminId int, status string) error {
+	_, err := mr.psql.Exec("UPDATE reports SET status = $1, resolved_at = NOW(), resolved_by = $2 "+
+		"WHERE target_kind = $3 AND target_id = $4 AND status = $5",
+		status, adminId, targetKind, targetId, entity.OpenReport)
+	if err != nil {
+		return repo.NewDBError("reports", "update", err)
+	}
+	return nil
+}
+
+func (mr *ModerationRepo) InsertModerationEntry(entry entity.ModerationEntry) error {
+	result, err := mr.psql.Exec("INSERT INTO moderation_log(admin_id, action, target_kind, target_id, report_id, note) "+
+		"VALUES($1, $2, $3, $4, $5, $6)", entry.AdminId, entry.Action, entry.TargetKind, entry.TargetId, entry.ReportId, entry.Note)
+	if err != nil {
+		return repo.NewDBError("moderation_log", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
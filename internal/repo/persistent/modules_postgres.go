@@ -5,6 +5,9 @@ import (
 	"errors"
 	"interactive_learning/internal/entity"
 	"interactive_learning/internal/repo"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type ModulesRepo struct {
@@ -15,9 +18,37 @@ func NewModulesRepo(psql repo.PSQL) *ModulesRepo {
 	return &ModulesRepo{psql: psql}
 }
 
-func (mr *ModulesRepo) GetModulesWithSimilarName(name string, limit, offset int) ([]entity.Module, error) {
+const modulesColumns = "modules.id, modules.name, modules.owner_id, modules.type, modules.forked_from, modules.forked_from_owner, " +
+	"ARRAY(SELECT module_tags.tag FROM module_tags WHERE module_tags.module_id = modules.id ORDER BY module_tags.tag), " +
+	"modules.description, modules.difficulty, modules.created_at, modules.updated_at, " +
+	"(SELECT COUNT(*) FROM cards WHERE cards.module_id = modules.id AND cards.deleted_at IS NULL), " +
+	"(SELECT cards.term_lang FROM cards WHERE cards.module_id = modules.id AND cards.deleted_at IS NULL " +
+	"GROUP BY cards.term_lang ORDER BY COUNT(*) DESC, cards.term_lang LIMIT 1), " +
+	"(SELECT cards.def_lang FROM cards WHERE cards.module_id = modules.id AND cards.deleted_at IS NULL " +
+	"GROUP BY cards.def_lang ORDER BY COUNT(*) DESC, cards.def_lang LIMIT 1), " +
+	"modules.hidden_at IS NOT NULL, modules.version"
+
+// scanModule читает колонки modulesColumns, extra - колонки после них
+func scanModule(row rowScanner, m *entity.Module, extra ...any) error {
+	fork := forkOrigin{}
+	var termLang, defLang sql.NullString
+	dest := append([]any{&m.Id, &m.Name, &m.OwnerId, &m.Type, &fork.id, &fork.ownerId, pq.Array(&m.Tags),
+		&m.Description, &m.Difficulty, &m.CreatedAt, &m.UpdatedAt, &m.CardsCount, &termLang, &defLang, &m.Hidden, &m.Version}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	m.ForkedFrom = fork.toEntity()
+	m.TermLang, m.DefLang = termLang.String, defLang.String
+	return nil
+}
+
+// tag сужает поиск до модулей с этим тегом, пустой tag не учитывается
+func (mr *ModulesRepo) GetModulesWithSimilarName(name, tag string, limit, offset int) ([]entity.Module, error) {
 	name = "%" + name + "%"
-	rows, err := mr.psql.Query("SELECT modules.id, modules.name, modules.owner_id, modules.type FROM modules WHERE name LIKE $1 LIMIT $2 OFFSET $3", name, limit, offset)
+	rows, err := mr.psql.Query("SELECT "+modulesColumns+" FROM modules "+
+		"WHERE name LIKE $1 AND deleted_at IS NULL AND hidden_at IS NULL AND type <> $5 "+
+		"AND ($2 = '' OR EXISTS (SELECT 1 FROM module_tags WHERE module_tags.module_id = modules.id AND module_tags.tag = $2)) "+
+		"LIMIT $3 OFFSET $4", name, tag, limit, offset, entity.UnlistedModule)
 	if err != nil {
 		return []entity.Module{}, repo.NewDBError("modules", "select", err)
 	}
@@ -25,7 +56,7 @@ func (mr *ModulesRepo) GetModulesWithSimilarName(name string, limit, offset int)
 	modules := []entity.Module{}
 	for rows.Next() {
 		m := entity.Module{}
-		if err = rows.Scan(&m.Id, &m.Name, &m.OwnerId, &m.Type); err != nil {
+		if err = scanModule(rows, &m); err != nil {
 			return []entity.Module{}, repo.NewDBError("modules", "select", err)
 		}
 		modules = append(modules, m)
@@ -35,7 +66,7 @@ func (mr *ModulesRepo) GetModulesWithSimilarName(name string, limit, offset int)
 }
 
 func (mr *ModulesRepo) GetModulesByUser(userId int) ([]entity.Module, error) {
-	rows, err := mr.psql.Query("SELECT * FROM modules WHERE owner_id = $1", userId)
+	rows, err := mr.psql.Query("SELECT "+modulesColumns+" FROM modules WHERE owner_id = $1 AND deleted_at IS NULL", userId)
 	if err != nil {
 		return []entity.Module{}, repo.NewDBError("modules", "select", err)
 	}
@@ -43,7 +74,7 @@ func (mr *ModulesRepo) GetModulesByUser(userId int) ([]entity.Module, error) {
 	modules := []entity.Module{}
 	for rows.Next() {
 		m := entity.Module{}
-		err = rows.Scan(&m.Id, &m.Name, &m.OwnerId, &m.Type)
+		err = scanModule(rows, &m)
 		if err != nil {
 			return []entity.Module{}, repo.NewDBError("modules", "select", err)
 		}
@@ -53,9 +84,9 @@ func (mr *ModulesRepo) GetModulesByUser(userId int) ([]entity.Module, error) {
 }
 
 func (cr *ModulesRepo) GetModuleById(moduleId int) (entity.Module, error) {
-	row := cr.psql.QueryRow("SELECT * FROM modules WHERE id = $1", moduleId)
+	row := cr.psql.QueryRow("SELECT "+modulesColumns+" FROM modules WHERE id = $1 AND deleted_at IS NULL", moduleId)
 	m := entity.Module{}
-	err := row.Scan(&m.Id, &m.Name, &m.OwnerId, &m.Type)
+	err := scanModule(row, &m)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return entity.Module{}, repo.NoSuchRecordToSelect
@@ -79,7 +110,7 @@ func (mr *ModulesRepo) GetLastInsertedModuleId() (int, error) {
 }
 
 func (mr *ModulesRepo) GetModuleOwnerId(moduleId int) (int, error) {
-	row := mr.psql.QueryRow("SELECT owner_id FROM modules WHERE id = $1", moduleId)
+	row := mr.psql.QueryRow("SELECT owner_id FROM modules WHERE id = $1 AND deleted_at IS NULL", moduleId)
 	var id int
 	err := row.Scan(&id)
 	if err != nil {
@@ -91,21 +122,37 @@ func (mr *ModulesRepo) GetModuleOwnerId(moduleId int) (int, error) {
 	return id, nil
 }
 
-func (mr *ModulesRepo) GetPopularModules(limit, offset int) ([]entity.PopularModule, error) {
-	rows, err := mr.psql.Query("SELECT modules.*, COUNT(DISTINCT modules_res.owner) as count "+
-		"FROM modules INNER JOIN modules_res ON modules.id = modules_res.module_id "+
-		"WHERE modules.type = 0 AND time >= NOW() - INTERVAL '7 days' "+
-		"GROUP BY modules.id, modules_res.owner "+
-		"ORDER BY count "+
+// popularOrder - условие попадания в список популярных и порядок для каждой сортировки
+var popularOrder = map[string]struct{ having, orderBy string }{
+	entity.PopularByLearners: {"learners > 0", "learners DESC, ratings_count DESC, id"},
+	entity.PopularByRating:   {"ratings_count > 0", "rating DESC, ratings_count DESC, learners DESC, id"},
+}
+
+// GetPopularModules считает учеников за последнюю неделю и среднюю оценку модуля
+func (mr *ModulesRepo) GetPopularModules(limit, offset int, sortBy string) ([]entity.PopularModule, error) {
+	order, ok := popularOrder[sortBy]
+	if !ok {
+		order = popularOrder[entity.PopularByLearners]
+	}
+	rows, err := mr.psql.Query("SELECT * FROM (SELECT "+modulesColumns+", COUNT(DISTINCT modules_res.owner) AS learners, "+
+		"ratings.average AS rating, COALESCE(ratings.count, 0) AS ratings_count "+
+		"FROM modules LEFT JOIN modules_res ON modules.id = modules_res.module_id AND modules_res.time >= NOW() - INTERVAL '7 days' "+
+		"LEFT JOIN (SELECT module_id, ROUND(AVG(rating), 2)::float8 AS average, COUNT(*) AS count "+
+		"FROM module_ratings GROUP BY module_id) AS ratings ON ratings.module_id = modules.id "+
+		"WHERE modules.type = 0 AND modules.deleted_at IS NULL AND modules.hidden_at IS NULL "+
+		"GROUP BY modules.id, ratings.average, ratings.count) AS popular "+
+		"WHERE "+order.having+" "+
+		"ORDER BY "+order.orderBy+" "+
 		"LIMIT $1 OFFSET $2;", limit, offset)
 	if err != nil {
 		return []entity.PopularModule{}, repo.NewDBError("modules", "select", err)
 	}
+	defer rows.Close()
 
 	modules := []entity.PopularModule{}
 	for rows.Next() {
 		m := entity.PopularModule{}
-		err = rows.Scan(&m.Mod.Id, &m.Mod.Name, &m.Mod.OwnerId, &m.Mod.Type, &m.Count)
+		err = scanModule(rows, &m.Mod, &m.Count, &m.Rating, &m.RatingsCount)
 		if err != nil {
 			return []entity.PopularModule{}, repo.NewDBError("modules", "select", err)
 		}
@@ -115,9 +162,49 @@ func (mr *ModulesRepo) GetPopularModules(limit, offset int) ([]entity.PopularMod
 	return modules, nil
 }
 
+func (mr *ModulesRepo) GetTrashedModules(ownerId int) ([]entity.TrashedModule, error) {
+	rows, err := mr.psql.Query("SELECT "+modulesColumns+", modules.deleted_at "+
+		"FROM modules WHERE owner_id = $1 AND deleted_at IS NOT NULL "+
+		"ORDER BY deleted_at DESC", ownerId)
+	if err != nil {
+		return []entity.TrashedModule{}, repo.NewDBError("modules", "select", err)
+	}
+	defer rows.Close()
+
+	modules := []entity.TrashedModule{}
+	for rows.Next() {
+		m := entity.TrashedModule{}
+		err = scanModule(rows, &m.Mod, &m.DeletedAt)
+		if err != nil {
+			return []entity.TrashedModule{}, repo.NewDBError("modules", "select", err)
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+func (mr *ModulesRepo) GetTrashedModuleById(moduleId int) (entity.TrashedModule, error) {
+	row := mr.psql.QueryRow("SELECT "+modulesColumns+", modules.deleted_at "+
+		"FROM modules WHERE id = $1 AND deleted_at IS NOT NULL", moduleId)
+	m := entity.TrashedModule{}
+	err := scanModule(row, &m.Mod, &m.DeletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.TrashedModule{}, repo.NoSuchRecordToSelect
+		}
+		return entity.TrashedModule{}, repo.NewDBError("modules", "select", err)
+	}
+	return m, nil
+}
+
+func (mr *ModulesRepo) GetModulesDeletedBefore(deletedBefore time.Time) ([]int, error) {
+	return selectIds(mr.psql, "modules", "SELECT id FROM modules WHERE deleted_at < $1", deletedBefore)
+}
+
 func (mr *ModulesRepo) InsertModule(module entity.ModuleToCreate) error {
-	result, err := mr.psql.Exec("INSERT INTO modules(name, owner_id, type) "+
-		"VALUES($1, $2, $3)", module.Name, module.OwnerId, module.Type)
+	forkedFrom, forkedFromOwner := forkOriginArgs(module.ForkedFrom)
+	result, err := mr.psql.Exec("INSERT INTO modules(name, owner_id, type, forked_from, forked_from_owner, description, difficulty) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7)", module.Name, module.OwnerId, module.Type, forkedFrom, forkedFromOwner, module.Description, module.Difficulty)
 	if err != nil {
 		return repo.NewDBError("modules", "insert", err)
 	}
@@ -127,10 +214,106 @@ func (mr *ModulesRepo) InsertModule(module entity.ModuleToCreate) error {
 	return nil
 }
 
-func (mr *ModulesRepo) RenameModule(moduleId int, newName string) error {
+// RenameModule, UpdateModuleType и UpdateModuleInfo меняют модуль, только если его версия все еще version,
+// и возвращают новую версию
+func (mr *ModulesRepo) RenameModule(moduleId, version int, newName string) (int, error) {
+	err := mr.psql.QueryRow("UPDATE modules "+
+		"SET name = $1, updated_at = NOW(), version = version + 1 "+
+		"WHERE id = $2 AND version = $3 "+
+		"RETURNING version", newName, moduleId, version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repo.NoSuchRecordToUpdate
+	} else if err != nil {
+		return 0, repo.NewDBError("modules", "update", err)
+	}
+	return version, nil
+}
+
+func (mr *ModulesRepo) UpdateModuleType(moduleId, version, newType int) (int, error) {
+	err := mr.psql.QueryRow("UPDATE modules "+
+		"SET type = $1, updated_at = NOW(), version = version + 1 "+
+		"WHERE id = $2 AND version = $3 "+
+		"RETURNING version", newType, moduleId, version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repo.NoSuchRecordToUpdate
+	} else if err != nil {
+		return 0, repo.NewDBError("modules", "update", err)
+	}
+	return version, nil
+}
+
+func (mr *ModulesRepo) GetModuleShareSlug(moduleId int) (string, error) {
+	var slug sql.NullString
+	err := mr.psql.QueryRow("SELECT share_slug FROM modules WHERE id = $1 AND deleted_at IS NULL", moduleId).Scan(&slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", repo.NoSuchRecordToSelect
+	} else if err != nil {
+		return "", repo.NewDBError("modules", "select", err)
+	}
+	return slug.String, nil
+}
+
+func (mr *ModulesRepo) GetModuleByShareSlug(slug string) (entity.Module, error) {
+	row := mr.psql.QueryRow("SELECT "+modulesColumns+" FROM modules WHERE share_slug = $1 AND deleted_at IS NULL", slug)
+	m := entity.Module{}
+	err := scanModule(row, &m)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Module{}, repo.NoSuchRecordToSelect
+	} else if err != nil {
+		return entity.Module{}, repo.NewDBError("modules", "select", err)
+	}
+	return m, nil
+}
+
+// SetModuleShareSlug задает новую ссылку, старая перестает открываться
+func (mr *ModulesRepo) SetModuleShareSlug(moduleId int, slug string) error {
+	result, err := mr.psql.Exec("UPDATE modules SET share_slug = $1 WHERE id = $2", slug, moduleId)
+	if err != nil {
+		return repo.NewDBError("modules", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (mr *ModulesRepo) UpdateModuleInfo(moduleId, version int, info entity.Info) (int, error) {
+	err := mr.psql.QueryRow("UPDATE modules "+
+		"SET description = $1, difficulty = $2, updated_at = NOW(), version = version + 1 "+
+		"WHERE id = $3 AND version = $4 "+
+		"RETURNING version", info.Description, info.Difficulty, moduleId, version).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repo.NoSuchRecordToUpdate
+	} else if err != nil {
+		return 0, repo.NewDBError("modules", "update", err)
+	}
+	return version, nil
+}
+
+// TouchModule отмечает изменение карточек модуля
+func (mr *ModulesRepo) TouchModule(moduleId int) error {
+	_, err := mr.psql.Exec("UPDATE modules SET updated_at = NOW() WHERE id = $1", moduleId)
+	if err != nil {
+		return repo.NewDBError("modules", "update", err)
+	}
+	return nil
+}
+
+// SetModuleHidden скрывает модуль по решению модератора или возвращает его
+func (mr *ModulesRepo) SetModuleHidden(moduleId int, hidden bool) error {
+	result, err := mr.psql.Exec("UPDATE modules SET hidden_at = CASE WHEN $2 THEN NOW() END "+
+		"WHERE id = $1 AND deleted_at IS NULL", moduleId, hidden)
+	if err != nil {
+		return repo.NewDBError("modules", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
+
+func (mr *ModulesRepo) TrashModule(moduleId int) error {
 	result, err := mr.psql.Exec("UPDATE modules "+
-		"SET name = $1 "+
-		"WHERE id = $2", newName, moduleId)
+		"SET deleted_at = NOW() "+
+		"WHERE id = $1 AND deleted_at IS NULL", moduleId)
 	if err != nil {
 		return repo.NewDBError("modules", "update", err)
 	} else if count, _ := result.RowsAffected(); count == 0 {
@@ -139,10 +322,10 @@ func (mr *ModulesRepo) RenameModule(moduleId int, newName string) error {
 	return nil
 }
 
-func (mr *ModulesRepo) UpdateModuleType(moduleId, newType int) error {
+func (mr *ModulesRepo) RestoreModule(moduleId int) error {
 	result, err := mr.psql.Exec("UPDATE modules "+
-		"SET type = $1 "+
-		"WHERE id = $2", newType, moduleId)
+		"SET deleted_at = NULL "+
+		"WHERE id = $1 AND deleted_at IS NOT NULL", moduleId)
 	if err != nil {
 		return repo.NewDBError("modules", "update", err)
 	} else if count, _ := result.RowsAffected(); count == 0 {
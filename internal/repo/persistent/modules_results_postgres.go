@@ -17,7 +17,7 @@ func NewModulesResultsRepo(psql repo.PSQL) *ModulesResultsRepo {
 }
 
 func (mrr *ModulesResultsRepo) GetModulesResultById(resultId int) (entity.ModuleResult, error) {
-	row := mrr.psql.QueryRow("SELECT modules_res.module_id, modules_res.result_id, modules_res.time, modules_res.owner, results.type FROM modules_res INNER JOIN results ON modules_res.result_id = results.id "+
+	row := mrr.psql.QueryRow("SELECT modules_res.module_id, modules_res.result_id, modules_res.time, modules_res.owner, results.type, results.score, results.duration, results.questions_count FROM modules_res INNER JOIN results ON modules_res.result_id = results.id "+
 		"WHERE modules_res.result_id = $1", resultId)
 
 	moduleRes := entity.ModuleResult{}
@@ -25,7 +25,10 @@ func (mrr *ModulesResultsRepo) GetModulesResultById(resultId int) (entity.Module
 		&moduleRes.Result.Id,
 		&moduleRes.Time,
 		&moduleRes.Owner,
-		&moduleRes.Result.Type); err != nil {
+		&moduleRes.Result.Type,
+		&moduleRes.Result.Score,
+		&moduleRes.Result.Duration,
+		&moduleRes.Result.QuestionsCount); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return entity.ModuleResult{}, repo.NoSuchRecordToSelect
 		}
@@ -35,7 +38,7 @@ func (mrr *ModulesResultsRepo) GetModulesResultById(resultId int) (entity.Module
 }
 
 func (mrr *ModulesResultsRepo) GetModulesResByOwner(ownerId int) ([]entity.ModuleResult, error) {
-	rows, err := mrr.psql.Query("SELECT modules_res.module_id, modules_res.time, results.* "+
+	rows, err := mrr.psql.Query("SELECT modules_res.module_id, modules_res.time, results.id, results.type, results.score, results.duration, results.questions_count "+
 		"FROM modules_res INNER JOIN results ON modules_res.result_id = results.id "+
 		"WHERE modules_res.\"owner\" = $1", ownerId)
 	if err != nil {
@@ -49,7 +52,10 @@ func (mrr *ModulesResultsRepo) GetModulesResByOwner(ownerId int) ([]entity.Modul
 		err := rows.Scan(&mr.ModuleId,
 			&mr.Time,
 			&mr.Result.Id,
-			&mr.Result.Type)
+			&mr.Result.Type,
+			&mr.Result.Score,
+			&mr.Result.Duration,
+			&mr.Result.QuestionsCount)
 		if err != nil {
 			return []entity.ModuleResult{}, repo.NewDBError("modules_res", "select", err)
 		}
@@ -60,7 +66,7 @@ func (mrr *ModulesResultsRepo) GetModulesResByOwner(ownerId int) ([]entity.Modul
 }
 
 func (mrr *ModulesResultsRepo) GetResultsToModuleOwner(moduleId, ownerId int) ([]entity.ModuleResult, error) {
-	rows, err := mrr.psql.Query("SELECT modules_res.time, results.* "+
+	rows, err := mrr.psql.Query("SELECT modules_res.time, results.id, results.type, results.score, results.duration, results.questions_count "+
 		"FROM modules_res INNER JOIN results ON modules_res.result_id = results.id "+
 		"WHERE modules_res.module_id = $1 AND modules_res.owner = $2", moduleId, ownerId)
 	if err != nil {
@@ -73,7 +79,10 @@ func (mrr *ModulesResultsRepo) GetResultsToModuleOwner(moduleId, ownerId int) ([
 		err := rows.Scan(
 			&mr.Time,
 			&mr.Result.Id,
-			&mr.Result.Type)
+			&mr.Result.Type,
+			&mr.Result.Score,
+			&mr.Result.Duration,
+			&mr.Result.QuestionsCount)
 		if err != nil {
 			return []entity.ModuleResult{}, repo.NewDBError("modules_res", "select", err)
 		}
@@ -84,7 +93,7 @@ func (mrr *ModulesResultsRepo) GetResultsToModuleOwner(moduleId, ownerId int) ([
 }
 
 func (mrr *ModulesResultsRepo) GetResultsToModule(moduleId int) ([]entity.ModuleResult, error) {
-	rows, err := mrr.psql.Query("SELECT modules_res.\"owner\", modules_res.time, results.* "+
+	rows, err := mrr.psql.Query("SELECT modules_res.\"owner\", modules_res.time, results.id, results.type, results.score, results.duration, results.questions_count "+
 		"FROM modules_res INNER JOIN results ON modules_res.result_id = results.id "+
 		"WHERE modules_res.module_id = $1", moduleId)
 	if err != nil {
@@ -97,7 +106,10 @@ func (mrr *ModulesResultsRepo) GetResultsToModule(moduleId int) ([]entity.Module
 		err := rows.Scan(&mr.Owner,
 			&mr.Time,
 			&mr.Result.Id,
-			&mr.Result.Type)
+			&mr.Result.Type,
+			&mr.Result.Score,
+			&mr.Result.Duration,
+			&mr.Result.QuestionsCount)
 		if err != nil {
 			return []entity.ModuleResult{}, repo.NewDBError("modules_res", "select", err)
 		}
@@ -0,0 +1,58 @@
+package persistent
+
+import (
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type NotificationsRepo struct {
+	psql repo.PSQL
+}
+
+func NewNotificationsRepo(psql repo.PSQL) *NotificationsRepo {
+	return &NotificationsRepo{psql: psql}
+}
+
+func (nr *NotificationsRepo) GetNotifications(userId, limit, offset int) ([]entity.Notification, error) {
+	rows, err := nr.psql.Query("SELECT id, user_id, kind, target_kind, target_id, text, created_at, read_at "+
+		"FROM notifications WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3", userId, limit, offset)
+	if err != nil {
+		return []entity.Notification{}, repo.NewDBError("notifications", "select", err)
+	}
+	defer rows.Close()
+
+	notifications := []entity.Notification{}
+	for rows.Next() {
+		n := entity.Notification{}
+		err = rows.Scan(&n.Id, &n.UserId, &n.Kind, &n.TargetKind, &n.TargetId, &n.Text, &n.CreatedAt, &n.ReadAt)
+		if err != nil {
+			return []entity.Notification{}, repo.NewDBError("notifications", "select", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+func (nr *NotificationsRepo) InsertNotification(notification entity.Notification) error {
+	result, err := nr.psql.Exec("INSERT INTO notifications(user_id, kind, target_kind, target_id, text) "+
+		"VALUES($1, $2, $3, $4, $5)", notification.UserId, notification.Kind, notification.TargetKind,
+		notification.TargetId, notification.Text)
+	if err != nil {
+		return repo.NewDBError("notifications", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+// MarkNotificationRead отмечает прочитанным уведомление пользователя userId
+func (nr *NotificationsRepo) MarkNotificationRead(notificationId, userId int) error {
+	result, err := nr.psql.Exec("UPDATE notifications SET read_at = COALESCE(read_at, NOW()) "+
+		"WHERE id = $1 AND user_id = $2", notificationId, userId)
+	if err != nil {
+		return repo.NewDBError("notifications", "update", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package persistent
+
+import (
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type RatingsRepo struct {
+	psql repo.PSQL
+}
+
+func NewRatingsRepo(psql repo.PSQL) *RatingsRepo {
+	return &RatingsRepo{psql: psql}
+}
+
+func (rr *RatingsRepo) GetModuleRating(moduleId, userId int) (entity.RatingSummary, error) {
+	return rr.getRating("module_ratings", "module_id", moduleId, userId)
+}
+
+func (rr *RatingsRepo) GetCategoryRating(categoryId, userId int) (entity.RatingSummary, error) {
+	return rr.getRating("category_ratings", "category_id", categoryId, userId)
+}
+
+// getRating считает среднюю оценку и добавляет оценку пользователя userId, если она есть
+func (rr *RatingsRepo) getRating(table, column string, id, userId int) (entity.RatingSummary, error) {
+	row := rr.psql.QueryRow("SELECT ROUND(AVG(rating), 2)::float8, COUNT(*), "+
+		"(SELECT rating FROM "+table+" WHERE "+column+" = $1 AND user_id = $2) "+
+		"FROM "+table+" WHERE "+column+" = $1", id, userId)
+
+	summary := entity.RatingSummary{}
+	if err := row.Scan(&summary.Average, &summary.Count, &summary.My); err != nil {
+		return entity.RatingSummary{}, repo.NewDBError(table, "select", err)
+	}
+	return summary, nil
+}
+
+func (rr *RatingsRepo) SetModuleRating(moduleId, userId, rating int) error {
+	return rr.setRating("module_ratings", "module_id", moduleId, userId, rating)
+}
+
+func (rr *RatingsRepo) SetCategoryRating(categoryId, userId, rating int) error {
+	return rr.setRating("category_ratings", "category_id", categoryId, userId, rating)
+}
+
+// setRating ставит оценку или заменяет прежнюю оценку пользователя
+func (rr *RatingsRepo) setRating(table, column string, id, userId, rating int) error {
+	result, err := rr.psql.Exec("INSERT INTO "+table+"("+column+", user_id, rating) VALUES($1, $2, $3) "+
+		"ON CONFLICT ("+column+", user_id) DO UPDATE SET rating = EXCLUDED.rating, updated_at = NOW()",
+		id, userId, rating)
+	if err != nil {
+		return repo.NewDBError(table, "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (rr *RatingsRepo) DeleteModuleRating(moduleId, userId int) error {
+	return rr.deleteRating("module_ratings", "module_id", moduleId, userId)
+}
+
+func (rr *RatingsRepo) DeleteCategoryRating(categoryId, userId int) error {
+	return rr.deleteRating("category_ratings", "category_id", categoryId, userId)
+}
+
+func (rr *RatingsRepo) deleteRating(table, column string, id, userId int) error {
+	result, err := rr.psql.Exec("DELETE FROM "+table+" WHERE "+column+" = $1 AND user_id = $2", id, userId)
+	if err != nil {
+		return repo.NewDBError(table, "delete", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToDelete
+	}
+	return nil
+}
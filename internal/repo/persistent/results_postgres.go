@@ -16,7 +16,7 @@ func NewResultsRepo(psql repo.PSQL) *ResultsRepo {
 }
 
 func (rr *ResultsRepo) GetResultsByOwner(ownerId int) ([]entity.Result, error) {
-	rows, err := rr.psql.Query("SELECT * FROM results WHERE owner = $1", ownerId)
+	rows, err := rr.psql.Query("SELECT id, type, score, duration, questions_count FROM results WHERE owner = $1", ownerId)
 	if err != nil {
 		return []entity.Result{}, repo.NewDBError("results", "select", err)
 	}
@@ -26,7 +26,10 @@ func (rr *ResultsRepo) GetResultsByOwner(ownerId int) ([]entity.Result, error) {
 	for rows.Next() {
 		r := entity.Result{}
 		err = rows.Scan(&r.Id,
-			&r.Type)
+			&r.Type,
+			&r.Score,
+			&r.Duration,
+			&r.QuestionsCount)
 		if err != nil {
 			return []entity.Result{}, repo.NewDBError("results", "select", err)
 		}
@@ -37,10 +40,13 @@ func (rr *ResultsRepo) GetResultsByOwner(ownerId int) ([]entity.Result, error) {
 }
 
 func (rr *ResultsRepo) GetResultById(id int) (entity.Result, error) {
-	row := rr.psql.QueryRow("SELECT * FROM results id = $1", id)
+	row := rr.psql.QueryRow("SELECT id, type, score, duration, questions_count FROM results WHERE id = $1", id)
 	r := entity.Result{}
 	err := row.Scan(&r.Id,
-		&r.Type)
+		&r.Type,
+		&r.Score,
+		&r.Duration,
+		&r.QuestionsCount)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return entity.Result{}, repo.NoSuchRecordToSelect
@@ -50,6 +56,22 @@ func (rr *ResultsRepo) GetResultById(id int) (entity.Result, error) {
 	return r, nil
 }
 
+func (rr *ResultsRepo) GetResultOwnerAndModule(resultId int) (int, int, error) {
+	row := rr.psql.QueryRow("SELECT \"owner\", module_id FROM modules_res WHERE result_id = $1 "+
+		"UNION ALL "+
+		"SELECT \"owner\", module_id FROM category_res WHERE result_id = $1 "+
+		"LIMIT 1", resultId)
+	var ownerId, moduleId int
+	err := row.Scan(&ownerId, &moduleId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, -1, repo.NoSuchRecordToSelect
+		}
+		return -1, -1, repo.NewDBError("results", "select", err)
+	}
+	return ownerId, moduleId, nil
+}
+
 func (rr *ResultsRepo) GetLastInsertedResultId() (int, error) {
 	row := rr.psql.QueryRow("SELECT MAX(id) FROM results")
 	var id int
@@ -64,8 +86,8 @@ func (rr *ResultsRepo) GetLastInsertedResultId() (int, error) {
 }
 
 func (rr *ResultsRepo) InsertResult(result entity.Result) error {
-	res, err := rr.psql.Exec("INSERT INTO results(type) "+
-		"VALUES($1)", result.Type)
+	res, err := rr.psql.Exec("INSERT INTO results(type, score, duration, questions_count) "+
+		"VALUES($1, $2, $3, $4)", result.Type, result.Score, result.Duration, result.QuestionsCount)
 	if err != nil {
 		return repo.NewDBError("results", "insert", err)
 	}
@@ -0,0 +1,110 @@
+package persistent
+
+import (
+	"database/sql"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type RevisionsRepo struct {
+	psql repo.PSQL
+}
+
+func NewRevisionsRepo(psql repo.PSQL) *RevisionsRepo {
+	return &RevisionsRepo{psql: psql}
+}
+
+const cardRevisionsColumns = "id, card_id, editor, action, " +
+	"before_term_lang, before_term_text, before_def_lang, before_def_text, " +
+	"after_term_lang, after_term_text, after_def_lang, after_def_text, created_at"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCardRevision(row rowScanner) (entity.CardRevision, error) {
+	r := entity.CardRevision{}
+	err := row.Scan(&r.Id, &r.CardId, &r.EditorId, &r.Action,
+		&r.Before.Term.Lang, &r.Before.Term.Text, &r.Before.Definition.Lang, &r.Before.Definition.Text,
+		&r.After.Term.Lang, &r.After.Term.Text, &r.After.Definition.Lang, &r.After.Definition.Text,
+		&r.CreatedAt)
+	return r, err
+}
+
+func (rr *RevisionsRepo) GetCardRevisions(cardId int) ([]entity.CardRevision, error) {
+	rows, err := rr.psql.Query("SELECT "+cardRevisionsColumns+" FROM card_revisions "+
+		"WHERE card_id = $1 ORDER BY id DESC", cardId)
+	if err != nil {
+		return []entity.CardRevision{}, repo.NewDBError("card_revisions", "select", err)
+	}
+	defer rows.Close()
+
+	revisions := []entity.CardRevision{}
+	for rows.Next() {
+		r, err := scanCardRevision(rows)
+		if err != nil {
+			return []entity.CardRevision{}, repo.NewDBError("card_revisions", "select", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
+
+func (rr *RevisionsRepo) GetCardRevisionById(revisionId int) (entity.CardRevision, error) {
+	row := rr.psql.QueryRow("SELECT "+cardRevisionsColumns+" FROM card_revisions WHERE id = $1", revisionId)
+	r, err := scanCardRevision(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.CardRevision{}, repo.NoSuchRecordToSelect
+		}
+		return entity.CardRevision{}, repo.NewDBError("card_revisions", "select", err)
+	}
+	return r, nil
+}
+
+func (rr *RevisionsRepo) GetModuleRevisions(moduleId int) ([]entity.ModuleRevision, error) {
+	rows, err := rr.psql.Query("SELECT id, module_id, editor, action, old_name, new_name, old_type, new_type, created_at "+
+		"FROM module_revisions WHERE module_id = $1 ORDER BY id DESC", moduleId)
+	if err != nil {
+		return []entity.ModuleRevision{}, repo.NewDBError("module_revisions", "select", err)
+	}
+	defer rows.Close()
+
+	revisions := []entity.ModuleRevision{}
+	for rows.Next() {
+		r := entity.ModuleRevision{}
+		err = rows.Scan(&r.Id, &r.ModuleId, &r.EditorId, &r.Action, &r.OldName, &r.NewName, &r.OldType, &r.NewType, &r.CreatedAt)
+		if err != nil {
+			return []entity.ModuleRevision{}, repo.NewDBError("module_revisions", "select", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
+
+func (rr *RevisionsRepo) InsertCardRevision(r entity.CardRevision) error {
+	result, err := rr.psql.Exec("INSERT INTO card_revisions(card_id, editor, action, "+
+		"before_term_lang, before_term_text, before_def_lang, before_def_text, "+
+		"after_term_lang, after_term_text, after_def_lang, after_def_text) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)", r.CardId, r.EditorId, r.Action,
+		r.Before.Term.Lang, r.Before.Term.Text, r.Before.Definition.Lang, r.Before.Definition.Text,
+		r.After.Term.Lang, r.After.Term.Text, r.After.Definition.Lang, r.After.Definition.Text)
+	if err != nil {
+		return repo.NewDBError("card_revisions", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
+
+func (rr *RevisionsRepo) InsertModuleRevision(r entity.ModuleRevision) error {
+	result, err := rr.psql.Exec("INSERT INTO module_revisions(module_id, editor, action, old_name, new_name, old_type, new_type) "+
+		"VALUES($1, $2, $3, $4, $5, $6, $7)", r.ModuleId, r.EditorId, r.Action, r.OldName, r.NewName, r.OldType, r.NewType)
+	if err != nil {
+		return repo.NewDBError("module_revisions", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
@@ -16,8 +16,8 @@ func NewSelectedRepo(psql repo.PSQL) *SelectedRepo {
 }
 
 func (sr *SelectedRepo) GetAllSelectedModulesByUser(userId int) ([]entity.Module, error) {
-	rows, err := sr.psql.Query("SELECT modules.* FROM selected_modules INNER JOIN modules ON selected_modules.module_id = modules.id "+
-		"WHERE user_id = $1", userId)
+	rows, err := sr.psql.Query("SELECT "+modulesColumns+" FROM selected_modules INNER JOIN modules ON selected_modules.module_id = modules.id "+
+		"WHERE user_id = $1 AND modules.deleted_at IS NULL", userId)
 	if err != nil {
 		return []entity.Module{}, repo.NewDBError("selected_modules", "select", err)
 	}
@@ -25,7 +25,7 @@ func (sr *SelectedRepo) GetAllSelectedModulesByUser(userId int) ([]entity.Module
 	modules := []entity.Module{}
 	for rows.Next() {
 		m := entity.Module{}
-		if err := rows.Scan(&m.Id, &m.Name, &m.OwnerId, &m.Type); err != nil {
+		if err := scanModule(rows, &m); err != nil {
 			return []entity.Module{}, repo.NewDBError("selected_modules", "select", err)
 		}
 		modules = append(modules, m)
@@ -35,8 +35,8 @@ func (sr *SelectedRepo) GetAllSelectedModulesByUser(userId int) ([]entity.Module
 }
 
 func (sr *SelectedRepo) GetAllSelectedCategoriesByUser(userId int) ([]entity.Category, error) {
-	rows, err := sr.psql.Query("SELECT categories.* FROM selected_categories INNER JOIN categories ON selected_categories.category_id = categories.id "+
-		"WHERE user_id = $1", userId)
+	rows, err := sr.psql.Query("SELECT "+categoriesColumns+" FROM selected_categories INNER JOIN categories ON selected_categories.category_id = categories.id "+
+		"WHERE user_id = $1 AND categories.deleted_at IS NULL", userId)
 	if err != nil {
 		return []entity.Category{}, repo.NewDBError("selected_categories", "select", err)
 	}
@@ -44,7 +44,7 @@ func (sr *SelectedRepo) GetAllSelectedCategoriesByUser(userId int) ([]entity.Cat
 	categories := []entity.Category{}
 	for rows.Next() {
 		c := entity.Category{}
-		if err := rows.Scan(&c.Id, &c.Name, &c.OwnerId, &c.Type); err != nil {
+		if err := scanCategory(rows, &c); err != nil {
 			return []entity.Category{}, repo.NewDBError("selected_categories", "select", err)
 		}
 		categories = append(categories, c)
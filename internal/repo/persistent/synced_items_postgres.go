@@ -0,0 +1,53 @@
+package persistent
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+)
+
+type SyncedItemsRepo struct {
+	psql repo.PSQL
+}
+
+func NewSyncedItemsRepo(psql repo.PSQL) *SyncedItemsRepo {
+	return &SyncedItemsRepo{psql: psql}
+}
+
+func (sr *SyncedItemsRepo) GetSyncedItem(ownerId int, clientId string) (entity.SyncedItem, error) {
+	row := sr.psql.QueryRow("SELECT owner, client_id, kind, client_time, response FROM synced_items "+
+		"WHERE owner = $1 AND client_id = $2", ownerId, clientId)
+
+	item := entity.SyncedItem{}
+	var response []byte
+	err := row.Scan(&item.Owner, &item.ClientId, &item.Kind, &item.ClientTime, &response)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.SyncedItem{}, repo.NoSuchRecordToSelect
+		}
+		return entity.SyncedItem{}, repo.NewDBError("synced_items", "select", err)
+	}
+
+	if err = json.Unmarshal(response, &item.Response); err != nil {
+		return entity.SyncedItem{}, repo.NewDBError("synced_items", "select", err)
+	}
+	return item, nil
+}
+
+func (sr *SyncedItemsRepo) InsertSyncedItem(item entity.SyncedItem) error {
+	response, err := json.Marshal(item.Response)
+	if err != nil {
+		return repo.NewDBError("synced_items", "insert", err)
+	}
+
+	result, err := sr.psql.Exec("INSERT INTO synced_items(owner, client_id, kind, client_time, response) "+
+		"VALUES($1, $2, $3, $4, $5)", item.Owner, item.ClientId, item.Kind, item.ClientTime, response)
+	if err != nil {
+		return repo.NewDBError("synced_items", "insert", err)
+	} else if count, _ := result.RowsAffected(); count == 0 {
+		return repo.InsertRecordError
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package persistent
+
+import (
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type TagsRepo struct {
+	psql repo.PSQL
+}
+
+func NewTagsRepo(psql repo.PSQL) *TagsRepo {
+	return &TagsRepo{psql: psql}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GetTagsByPrefix подсказывает теги модулей и карточек, которые видны пользователю
+func (tr *TagsRepo) GetTagsByPrefix(prefix string, userId, limit int) ([]entity.TagCount, error) {
+	return tr.getTags("SELECT tag, COUNT(*) AS count FROM ("+
+		"SELECT module_tags.tag FROM module_tags INNER JOIN modules ON modules.id = module_tags.module_id "+
+		"WHERE modules.deleted_at IS NULL AND (modules.type = 0 OR modules.owner_id = $2) "+
+		"UNION ALL "+
+		"SELECT card_tags.tag FROM card_tags INNER JOIN cards ON cards.id = card_tags.card_id "+
+		"INNER JOIN modules ON modules.id = cards.module_id "+
+		"WHERE cards.deleted_at IS NULL AND modules.deleted_at IS NULL AND (modules.type = 0 OR modules.owner_id = $2)"+
+		") AS visible_tags "+
+		"WHERE tag LIKE $1 "+
+		"GROUP BY tag ORDER BY count DESC, tag "+
+		"LIMIT $3", likeEscaper.Replace(prefix)+"%", userId, limit)
+}
+
+// GetPopularTags считает публичные модули с каждым тегом
+func (tr *TagsRepo) GetPopularTags(limit, offset int) ([]entity.TagCount, error) {
+	return tr.getTags("SELECT module_tags.tag, COUNT(*) AS count "+
+		"FROM module_tags INNER JOIN modules ON modules.id = module_tags.module_id "+
+		"WHERE modules.type = 0 AND modules.deleted_at IS NULL "+
+		"GROUP BY module_tags.tag ORDER BY count DESC, module_tags.tag "+
+		"LIMIT $1 OFFSET $2", limit, offset)
+}
+
+func (tr *TagsRepo) getTags(query string, args ...any) ([]entity.TagCount, error) {
+	rows, err := tr.psql.Query(query, args...)
+	if err != nil {
+		return []entity.TagCount{}, repo.NewDBError("module_tags", "select", err)
+	}
+	defer rows.Close()
+
+	tags := []entity.TagCount{}
+	for rows.Next() {
+		t := entity.TagCount{}
+		if err = rows.Scan(&t.Tag, &t.Count); err != nil {
+			return []entity.TagCount{}, repo.NewDBError("module_tags", "select", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// SetModuleTags заменяет все теги модуля
+func (tr *TagsRepo) SetModuleTags(moduleId int, tags []string) error {
+	_, err := tr.psql.Exec("DELETE FROM module_tags WHERE module_id = $1", moduleId)
+	if err != nil {
+		return repo.NewDBError("module_tags", "delete", err)
+	}
+
+	_, err = tr.psql.Exec("INSERT INTO module_tags(module_id, tag) "+
+		"SELECT $1, unnest($2::character varying[])", moduleId, pq.Array(tags))
+	if err != nil {
+		return repo.NewDBError("module_tags", "insert", err)
+	}
+	return nil
+}
+
+// SetCardTags заменяет все теги карточки
+func (tr *TagsRepo) SetCardTags(cardId int, tags []string) error {
+	_, err := tr.psql.Exec("DELETE FROM card_tags WHERE card_id = $1", cardId)
+	if err != nil {
+		return repo.NewDBError("card_tags", "delete", err)
+	}
+
+	_, err = tr.psql.Exec("INSERT INTO card_tags(card_id, tag) "+
+		"SELECT $1, unnest($2::character varying[])", cardId, pq.Array(tags))
+	if err != nil {
+		return repo.NewDBError("card_tags", "insert", err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package persistent
+
+import "interactive_learning/internal/repo"
+
+// selectIds читает один столбец с id, например строки корзины к очистке
+func selectIds(psql repo.PSQL, table, query string, args ...any) ([]int, error) {
+	rows, err := psql.Query(query, args...)
+	if err != nil {
+		return []int{}, repo.NewDBError(table, "select", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return []int{}, repo.NewDBError(table, "select", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
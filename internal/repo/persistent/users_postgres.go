@@ -35,7 +35,7 @@ func (u *UsersRepo) GetUsersWithSimilarName(name string, limit, offset int) ([]e
 }
 
 func (u *UsersRepo) GetUserByLogin(login string) (entity.User, error) {
-	row := u.psql.QueryRow("select * from users where login = $1", login)
+	row := u.psql.QueryRow("select id, login, name, password_hash from users where login = $1", login)
 
 	user := entity.User{}
 	err := row.Scan(&user.Id, &user.Login, &user.Name, &user.PasswordHash)
@@ -64,6 +64,16 @@ func (u *UsersRepo) GetUserInfoById(userId int) (entity.User, error) {
 	return user, nil
 }
 
+func (u *UsersRepo) IsAdmin(userId int) (bool, error) {
+	row := u.psql.QueryRow("select exists (select 1 from users where id = $1 and is_admin)", userId)
+
+	var isAdmin bool
+	if err := row.Scan(&isAdmin); err != nil {
+		return false, repo.NewDBError("users", "select", err)
+	}
+	return isAdmin, nil
+}
+
 func (u *UsersRepo) IsContainsLogin(login string) (bool, error) {
 	row := u.psql.QueryRow("select count(*) from users where login = $1", login)
 
@@ -93,3 +103,14 @@ func (u *UsersRepo) InsertUser(user entity.User) error {
 	}
 	return nil
 }
+
+func (u *UsersRepo) SetShareActivity(userId int, share bool) error {
+	result, err := u.psql.Exec("update users set share_activity = $2 where id = $1", userId, share)
+	if err != nil {
+		return repo.NewDBError("users", "update", err)
+	}
+	if count, _ := result.RowsAffected(); count == 0 {
+		return repo.NoSuchRecordToUpdate
+	}
+	return nil
+}
@@ -20,6 +20,19 @@ type UnitOfWorkImpl struct {
 	modulesResultsRepoWrite         repo.ModulesResultsRepoWrite
 	categoryModulesResultsRepoWrite repo.CategoryModulesResultsRepoWrite
 	selectedRepoWrite               repo.SelectedRepoWrite
+	idempotencyRepoWrite            repo.IdempotencyRepoWrite
+	syncedItemsRepoWrite            repo.SyncedItemsRepoWrite
+	attachmentsRepoWrite            repo.AttachmentsRepoWrite
+	revisionsRepoWrite              repo.RevisionsRepoWrite
+	tagsRepoWrite                   repo.TagsRepoWrite
+	grantsRepoWrite                 repo.GrantsRepoWrite
+	groupsRepoWrite                 repo.GroupsRepoWrite
+	assignmentsRepoWrite            repo.AssignmentsRepoWrite
+	followsRepoWrite                repo.FollowsRepoWrite
+	ratingsRepoWrite                repo.RatingsRepoWrite
+	commentsRepoWrite               repo.CommentsRepoWrite
+	moderationRepoWrite             repo.ModerationRepoWrite
+	notificationsRepoWrite          repo.NotificationsRepoWrite
 
 	userRepoRead                   repo.UsersRepoRead
 	cardRepoRead                   repo.CardRepoRead
@@ -31,6 +44,19 @@ type UnitOfWorkImpl struct {
 	modulesResultsRepoRead         repo.ModulesResultsRepoRead
 	categoryModulesResultsRepoRead repo.CategoryModulesResultsRepoRead
 	selectedRepoRead               repo.SelectedRepoRead
+	idempotencyRepoRead            repo.IdempotencyRepoRead
+	syncedItemsRepoRead            repo.SyncedItemsRepoRead
+	attachmentsRepoRead            repo.AttachmentsRepoRead
+	revisionsRepoRead              repo.RevisionsRepoRead
+	tagsRepoRead                   repo.TagsRepoRead
+	grantsRepoRead                 repo.GrantsRepoRead
+	groupsRepoRead                 repo.GroupsRepoRead
+	assignmentsRepoRead            repo.AssignmentsRepoRead
+	followsRepoRead                repo.FollowsRepoRead
+	ratingsRepoRead                repo.RatingsRepoRead
+	commentsRepoRead               repo.CommentsRepoRead
+	moderationRepoRead             repo.ModerationRepoRead
+	notificationsRepoRead          repo.NotificationsRepoRead
 }
 
 func NewUnitOfWork(db *sql.DB) *UnitOfWorkImpl {
@@ -56,6 +82,19 @@ func (uow *UnitOfWorkImpl) Begin() error {
 	modulesResultsRepo := persistent.NewModulesResultsRepo(tx)
 	categoryModulesResultsRepo := persistent.NewCategoryModulesResultsRepo(tx)
 	selectedRepo := persistent.NewSelectedRepo(tx)
+	idempotencyRepo := persistent.NewIdempotencyRepo(tx)
+	syncedItemsRepo := persistent.NewSyncedItemsRepo(tx)
+	attachmentsRepo := persistent.NewAttachmentsRepo(tx)
+	revisionsRepo := persistent.NewRevisionsRepo(tx)
+	tagsRepo := persistent.NewTagsRepo(tx)
+	grantsRepo := persistent.NewGrantsRepo(tx)
+	groupsRepo := persistent.NewGroupsRepo(tx)
+	assignmentsRepo := persistent.NewAssignmentsRepo(tx)
+	followsRepo := persistent.NewFollowsRepo(tx)
+	ratingsRepo := persistent.NewRatingsRepo(tx)
+	commentsRepo := persistent.NewCommentsRepo(tx)
+	moderationRepo := persistent.NewModerationRepo(tx)
+	notificationsRepo := persistent.NewNotificationsRepo(tx)
 
 	uow.userRepoRead = userRepo
 	uow.userRepoWrite = userRepo
@@ -77,6 +116,32 @@ func (uow *UnitOfWorkImpl) Begin() error {
 	uow.categoryModulesResultsRepoWrite = categoryModulesResultsRepo
 	uow.selectedRepoRead = selectedRepo
 	uow.selectedRepoWrite = selectedRepo
+	uow.idempotencyRepoRead = idempotencyRepo
+	uow.idempotencyRepoWrite = idempotencyRepo
+	uow.syncedItemsRepoRead = syncedItemsRepo
+	uow.syncedItemsRepoWrite = syncedItemsRepo
+	uow.attachmentsRepoRead = attachmentsRepo
+	uow.attachmentsRepoWrite = attachmentsRepo
+	uow.revisionsRepoRead = revisionsRepo
+	uow.revisionsRepoWrite = revisionsRepo
+	uow.tagsRepoRead = tagsRepo
+	uow.tagsRepoWrite = tagsRepo
+	uow.grantsRepoRead = grantsRepo
+	uow.grantsRepoWrite = grantsRepo
+	uow.groupsRepoRead = groupsRepo
+	uow.groupsRepoWrite = groupsRepo
+	uow.assignmentsRepoRead = assignmentsRepo
+	uow.assignmentsRepoWrite = assignmentsRepo
+	uow.followsRepoRead = followsRepo
+	uow.followsRepoWrite = followsRepo
+	uow.ratingsRepoRead = ratingsRepo
+	uow.ratingsRepoWrite = ratingsRepo
+	uow.commentsRepoRead = commentsRepo
+	uow.commentsRepoWrite = commentsRepo
+	uow.moderationRepoRead = moderationRepo
+	uow.moderationRepoWrite = moderationRepo
+	uow.notificationsRepoRead = notificationsRepo
+	uow.notificationsRepoWrite = notificationsRepo
 
 	return nil
 }
@@ -142,6 +207,58 @@ func (uow *UnitOfWorkImpl) GetSelectedRepoWriter() repo.SelectedRepoWrite {
 	return uow.selectedRepoWrite
 }
 
+func (uow *UnitOfWorkImpl) GetIdempotencyRepoWriter() repo.IdempotencyRepoWrite {
+	return uow.idempotencyRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetSyncedItemsRepoWriter() repo.SyncedItemsRepoWrite {
+	return uow.syncedItemsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetAttachmentsRepoWriter() repo.AttachmentsRepoWrite {
+	return uow.attachmentsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetRevisionsRepoWriter() repo.RevisionsRepoWrite {
+	return uow.revisionsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetTagsRepoWriter() repo.TagsRepoWrite {
+	return uow.tagsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetGrantsRepoWriter() repo.GrantsRepoWrite {
+	return uow.grantsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetGroupsRepoWriter() repo.GroupsRepoWrite {
+	return uow.groupsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetAssignmentsRepoWriter() repo.AssignmentsRepoWrite {
+	return uow.assignmentsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetFollowsRepoWriter() repo.FollowsRepoWrite {
+	return uow.followsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetRatingsRepoWriter() repo.RatingsRepoWrite {
+	return uow.ratingsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetCommentsRepoWriter() repo.CommentsRepoWrite {
+	return uow.commentsRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetModerationRepoWriter() repo.ModerationRepoWrite {
+	return uow.moderationRepoWrite
+}
+
+func (uow *UnitOfWorkImpl) GetNotificationsRepoWriter() repo.NotificationsRepoWrite {
+	return uow.notificationsRepoWrite
+}
+
 func (uow *UnitOfWorkImpl) GetUsersRepoReader() repo.UsersRepoRead {
 	return uow.userRepoRead
 }
@@ -181,3 +298,55 @@ func (uow *UnitOfWorkImpl) GetCategoryModulesResultsRepoReader() repo.CategoryMo
 func (uow *UnitOfWorkImpl) GetSelectedRepoReader() repo.SelectedRepoRead {
 	return uow.selectedRepoRead
 }
+
+func (uow *UnitOfWorkImpl) GetIdempotencyRepoReader() repo.IdempotencyRepoRead {
+	return uow.idempotencyRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetSyncedItemsRepoReader() repo.SyncedItemsRepoRead {
+	return uow.syncedItemsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetAttachmentsRepoReader() repo.AttachmentsRepoRead {
+	return uow.attachmentsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetRevisionsRepoReader() repo.RevisionsRepoRead {
+	return uow.revisionsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetTagsRepoReader() repo.TagsRepoRead {
+	return uow.tagsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetGrantsRepoReader() repo.GrantsRepoRead {
+	return uow.grantsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetGroupsRepoReader() repo.GroupsRepoRead {
+	return uow.groupsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetAssignmentsRepoReader() repo.AssignmentsRepoRead {
+	return uow.assignmentsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetFollowsRepoReader() repo.FollowsRepoRead {
+	return uow.followsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetRatingsRepoReader() repo.RatingsRepoRead {
+	return uow.ratingsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetCommentsRepoReader() repo.CommentsRepoRead {
+	return uow.commentsRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetModerationRepoReader() repo.ModerationRepoRead {
+	return uow.moderationRepoRead
+}
+
+func (uow *UnitOfWorkImpl) GetNotificationsRepoReader() repo.NotificationsRepoRead {
+	return uow.notificationsRepoRead
+}
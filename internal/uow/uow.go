@@ -18,6 +18,19 @@ type UnitOfWork interface {
 	GetModulesResultsRepoWriter() repo.ModulesResultsRepoWrite
 	GetCategoryModulesResultsRepoWriter() repo.CategoryModulesResultsRepoWrite
 	GetSelectedRepoWriter() repo.SelectedRepoWrite
+	GetIdempotencyRepoWriter() repo.IdempotencyRepoWrite
+	GetSyncedItemsRepoWriter() repo.SyncedItemsRepoWrite
+	GetAttachmentsRepoWriter() repo.AttachmentsRepoWrite
+	GetRevisionsRepoWriter() repo.RevisionsRepoWrite
+	GetTagsRepoWriter() repo.TagsRepoWrite
+	GetGrantsRepoWriter() repo.GrantsRepoWrite
+	GetGroupsRepoWriter() repo.GroupsRepoWrite
+	GetAssignmentsRepoWriter() repo.AssignmentsRepoWrite
+	GetFollowsRepoWriter() repo.FollowsRepoWrite
+	GetRatingsRepoWriter() repo.RatingsRepoWrite
+	GetCommentsRepoWriter() repo.CommentsRepoWrite
+	GetModerationRepoWriter() repo.ModerationRepoWrite
+	GetNotificationsRepoWriter() repo.NotificationsRepoWrite
 
 	GetUsersRepoReader() repo.UsersRepoRead
 	GetCardRepoReader() repo.CardRepoRead
@@ -29,4 +42,17 @@ type UnitOfWork interface {
 	GetModulesResultsRepoReader() repo.ModulesResultsRepoRead
 	GetCategoryModulesResultsRepoReader() repo.CategoryModulesResultsRepoRead
 	GetSelectedRepoReader() repo.SelectedRepoRead
+	GetIdempotencyRepoReader() repo.IdempotencyRepoRead
+	GetSyncedItemsRepoReader() repo.SyncedItemsRepoRead
+	GetAttachmentsRepoReader() repo.AttachmentsRepoRead
+	GetRevisionsRepoReader() repo.RevisionsRepoRead
+	GetTagsRepoReader() repo.TagsRepoRead
+	GetGrantsRepoReader() repo.GrantsRepoRead
+	GetGroupsRepoReader() repo.GroupsRepoRead
+	GetAssignmentsRepoReader() repo.AssignmentsRepoRead
+	GetFollowsRepoReader() repo.FollowsRepoRead
+	GetRatingsRepoReader() repo.RatingsRepoRead
+	GetCommentsRepoReader() repo.CommentsRepoRead
+	GetModerationRepoReader() repo.ModerationRepoRead
+	GetNotificationsRepoReader() repo.NotificationsRepoRead
 }
@@ -4,6 +4,7 @@ import (
 	"interactive_learning/internal/entity"
 	httputils "interactive_learning/internal/http_utils"
 	"interactive_learning/internal/utils/tokengenerator"
+	"io"
 )
 
 type Tokens interface {
@@ -22,54 +23,73 @@ type Users interface {
 
 type Cards interface {
 	GetCardsByModule(moduleId, userId int) ([]entity.Card, error)
-	GetCardById(cardId int) (entity.Card, error)
-	InsertCard(card entity.Card) (int, error)
-	InsertCards(cards entity.CardsToAdd) ([]int, error)
-	UpdateCard(userId int, card entity.Card) error
+	GetCardById(cardId, userId int) (entity.Card, error)
+	InsertCard(userId int, card entity.Card) (int, error)
+	InsertCards(userId int, cards entity.CardsToAdd) ([]int, error)
+	ReorderCards(userId, moduleId int, cardsIds []int) error
+	UpdateCard(userId, version int, card entity.Card) (int, error)
 	DeleteCard(userId int, cardId int) error
+	AddCardAttachment(userId, cardId int, side string, file io.Reader, size int64) (entity.Attachment, error)
+	GetCardAttachment(userId, attachmentId int) (entity.Attachment, io.ReadCloser, error)
+	DeleteCardAttachment(userId, attachmentId int) error
+	GetCardHistory(userId, cardId int) ([]entity.CardRevision, error)
+	RevertCard(userId, cardId, revisionId int) (entity.Card, error)
+	SetCardTags(userId, cardId int, tags []string) ([]string, error)
 }
 
 type Modules interface {
-	GetModulesWithSimilarName(name string, limit, offset, userId int) ([]entity.Module, error)
+	GetModulesWithSimilarName(name, tag string, limit, offset, userId int) ([]entity.Module, error)
 	GetModulesByUser(ownerId int, withCards bool, userId int) ([]entity.Module, error)
 	GetModuleById(moduleId, userId int) (entity.Module, error)
 	GetModulesByIds(modulesIds []int, isFull bool, userId int) ([]entity.Module, error)
 	GetModuleOwnerId(moduleId int) (int, error)
-	GetPopularModules(limit, offset int) ([]entity.PopularModule, error)
+	GetPopularModules(limit, offset int, sortBy string) ([]entity.PopularModule, error)
 	InsertModule(module entity.ModuleToCreate) (int, []int, error)
-	RenameModule(userId, moduleId int, newName string) error
-	UpdateModuleType(moduleId, newType, userId int) error
+	ImportModule(userId int, req httputils.ImportModuleReq, file io.Reader) (entity.ModuleImport, error)
+	ForkModule(userId, moduleId int) (int, []int, error)
+	RenameModule(userId, moduleId, version int, newName string) (int, error)
+	UpdateModuleInfo(userId, moduleId, version int, info entity.Info) (int, error)
+	UpdateModuleType(moduleId, newType, userId, version int) (int, error)
 	DeleteModule(userId int, moduleId int) error
+	GetModuleHistory(userId, moduleId int) ([]entity.ModuleRevision, error)
+	SetModuleTags(userId, moduleId int, tags []string) ([]string, error)
+	GetModuleShareSlug(userId, moduleId int) (string, error)
+	RotateModuleShareSlug(userId, moduleId int) (string, error)
 }
 
 type Categories interface {
 	GetCategoriesWithSimilarName(name string, limit, offset, userId int) ([]entity.Category, error)
 	GetCategoriesToUser(ownerId int, isFull bool, userId int) ([]entity.Category, error)
 	GetCategoryById(id, userId int) (entity.Category, error)
-	GetPopularCategories(limit, offset int) ([]entity.PopularCategory, error)
+	GetPopularCategories(limit, offset int, sortBy string) ([]entity.PopularCategory, error)
 	InsertCategory(category entity.CategoryToCreate) (int, error)
+	ForkCategory(userId, categoryId int) (int, []int, error)
 	RenameCategory(userId, categoryId int, newName string) error
+	UpdateCategoryInfo(userId, categoryId int, info entity.Info) error
 	UpdateCategoryType(categoryId, newType, userId int) error
 	DeleteCategory(userId, categoryId int) error
+	GetCategoryShareSlug(userId, categoryId int) (string, error)
+	RotateCategoryShareSlug(userId, categoryId int) (string, error)
 }
 
 type CategoryModules interface {
 	GetModulesToCategory(categoryId int, isFull bool, userId int) ([]entity.Module, error)
 	InsertModulesToCategory(userId, categoryId int, modulesIds []int) error
+	ReorderCategoryModules(userId, categoryId int, modulesIds []int) error
 	DeleteModuleFromCategory(userId, categoryId, moduleId int) error
 }
 
 type Results interface {
-	GetResultsByOwner(userId int) ([]entity.CategoryModulesResult, []entity.ModuleResult, error)
-	GetModuleResultById(resultId int) (entity.ModuleResult, error)
-	GetCardsResultById(resultId int) ([]entity.CardsResult, error)
-	GetResultsToModuleId(moduleId, userId int) ([]entity.ModuleResult, error)
-	GetResultsByCategoryId(categoryId, userId int) ([]entity.CategoryModulesResult, error)
-	GetCategoryResById(categoryResultsId int) (entity.CategoryModulesResult, error)
-	InsertModuleResult(result httputils.InsertModuleResultReq) (int, error)
-	InsertCategoryResult(result httputils.InsertCategoryModulesResultReq) (int, []int, error)
-	DeleteModuleResult(resultId int) error
-	DeleteCategoryResultById(categoryResultId int) error
+	GetResultsByOwner(ownerId, userId int) ([]entity.CategoryModulesResult, []entity.ModuleResult, error)
+	GetModuleResultById(resultId, userId int) (entity.ModuleResult, error)
+	GetCardsResultById(resultId, userId int) ([]entity.CardsResult, error)
+	GetResultsToModuleId(moduleId, ownerId, userId int) ([]entity.ModuleResult, error)
+	GetResultsByCategoryId(categoryId, ownerId, userId int) ([]entity.CategoryModulesResult, error)
+	GetCategoryResById(categoryResultsId, userId int) (entity.CategoryModulesResult, error)
+	InsertModuleResult(userId int, idempotencyKey string, result httputils.InsertModuleResultReq) (int, error)
+	InsertCategoryResult(userId int, idempotencyKey string, result httputils.InsertCategoryModulesResultReq) (int, []int, error)
+	DeleteModuleResult(userId, resultId int) error
+	DeleteCategoryResultById(userId, categoryResultId int) error
 }
 
 type Selected interface {
@@ -82,3 +102,98 @@ type Selected interface {
 	DeleteModuleToUser(userId, moduleId int) error
 	DeleteCategoryToUser(userId, categoryId int) error
 }
+
+type Sync interface {
+	Sync(userId int, req httputils.SyncReq) ([]entity.SyncItemStatus, error)
+}
+
+type Bundles interface {
+	ExportModule(moduleId, userId int) (entity.Bundle, error)
+	ExportCategory(categoryId, userId int) (entity.Bundle, error)
+	ImportBundle(userId int, bundle entity.Bundle) (entity.BundleImport, error)
+}
+
+type Trash interface {
+	GetTrash(userId int) (entity.Trash, error)
+	RestoreModule(userId, moduleId int) error
+	RestoreCategory(userId, categoryId int) error
+	RestoreCard(userId, cardId int) error
+}
+
+type Tags interface {
+	GetTagsByPrefix(prefix string, limit, userId int) ([]entity.TagCount, error)
+	GetPopularTags(limit, offset int) ([]entity.TagCount, error)
+}
+
+type Grants interface {
+	GetModuleGrants(userId, moduleId int) ([]entity.Grant, error)
+	ShareModule(userId, moduleId, granteeId int, role string) error
+	UnshareModule(userId, moduleId, granteeId int) error
+	GetCategoryGrants(userId, categoryId int) ([]entity.Grant, error)
+	ShareCategory(userId, categoryId, granteeId int, role string) error
+	UnshareCategory(userId, categoryId, granteeId int) error
+	GetSharedWithUser(userId int) (entity.Shared, error)
+	OpenShareLink(userId int, slug string) (entity.ShareLink, error)
+}
+
+type Groups interface {
+	CreateGroup(userId int, name string) (entity.Group, error)
+	GetUserGroups(userId int) ([]entity.Group, error)
+	GetGroup(userId, groupId int) (entity.Group, error)
+	RenameGroup(userId, groupId int, name string) error
+	DeleteGroup(userId, groupId int) error
+	RotateJoinCode(userId, groupId int) (string, error)
+	JoinGroup(userId int, code string) (entity.Group, error)
+	LeaveGroup(userId, groupId int) error
+	KickMember(userId, groupId, memberId int) error
+	GetGroupMembers(userId, groupId int) ([]entity.GroupMember, error)
+	GetGroupMaterials(userId, groupId int) (entity.GroupMaterials, error)
+	AttachModuleToGroup(userId, groupId, moduleId int) error
+	DetachModuleFromGroup(userId, groupId, moduleId int) error
+	AttachCategoryToGroup(userId, groupId, categoryId int) error
+	DetachCategoryFromGroup(userId, groupId, categoryId int) error
+	GetGroupResults(userId, groupId int) (entity.GroupResults, error)
+}
+
+type Follows interface {
+	Follow(userId, followeeId int) error
+	Unfollow(userId, followeeId int) error
+	GetFollowers(ownerId, userId int) ([]entity.User, error)
+	GetFollowing(ownerId, userId int) ([]entity.User, error)
+	GetFeed(userId int, cursor string, limit int) (entity.FeedPage, error)
+	SetShareActivity(userId int, share bool) error
+}
+
+type Assignments interface {
+	CreateAssignment(userId, groupId int, req httputils.AssignmentReq) (int, error)
+	GetGroupAssignments(userId, groupId int) ([]entity.Assignment, error)
+	DeleteAssignment(userId, assignmentId int) error
+	GetMyAssignments(userId int) ([]entity.StudentAssignment, error)
+	GetAssignmentSubmissions(userId, assignmentId int) ([]entity.Submission, error)
+	GetGradebook(userId, assignmentId int) (entity.Gradebook, error)
+}
+
+type Feedback interface {
+	GetModuleRating(userId, moduleId int) (entity.RatingSummary, error)
+	RateModule(userId, moduleId, rating int) error
+	UnrateModule(userId, moduleId int) error
+	GetCategoryRating(userId, categoryId int) (entity.RatingSummary, error)
+	RateCategory(userId, categoryId, rating int) error
+	UnrateCategory(userId, categoryId int) error
+	GetModuleComments(userId, moduleId, limit, offset int) ([]entity.Comment, error)
+	AddModuleComment(userId, moduleId int, text string) (int, error)
+	GetCategoryComments(userId, categoryId, limit, offset int) ([]entity.Comment, error)
+	AddCategoryComment(userId, categoryId int, text string) (int, error)
+	UpdateComment(userId, commentId int, text string) error
+	DeleteComment(userId, commentId int) error
+}
+
+type Moderation interface {
+	Report(userId int, targetKind string, targetId int, req httputils.ReportReq) (int, error)
+	GetReports(userId int, status string, limit, offset int) ([]entity.Report, error)
+	ResolveReport(userId, reportId int, req httputils.ModerationReq) error
+	RestoreContent(userId int, req httputils.RestoreReq) error
+	GetModerationLog(userId, limit, offset int) ([]entity.ModerationEntry, error)
+	GetNotifications(userId, limit, offset int) ([]entity.Notification, error)
+	MarkNotificationRead(userId, notificationId int) error
+}
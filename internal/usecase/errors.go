@@ -115,3 +115,67 @@ func (ae *AlreadyExistsError) Error() string {
 func (ae *AlreadyExistsError) Unwrap() error {
 	return AlreadyExistsErr
 }
+
+var InvalidDataErr = errors.New("invalid data")
+
+type InvalidDataError struct {
+	Object string
+	Err    error
+}
+
+func NewInvalidDataError(object string, err error) *InvalidDataError {
+	return &InvalidDataError{Object: object, Err: err}
+}
+
+func (ide *InvalidDataError) Error() string {
+	return fmt.Sprintf("invalid data object: %s, error: %s", ide.Object, ide.Err.Error())
+}
+
+func (ide *InvalidDataError) Unwrap() error {
+	return InvalidDataErr
+}
+
+var ConflictErr = errors.New("conflict")
+
+type ConflictError struct {
+	Object string
+	Err    error
+}
+
+func NewConflictError(object string, err error) *ConflictError {
+	return &ConflictError{Object: object, Err: err}
+}
+
+func (ce *ConflictError) Error() string {
+	return fmt.Sprintf("conflict object: %s, error: %s", ce.Object, ce.Err.Error())
+}
+
+func (ce *ConflictError) Unwrap() error {
+	return ConflictErr
+}
+
+var PreconditionFailedErr = errors.New("precondition failed")
+
+// VersionMismatchError - объект изменили после того, как клиент его прочитал.
+// Current - текущее состояние объекта, его версия в Version.
+type VersionMismatchError struct {
+	Object  string
+	Version int
+	Current any
+}
+
+func NewVersionMismatchError(object string, version int, current any) *VersionMismatchError {
+	return &VersionMismatchError{Object: object, Version: version, Current: current}
+}
+
+func (vme *VersionMismatchError) Error() string {
+	return fmt.Sprintf("error: %s was changed, current version is %d", vme.Object, vme.Version)
+}
+
+func (vme *VersionMismatchError) Unwrap() error {
+	return PreconditionFailedErr
+}
+
+func (vme *VersionMismatchError) Conflict() (string, int, any) {
+	return vme.Object, vme.Version, vme.Current
+}
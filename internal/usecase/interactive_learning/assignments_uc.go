@@ -0,0 +1,320 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"slices"
+	"time"
+)
+
+func validateAssignment(req httputils.AssignmentReq) (time.Time, error) {
+	if (req.ModuleId == nil) == (req.CategoryId == nil) {
+		return time.Time{}, usecase.NewInvalidDataError("assignment", errors.New("assignment needs either module or category"))
+	}
+	if !slices.Contains(entity.ResultTypes, req.Mode) {
+		return time.Time{}, usecase.NewInvalidDataError("assignment", errors.New("unknown mode "+req.Mode))
+	}
+	if req.MinScore != nil {
+		if !slices.Contains(entity.ScoredResultTypes, req.Mode) {
+			return time.Time{}, usecase.NewInvalidDataError("assignment", errors.New("min score is not supported for mode "+req.Mode))
+		} else if *req.MinScore < 0 || *req.MinScore > 100 {
+			return time.Time{}, usecase.NewInvalidDataError("assignment", errors.New("min score must be a percent"))
+		}
+	}
+
+	dueAt, err := time.Parse(time.DateTime, req.DueAt)
+	if err != nil {
+		return time.Time{}, usecase.NewInvalidDataError("assignment", err)
+	} else if dueAt.Before(time.Now()) {
+		return time.Time{}, usecase.NewInvalidDataError("assignment", errors.New("due date is in the past"))
+	}
+	return dueAt, nil
+}
+
+// assignmentResource загружает задание: доступ к нему определяет его группа
+func (u *UseCase) assignmentResource(assignmentId int, uow uow.UnitOfWork) (entity.Assignment, usecase.Resource, error) {
+	assignmentsRepoRead := u.assignmentsRepoRead
+	if uow != nil {
+		assignmentsRepoRead = uow.GetAssignmentsRepoReader()
+	}
+
+	assignment, err := assignmentsRepoRead.GetAssignmentById(assignmentId)
+	if err != nil {
+		return entity.Assignment{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	_, res, err := u.groupResource(assignment.GroupId, uow)
+	if err != nil {
+		return entity.Assignment{}, usecase.Resource{}, err
+	}
+	return assignment, res, nil
+}
+
+// CreateAssignment задает группе пройти модуль или категорию. Материал
+// должен быть открыт группе, иначе ученики не смогут его пройти.
+func (u *UseCase) CreateAssignment(userId, groupId int, req httputils.AssignmentReq) (int, error) {
+	dueAt, err := validateAssignment(req)
+	if err != nil {
+		return -1, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	if err = u.checkGroupEditor(userId, groupId, uow); err != nil {
+		return -1, err
+	}
+
+	if req.ModuleId != nil {
+		modules, err := uow.GetGroupsRepoReader().GetGroupModules(groupId)
+		if err != nil {
+			return -1, u.errorsMapper.DBErrorToApp(err)
+		} else if !slices.ContainsFunc(modules, func(m entity.Module) bool { return m.Id == *req.ModuleId }) {
+			return -1, usecase.NewInvalidDataError("assignment", errors.New("module is not attached to group"))
+		}
+	} else {
+		categories, err := uow.GetGroupsRepoReader().GetGroupCategories(groupId)
+		if err != nil {
+			return -1, u.errorsMapper.DBErrorToApp(err)
+		} else if !slices.ContainsFunc(categories, func(c entity.Category) bool { return c.Id == *req.CategoryId }) {
+			return -1, usecase.NewInvalidDataError("assignment", errors.New("category is not attached to group"))
+		}
+	}
+
+	err = uow.GetAssignmentsRepoWriter().InsertAssignment(entity.Assignment{
+		GroupId:    groupId,
+		ModuleId:   req.ModuleId,
+		CategoryId: req.CategoryId,
+		Mode:       req.Mode,
+		MinScore:   req.MinScore,
+		DueAt:      dueAt,
+	})
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	newId, err := uow.GetAssignmentsRepoReader().GetLastInsertedAssignmentId()
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	return newId, nil
+}
+
+func (u *UseCase) GetGroupAssignments(userId, groupId int) ([]entity.Assignment, error) {
+	_, res, err := u.groupResource(groupId, nil)
+	if err != nil {
+		return []entity.Assignment{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.Assignment{}, u.policy.Deny(res)
+	}
+
+	assignments, err := u.assignmentsRepoRead.GetGroupAssignments(groupId)
+	if err != nil {
+		return []entity.Assignment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return assignments, nil
+}
+
+func (u *UseCase) DeleteAssignment(userId, assignmentId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	_, res, err := u.assignmentResource(assignmentId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetAssignmentsRepoWriter().DeleteAssignment(assignmentId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// GetMyAssignments возвращает задания ученика во всех его группах с его прогрессом
+func (u *UseCase) GetMyAssignments(userId int) ([]entity.StudentAssignment, error) {
+	assignments, err := u.assignmentsRepoRead.GetStudentAssignments(userId)
+	if err != nil {
+		return []entity.StudentAssignment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	submissions, err := u.assignmentsRepoRead.GetUserSubmissions(userId)
+	if err != nil {
+		return []entity.StudentAssignment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	for i := range assignments {
+		assignmentSubmissions := slices.DeleteFunc(slices.Clone(submissions), func(s entity.Submission) bool {
+			return s.AssignmentId != assignments[i].Id
+		})
+		assignments[i].Status = assignmentStatus(userId, "", assignmentSubmissions)
+	}
+	return assignments, nil
+}
+
+// GetAssignmentSubmissions возвращает преподавателю все засчитанные в задание результаты
+func (u *UseCase) GetAssignmentSubmissions(userId, assignmentId int) ([]entity.Submission, error) {
+	_, res, err := u.assignmentResource(assignmentId, nil)
+	if err != nil {
+		return []entity.Submission{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return []entity.Submission{}, u.policy.Deny(res)
+	}
+
+	submissions, err := u.assignmentsRepoRead.GetSubmissions(assignmentId)
+	if err != nil {
+		return []entity.Submission{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return submissions, nil
+}
+
+// GetGradebook возвращает итог по заданию для каждого ученика группы,
+// в том числе для тех, кто к нему не приступал
+func (u *UseCase) GetGradebook(userId, assignmentId int) (entity.Gradebook, error) {
+	assignment, res, err := u.assignmentResource(assignmentId, nil)
+	if err != nil {
+		return entity.Gradebook{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return entity.Gradebook{}, u.policy.Deny(res)
+	}
+
+	members, err := u.groupsRepoRead.GetGroupMembers(assignment.GroupId)
+	if err != nil {
+		return entity.Gradebook{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	submissions, err := u.assignmentsRepoRead.GetSubmissions(assignmentId)
+	if err != nil {
+		return entity.Gradebook{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	gradebook := entity.Gradebook{Assignment: assignment, Students: []entity.AssignmentStatus{}}
+	for _, member := range members {
+		if member.Role != entity.StudentRole {
+			continue
+		}
+		studentSubmissions := slices.DeleteFunc(slices.Clone(submissions), func(s entity.Submission) bool {
+			return s.UserId != member.UserId
+		})
+		gradebook.Students = append(gradebook.Students, assignmentStatus(member.UserId, member.Login, studentSubmissions))
+	}
+	return gradebook, nil
+}
+
+// assignmentStatus подводит итог по попыткам ученика, submissions отсортированы по времени.
+// Задание выполнено первой попыткой, прошедшей порог.
+func assignmentStatus(userId int, login string, submissions []entity.Submission) entity.AssignmentStatus {
+	status := entity.AssignmentStatus{UserId: userId, Login: login, Attempts: len(submissions)}
+	for _, s := range submissions {
+		if s.Score != nil && (status.BestScore == nil || *s.Score > *status.BestScore) {
+			score := *s.Score
+			status.BestScore = &score
+		}
+		if s.Passed && !status.Completed {
+			completedAt := s.SubmittedAt
+			status.Completed, status.Late, status.CompletedAt = true, s.Late, &completedAt
+		}
+	}
+	return status
+}
+
+// resultsScore - процент правильных ответов по результатам, nil если его нельзя посчитать
+func resultsScore(results []httputils.ResultForReq) *int {
+	score, questions := 0, 0
+	for _, result := range results {
+		if result.Score == nil || result.QuestionsCount == nil {
+			return nil
+		}
+		score += *result.Score
+		questions += *result.QuestionsCount
+	}
+	if questions == 0 {
+		return nil
+	}
+	percent := score * 100 / questions
+	return &percent
+}
+
+// submitToAssignments засчитывает результат в подходящие по режиму задания.
+// Без порога задание выполняется любым результатом в нужном режиме.
+func (u *UseCase) submitToAssignments(assignments []entity.Assignment, submission entity.Submission, results []httputils.ResultForReq, uow uow.UnitOfWork) error {
+	submission.Score = resultsScore(results)
+	for _, assignment := range assignments {
+		if slices.ContainsFunc(results, func(r httputils.ResultForReq) bool { return r.Type != assignment.Mode }) {
+			continue
+		}
+		submission.AssignmentId = assignment.Id
+		submission.Passed = assignment.MinScore == nil ||
+			(submission.Score != nil && *submission.Score >= *assignment.MinScore)
+		if err := uow.GetAssignmentsRepoWriter().InsertSubmission(submission); err != nil {
+			return u.errorsMapper.DBErrorToApp(err)
+		}
+	}
+	return nil
+}
+
+// submitModuleResult связывает новый результат по модулю с заданиями групп ученика
+func (u *UseCase) submitModuleResult(result httputils.InsertModuleResultReq, resultId int, uow uow.UnitOfWork) error {
+	assignments, err := uow.GetAssignmentsRepoReader().GetStudentModuleAssignments(result.Owner, result.ModuleId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	} else if len(assignments) == 0 {
+		return nil
+	}
+	return u.submitToAssignments(assignments, entity.Submission{UserId: result.Owner, ResultId: &resultId},
+		[]httputils.ResultForReq{result.Result}, uow)
+}
+
+// submitCategoryResult связывает прохождение категории с заданиями групп ученика,
+// все модули категории должны быть пройдены в режиме задания. Прохождение части
+// модулей в задания не засчитывается.
+func (u *UseCase) submitCategoryResult(result httputils.InsertCategoryModulesResultReq, categoryResultId int, uow uow.UnitOfWork) error {
+	if len(result.Modules) == 0 {
+		return nil
+	}
+	assignments, err := uow.GetAssignmentsRepoReader().GetStudentCategoryAssignments(result.Owner, result.CategoryId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	} else if len(assignments) == 0 {
+		return nil
+	}
+
+	categoryModules, err := uow.GetCategoryModulesRepoReader().GetModulesToCategory(result.CategoryId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	categoryModulesIds := make([]int, 0, len(categoryModules))
+	for _, module := range categoryModules {
+		categoryModulesIds = append(categoryModulesIds, module.Id)
+	}
+
+	results := make([]httputils.ResultForReq, 0, len(result.Modules))
+	modulesIds := make([]int, 0, len(result.Modules))
+	for _, moduleRes := range result.Modules {
+		results = append(results, moduleRes.Result)
+		modulesIds = append(modulesIds, moduleRes.ModuleId)
+	}
+	if !isPermutation(modulesIds, categoryModulesIds) {
+		return nil
+	}
+	return u.submitToAssignments(assignments, entity.Submission{UserId: result.Owner, CategoryResultId: &categoryResultId}, results, uow)
+}
@@ -0,0 +1,110 @@
+package interactivelearning
+
+import (
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestAssignmentStatus(t *testing.T) {
+	first := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	second, third := first.Add(time.Hour), first.Add(2*time.Hour)
+
+	tests := []struct {
+		name        string
+		submissions []entity.Submission
+		want        entity.AssignmentStatus
+	}{
+		{
+			name: "no attempts",
+			want: entity.AssignmentStatus{UserId: 1, Login: "anna"},
+		},
+		{
+			name: "failed attempts",
+			submissions: []entity.Submission{
+				{Score: intPtr(40), SubmittedAt: first},
+				{Score: intPtr(60), SubmittedAt: second},
+			},
+			want: entity.AssignmentStatus{UserId: 1, Login: "anna", Attempts: 2, BestScore: intPtr(60)},
+		},
+		{
+			name: "completed by first passed attempt",
+			submissions: []entity.Submission{
+				{Score: intPtr(50), SubmittedAt: first},
+				{Score: intPtr(80), Passed: true, Late: true, SubmittedAt: second},
+				{Score: intPtr(90), Passed: true, SubmittedAt: third},
+			},
+			want: entity.AssignmentStatus{UserId: 1, Login: "anna", Attempts: 3, BestScore: intPtr(90),
+				Completed: true, Late: true, CompletedAt: &second},
+		},
+		{
+			name: "unscored attempts",
+			submissions: []entity.Submission{
+				{SubmittedAt: first},
+				{Passed: true, SubmittedAt: second},
+			},
+			want: entity.AssignmentStatus{UserId: 1, Login: "anna", Attempts: 2, Completed: true, CompletedAt: &second},
+		},
+		{
+			name: "score of unscored attempt is kept",
+			submissions: []entity.Submission{
+				{Score: intPtr(0), SubmittedAt: first},
+				{SubmittedAt: second},
+			},
+			want: entity.AssignmentStatus{UserId: 1, Login: "anna", Attempts: 2, BestScore: intPtr(0)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := assignmentStatus(1, "anna", test.submissions)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResultsScore(t *testing.T) {
+	result := func(score, questions *int) httputils.ResultForReq {
+		return httputils.ResultForReq{
+			Type:       entity.TestResult,
+			ResultMeta: entity.ResultMeta{Score: score, QuestionsCount: questions},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		results []httputils.ResultForReq
+		want    *int
+	}{
+		{"no results", nil, nil},
+		{"single module", []httputils.ResultForReq{result(intPtr(7), intPtr(10))}, intPtr(70)},
+		{"modules are summed by questions", []httputils.ResultForReq{
+			result(intPtr(1), intPtr(2)),
+			result(intPtr(8), intPtr(8)),
+		}, intPtr(90)},
+		{"percent is rounded down", []httputils.ResultForReq{result(intPtr(2), intPtr(3))}, intPtr(66)},
+		{"no questions", []httputils.ResultForReq{result(intPtr(0), intPtr(0))}, nil},
+		{"module without score", []httputils.ResultForReq{
+			result(intPtr(5), intPtr(5)),
+			result(nil, intPtr(5)),
+		}, nil},
+		{"module without questions", []httputils.ResultForReq{result(intPtr(5), nil)}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := resultsScore(test.results)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package interactivelearning
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/usecase"
+	"io"
+	"log"
+	"net/http"
+)
+
+// AddCardAttachment сохраняет изображение или аудио в хранилище и прикрепляет его к стороне карточки
+func (u *UseCase) AddCardAttachment(userId, cardId int, side string, file io.Reader, size int64) (entity.Attachment, error) {
+	if side != entity.TermSide && side != entity.DefinitionSide {
+		return entity.Attachment{}, usecase.NewInvalidDataError("attachment", errors.New("invalid side"))
+	}
+
+	// тип определяем по содержимому, заголовку клиента не доверяем
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return entity.Attachment{}, usecase.NewInvalidDataError("attachment", errors.New("empty file"))
+	}
+	head = head[:n]
+
+	contentType := detectAttachmentType(head)
+	kind, ok := entity.AttachmentContentTypes[contentType]
+	if !ok {
+		return entity.Attachment{}, usecase.NewInvalidDataError("attachment", fmt.Errorf("unsupported file type %s", contentType))
+	}
+	if (kind == entity.ImageAttachment && size > entity.MaxImageAttachmentSize) ||
+		(kind == entity.AudioAttachment && size > entity.MaxAudioAttachmentSize) {
+		return entity.Attachment{}, usecase.NewInvalidDataError("attachment", errors.New("file is too large"))
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return entity.Attachment{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.attachmentsMutex.Lock()
+	defer u.attachmentsMutex.Unlock()
+
+	_, res, err := u.cardResource(cardId, uow)
+	if err != nil {
+		return entity.Attachment{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return entity.Attachment{}, u.policy.Deny(res)
+	}
+
+	attachment := entity.Attachment{
+		CardId:      cardId,
+		OwnerId:     userId,
+		Side:        side,
+		Kind:        kind,
+		ContentType: contentType,
+		Size:        size,
+		Key:         newBlobKey(cardId),
+	}
+	if err = u.blobStore.Put(attachment.Key, io.MultiReader(bytes.NewReader(head), file), size, contentType); err != nil {
+		return entity.Attachment{}, usecase.NewInternalError(err)
+	}
+
+	// если запись не сохранится, файл станет сиротой
+	committed := false
+	defer func() {
+		if !committed {
+			u.deleteBlobs([]entity.Attachment{attachment})
+		}
+	}()
+
+	if err = uow.GetAttachmentsRepoWriter().InsertAttachment(attachment); err != nil {
+		return entity.Attachment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	if attachment.Id, err = uow.GetAttachmentsRepoReader().GetLastInsertedAttachmentId(); err != nil {
+		return entity.Attachment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	if attachment, err = uow.GetAttachmentsRepoReader().GetAttachmentById(attachment.Id); err != nil {
+		return entity.Attachment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return entity.Attachment{}, usecase.NewInternalError(err)
+	}
+	committed = true
+	return attachment, nil
+}
+
+// GetCardAttachment отдает файл, если пользователю доступен модуль карточки
+func (u *UseCase) GetCardAttachment(userId, attachmentId int) (entity.Attachment, io.ReadCloser, error) {
+	attachment, err := u.attachmentsRepoRead.GetAttachmentById(attachmentId)
+	if err != nil {
+		return entity.Attachment{}, nil, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	_, res, err := u.cardResource(attachment.CardId, nil)
+	if err != nil {
+		return entity.Attachment{}, nil, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.Attachment{}, nil, u.policy.Deny(res)
+	}
+
+	file, err := u.blobStore.Get(attachment.Key)
+	if err != nil {
+		return entity.Attachment{}, nil, u.errorsMapper.DBErrorToApp(err)
+	}
+	return attachment, file, nil
+}
+
+func (u *UseCase) DeleteCardAttachment(userId, attachmentId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.attachmentsMutex.Lock()
+	defer u.attachmentsMutex.Unlock()
+
+	attachment, err := uow.GetAttachmentsRepoReader().GetAttachmentById(attachmentId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	_, res, err := u.cardResource(attachment.CardId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetAttachmentsRepoWriter().DeleteAttachment(attachmentId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	u.deleteBlobs([]entity.Attachment{attachment})
+	return nil
+}
+
+// getCardsByModule возвращает карточки модуля вместе с вложениями
+func (u *UseCase) getCardsByModule(moduleId int) ([]entity.Card, error) {
+	cards, err := u.cardsRepoRead.GetCardsByModule(moduleId)
+	if err != nil {
+		return []entity.Card{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	attachments, err := u.attachmentsRepoRead.GetAttachmentsToModule(moduleId)
+	if err != nil {
+		return []entity.Card{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	toCard := map[int][]entity.Attachment{}
+	for _, attachment := range attachments {
+		toCard[attachment.CardId] = append(toCard[attachment.CardId], attachment)
+	}
+	for i := range cards {
+		cards[i].Attachments = toCard[cards[i].Id]
+	}
+	return cards, nil
+}
+
+// deleteBlobs удаляет файлы после коммита, ошибка хранилища не откатывает удаление записей
+func (u *UseCase) deleteBlobs(attachments []entity.Attachment) {
+	for _, attachment := range attachments {
+		if err := u.blobStore.Delete(attachment.Key); err != nil {
+			log.Printf("failed to delete blob %s: %s", attachment.Key, err)
+		}
+	}
+}
+
+func detectAttachmentType(head []byte) string {
+	contentType := http.DetectContentType(head)
+	if contentType == "application/ogg" {
+		return "audio/ogg"
+	}
+	return contentType
+}
+
+func newBlobKey(cardId int) string {
+	raw := make([]byte, 16)
+	rand.Read(raw)
+	return fmt.Sprintf("cards/%d/%s", cardId, hex.EncodeToString(raw))
+}
@@ -0,0 +1,136 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/usecase"
+	"time"
+)
+
+// ExportModule собирает выгрузку модуля, доступную пользователю
+func (u *UseCase) ExportModule(moduleId, userId int) (entity.Bundle, error) {
+	module, err := u.GetModuleById(moduleId, userId)
+	if err != nil {
+		return entity.Bundle{}, err
+	}
+
+	return entity.Bundle{
+		Manifest: newBundleManifest(entity.ModuleBundle),
+		Content: entity.BundleContent{
+			Modules: []entity.BundleModule{moduleToBundle(module)},
+		},
+	}, nil
+}
+
+// ExportCategory собирает выгрузку категории вместе с ее модулями
+func (u *UseCase) ExportCategory(categoryId, userId int) (entity.Bundle, error) {
+	category, err := u.GetCategoryById(categoryId, userId)
+	if err != nil {
+		return entity.Bundle{}, err
+	}
+
+	content := entity.BundleContent{
+		Name:    category.Name,
+		Type:    category.Visibility,
+		Modules: make([]entity.BundleModule, 0, len(category.Modules)),
+	}
+	for _, module := range category.Modules {
+		content.Modules = append(content.Modules, moduleToBundle(module))
+	}
+
+	return entity.Bundle{
+		Manifest: newBundleManifest(entity.CategoryBundle),
+		Content:  content,
+	}, nil
+}
+
+// ImportBundle создает из выгрузки новые модули (и категорию) от имени пользователя
+func (u *UseCase) ImportBundle(userId int, bundle entity.Bundle) (entity.BundleImport, error) {
+	if err := validateBundle(bundle); err != nil {
+		return entity.BundleImport{}, usecase.NewInvalidDataError("bundle", err)
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return entity.BundleImport{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	u.cardMutex.Lock()
+	u.categoryMutex.Lock()
+	defer func() {
+		u.moduleMutex.Unlock()
+		u.cardMutex.Unlock()
+		u.categoryMutex.Unlock()
+	}()
+
+	res := entity.BundleImport{ModulesIds: make([]int, 0, len(bundle.Content.Modules))}
+	for _, module := range bundle.Content.Modules {
+		id, _, err := u.insertModule(entity.ModuleToCreate{
+			Name:    module.Name,
+			Cards:   module.Cards,
+			OwnerId: userId,
+			Type:    module.Type,
+		}, uow)
+		if err != nil {
+			return entity.BundleImport{}, err
+		}
+		res.ModulesIds = append(res.ModulesIds, id)
+	}
+
+	if bundle.Manifest.Kind == entity.CategoryBundle {
+		categoryId, err := u.insertCategory(entity.CategoryToCreate{
+			Name:    bundle.Content.Name,
+			OwnerId: userId,
+			Modules: res.ModulesIds,
+			Type:    bundle.Content.Type,
+		}, uow)
+		if err != nil {
+			return entity.BundleImport{}, err
+		}
+		res.CategoryId = categoryId
+	}
+
+	if err := uow.Commit(); err != nil {
+		return entity.BundleImport{}, usecase.NewInternalError(err)
+	}
+	return res, nil
+}
+
+func newBundleManifest(kind string) entity.BundleManifest {
+	return entity.BundleManifest{
+		Format:    entity.BundleFormat,
+		Version:   entity.BundleVersion,
+		Kind:      kind,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func moduleToBundle(module entity.Module) entity.BundleModule {
+	return entity.BundleModule{Name: module.Name, Type: module.Type, Cards: cardsToAdd(module.Cards)}
+}
+
+func validateBundle(bundle entity.Bundle) error {
+	switch bundle.Manifest.Kind {
+	case entity.ModuleBundle:
+		if len(bundle.Content.Modules) != 1 {
+			return errors.New("module bundle must contain exactly one module")
+		}
+	case entity.CategoryBundle:
+		if bundle.Content.Name == "" {
+			return errors.New("empty category name")
+		} else if bundle.Content.Type < entity.PublicCategory || bundle.Content.Type > entity.UnlistedCategory {
+			return errors.New("invalid category type")
+		}
+	default:
+		return errors.New("unknown bundle kind")
+	}
+
+	for _, module := range bundle.Content.Modules {
+		if module.Name == "" {
+			return errors.New("empty module name")
+		}
+	}
+	return nil
+}
@@ -2,51 +2,41 @@ package interactivelearning
 
 import (
 	"errors"
+	"fmt"
 	"interactive_learning/internal/entity"
 	"interactive_learning/internal/uow"
 	"interactive_learning/internal/usecase"
+	"slices"
 )
 
-func (u *UseCase) getCardOwnerId(cardId int, uow uow.UnitOfWork) (int, error) {
-	cardsRepoRead := u.cardsRepoRead
-	moduleRepoRead := u.moduleRepoRead
-	if uow != nil {
-		cardsRepoRead = uow.GetCardRepoReader()
-		moduleRepoRead = uow.GetModuleRepoReader()
-	}
-
-	parentModule, err := cardsRepoRead.GetParentModuleId(cardId)
+func (u *UseCase) GetCardById(cardId, userId int) (entity.Card, error) {
+	_, res, err := u.cardResource(cardId, nil)
 	if err != nil {
-		return -1, u.errorsMapper.DBErrorToApp(err)
+		return entity.Card{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.Card{}, u.policy.Deny(res)
 	}
-	moduleOwner, err := moduleRepoRead.GetModuleOwnerId(parentModule)
-	if err != nil {
-		return -1, u.errorsMapper.DBErrorToApp(err)
-	}
-	return moduleOwner, nil
-}
 
-func (u *UseCase) GetCardById(cardId int) (entity.Card, error) {
 	card, err := u.cardsRepoRead.GetCardById(cardId)
 	if err != nil {
 		return entity.Card{}, u.errorsMapper.DBErrorToApp(err)
 	}
+	card.Attachments, err = u.attachmentsRepoRead.GetAttachmentsToCard(cardId)
+	if err != nil {
+		return entity.Card{}, u.errorsMapper.DBErrorToApp(err)
+	}
 	return card, nil
 }
 
 func (u *UseCase) GetCardsByModule(moduleId int, userId int) ([]entity.Card, error) {
-	_, err := u.GetModuleById(moduleId, userId)
+	module, err := u.GetModuleById(moduleId, userId)
 	if err != nil { // нужно для проверки доступен ли модуль
 		return []entity.Card{}, err
 	}
-	cards, err := u.cardsRepoRead.GetCardsByModule(moduleId)
-	if err != nil {
-		return []entity.Card{}, u.errorsMapper.DBErrorToApp(err)
-	}
-	return cards, nil
+	return module.Cards, nil
 }
 
-func (u *UseCase) InsertCard(card entity.Card) (int, error) {
+func (u *UseCase) InsertCard(userId int, card entity.Card) (int, error) {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return -1, usecase.NewInternalError(err)
@@ -56,7 +46,14 @@ func (u *UseCase) InsertCard(card entity.Card) (int, error) {
 	u.cardMutex.Lock()
 	defer u.cardMutex.Unlock()
 
-	err := uow.GetCardRepoWriter().InsertCard(card)
+	_, res, err := u.moduleResource(card.ParentModule, uow)
+	if err != nil {
+		return -1, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return -1, u.policy.Deny(res)
+	}
+
+	err = uow.GetCardRepoWriter().InsertCard(card)
 	if err != nil {
 		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
@@ -65,6 +62,9 @@ func (u *UseCase) InsertCard(card entity.Card) (int, error) {
 	if err != nil {
 		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
+	if err = uow.GetModuleRepoWriter().TouchModule(card.ParentModule); err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
 
 	if err = uow.Commit(); err != nil {
 		return -1, usecase.NewInternalError(err)
@@ -73,7 +73,7 @@ func (u *UseCase) InsertCard(card entity.Card) (int, error) {
 	return insertedId, nil
 }
 
-func (u *UseCase) InsertCards(cards entity.CardsToAdd) ([]int, error) {
+func (u *UseCase) InsertCards(userId int, cards entity.CardsToAdd) ([]int, error) {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return []int{}, usecase.NewInternalError(err)
@@ -83,30 +83,92 @@ func (u *UseCase) InsertCards(cards entity.CardsToAdd) ([]int, error) {
 	u.cardMutex.Lock()
 	defer u.cardMutex.Unlock()
 
-	var ids []int
-	var err error
-	var curId int
+	_, res, err := u.moduleResource(cards.ParentModule, uow)
+	if err != nil {
+		return []int{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return []int{}, u.policy.Deny(res)
+	}
+
+	ids, err := u.insertCards(cards, uow)
+	if err != nil {
+		return []int{}, err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return []int{}, usecase.NewInternalError(err)
+	}
+
+	return ids, nil
+}
 
+func (u *UseCase) insertCards(cards entity.CardsToAdd, uow uow.UnitOfWork) ([]int, error) {
+	ids := []int{}
 	for _, card := range cards.Cards {
-		err = uow.GetCardRepoWriter().InsertCard(entity.Card{ParentModule: cards.ParentModule, Term: card.Term, Definition: card.Definition})
+		err := uow.GetCardRepoWriter().InsertCard(entity.Card{ParentModule: cards.ParentModule, Term: card.Term, Definition: card.Definition})
 		if err != nil {
 			return []int{}, u.errorsMapper.DBErrorToApp(err)
 		}
-		curId, err = uow.GetCardRepoReader().GetLastInsertedCardId()
+		curId, err := uow.GetCardRepoReader().GetLastInsertedCardId()
 		if err != nil {
 			return []int{}, u.errorsMapper.DBErrorToApp(err)
 		}
 		ids = append(ids, curId)
 	}
-
-	if err = uow.Commit(); err != nil {
-		return []int{}, usecase.NewInternalError(err)
+	if len(ids) == 0 {
+		return ids, nil
 	}
 
+	if cards.Position != nil {
+		if err := u.moveInsertedCards(cards.ParentModule, *cards.Position, ids, uow); err != nil {
+			return []int{}, err
+		}
+	}
+	if err := uow.GetModuleRepoWriter().TouchModule(cards.ParentModule); err != nil {
+		return []int{}, u.errorsMapper.DBErrorToApp(err)
+	}
 	return ids, nil
 }
 
-func (u *UseCase) UpdateCard(userId int, card entity.Card) error {
+// moveInsertedCards переносит только что добавленные в конец модуля карточки на позицию position
+func (u *UseCase) moveInsertedCards(moduleId, position int, insertedIds []int, uow uow.UnitOfWork) error {
+	cards, err := uow.GetCardRepoReader().GetCardsByModule(moduleId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	currentIds := make([]int, 0, len(cards))
+	for _, card := range cards {
+		currentIds = append(currentIds, card.Id)
+	}
+	order, err := insertAtPosition(currentIds, insertedIds, position)
+	if err != nil {
+		return err
+	}
+
+	if err = uow.GetCardRepoWriter().ReorderCards(moduleId, order); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	return nil
+}
+
+// insertAtPosition возвращает порядок карточек, в котором добавленные карточки стоят
+// начиная с position, а карточки с этой позиции и дальше сдвигаются за них
+func insertAtPosition(currentIds, insertedIds []int, position int) ([]int, error) {
+	order := make([]int, 0, len(currentIds))
+	for _, id := range currentIds {
+		if !slices.Contains(insertedIds, id) {
+			order = append(order, id)
+		}
+	}
+	if position < 0 || position > len(order) {
+		return nil, usecase.NewInvalidDataError("cards", fmt.Errorf("position must be from 0 to %d", len(order)))
+	}
+	return slices.Insert(order, position, insertedIds...), nil
+}
+
+// ReorderCards задает порядок всех карточек модуля, cardsIds - перестановка его карточек
+func (u *UseCase) ReorderCards(userId, moduleId int, cardsIds []int) error {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return usecase.NewInternalError(err)
@@ -116,17 +178,31 @@ func (u *UseCase) UpdateCard(userId int, card entity.Card) error {
 	u.cardMutex.Lock()
 	defer u.cardMutex.Unlock()
 
-	ownerId, err := u.getCardOwnerId(card.Id, uow)
+	_, res, err := u.moduleResource(moduleId, uow)
 	if err != nil {
 		return err
-	} else if ownerId != userId {
-		return usecase.NewNotAvailableError("card", card.Id)
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
 	}
 
-	err = uow.GetCardRepoWriter().UpdateCard(card)
+	cards, err := uow.GetCardRepoReader().GetCardsByModule(moduleId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
+	currentIds := make([]int, 0, len(cards))
+	for _, card := range cards {
+		currentIds = append(currentIds, card.Id)
+	}
+	if !isPermutation(cardsIds, currentIds) {
+		return usecase.NewInvalidDataError("cards", errors.New("order must contain every card of the module exactly once"))
+	}
+
+	if err = uow.GetCardRepoWriter().ReorderCards(moduleId, cardsIds); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.GetModuleRepoWriter().TouchModule(moduleId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
 
 	if err = uow.Commit(); err != nil {
 		return usecase.NewInternalError(err)
@@ -134,6 +210,62 @@ func (u *UseCase) UpdateCard(userId int, card entity.Card) error {
 	return nil
 }
 
+// isPermutation проверяет, что ids содержит те же элементы, что и current, без повторов
+func isPermutation(ids, current []int) bool {
+	if len(ids) != len(current) {
+		return false
+	}
+	sorted, sortedCurrent := slices.Clone(ids), slices.Clone(current)
+	slices.Sort(sorted)
+	slices.Sort(sortedCurrent)
+	return slices.Equal(sorted, sortedCurrent)
+}
+
+func cardsToAdd(cards []entity.Card) []entity.CardToAdd {
+	res := make([]entity.CardToAdd, 0, len(cards))
+	for _, card := range cards {
+		res = append(res, entity.CardToAdd{Term: card.Term, Definition: card.Definition})
+	}
+	return res
+}
+
+// UpdateCard меняет карточку, если ее версия все еще version, и возвращает новую версию
+func (u *UseCase) UpdateCard(userId, version int, card entity.Card) (int, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.cardMutex.Lock()
+	defer u.cardMutex.Unlock()
+
+	_, res, err := u.cardResource(card.Id, uow)
+	if err != nil {
+		return -1, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return -1, u.policy.Deny(res)
+	}
+
+	current, err := uow.GetCardRepoReader().GetCardById(card.Id)
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	} else if current.Version != version {
+		return -1, usecase.NewVersionMismatchError("card", current.Version, current)
+	}
+
+	card.Version = version
+	newVersion, err := u.updateCardWithRevision(userId, current, card, entity.UpdateRevision, uow)
+	if err != nil {
+		return -1, err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	return newVersion, nil
+}
+
 func (u *UseCase) DeleteCard(userId int, cardId int) error {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
@@ -144,23 +276,19 @@ func (u *UseCase) DeleteCard(userId int, cardId int) error {
 	u.cardMutex.Lock()
 	defer u.cardMutex.Unlock()
 
-	ownerId, err := u.getCardOwnerId(cardId, uow)
+	module, res, err := u.cardResource(cardId, uow)
 	if err != nil {
 		return err
-	} else if ownerId != userId {
-		return usecase.NewNotAvailableError("card", cardId)
+	} else if !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
 	}
 
-	u.cardsResultsMutex.Lock()
-	defer u.cardsResultsMutex.Unlock()
-
-	err = uow.GetCardsResultsRepoWriter().DeleteResultsToCard(cardId)
+	// вложения остаются до очистки корзины
+	err = uow.GetCardRepoWriter().TrashCard(cardId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
-
-	err = uow.GetCardRepoWriter().DeleteCard(cardId)
-	if err != nil {
+	if err = uow.GetModuleRepoWriter().TouchModule(module.Id); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
@@ -175,25 +303,11 @@ func (u *UseCase) deleteCardsToParentModule(moduleId int, uow uow.UnitOfWork) er
 		return usecase.NewInternalError(errors.New("uow is null"))
 	}
 
-	module, err := uow.GetModuleRepoReader().GetModuleById(moduleId)
-	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
-	}
-
-	u.cardsResultsMutex.Lock()
-	defer u.cardsResultsMutex.Unlock()
-
-	for _, card := range module.Cards {
-		err = uow.GetCardsResultsRepoWriter().DeleteResultsToCard(card.Id)
-		if err != nil {
-			return u.errorsMapper.DBErrorToApp(err)
-		}
-	}
-
+	// результаты по карточкам удаляются вместе с результатами модуля
 	u.cardMutex.Lock()
 	defer u.cardMutex.Unlock()
 
-	err = uow.GetCardRepoWriter().DeleteCardsToParentModule(moduleId)
+	err := uow.GetCardRepoWriter().DeleteCardsToParentModule(moduleId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
@@ -0,0 +1,76 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/usecase"
+	"slices"
+	"testing"
+)
+
+func TestIsPermutation(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []int
+		current []int
+		want    bool
+	}{
+		{"both empty", []int{}, nil, true},
+		{"same order", []int{1, 2, 3}, []int{1, 2, 3}, true},
+		{"reordered", []int{3, 1, 2}, []int{1, 2, 3}, true},
+		{"missing card", []int{1, 2}, []int{1, 2, 3}, false},
+		{"extra card", []int{1, 2, 3, 4}, []int{1, 2, 3}, false},
+		{"foreign card", []int{1, 2, 4}, []int{1, 2, 3}, false},
+		{"repeated card", []int{1, 1, 2}, []int{1, 2, 3}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids := slices.Clone(test.ids)
+			if got := isPermutation(ids, test.current); got != test.want {
+				t.Errorf("isPermutation(%v, %v) = %v, want %v", test.ids, test.current, got, test.want)
+			}
+			// порядок из запроса сохраняется для записи в базу
+			if !slices.Equal(ids, test.ids) {
+				t.Errorf("ids changed to %v", ids)
+			}
+		})
+	}
+}
+
+func TestInsertAtPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  []int
+		inserted []int
+		position int
+		want     []int
+		wantErr  bool
+	}{
+		{"first card of empty module", []int{10}, []int{10}, 0, []int{10}, false},
+		{"to the beginning", []int{1, 2, 3, 10}, []int{10}, 0, []int{10, 1, 2, 3}, false},
+		{"to the middle", []int{1, 2, 3, 10}, []int{10}, 1, []int{1, 10, 2, 3}, false},
+		{"to the end", []int{1, 2, 3, 10}, []int{10}, 3, []int{1, 2, 3, 10}, false},
+		{"several cards keep their order", []int{1, 2, 10, 11, 12}, []int{10, 11, 12}, 1, []int{1, 10, 11, 12, 2}, false},
+		{"negative position", []int{1, 10}, []int{10}, -1, nil, true},
+		{"position after the end", []int{1, 2, 10}, []int{10}, 3, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := insertAtPosition(test.current, test.inserted, test.position)
+			if test.wantErr {
+				var invalid *usecase.InvalidDataError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("got error %v, want invalid data error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
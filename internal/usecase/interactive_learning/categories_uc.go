@@ -13,16 +13,7 @@ func (u *UseCase) GetCategoriesWithSimilarName(name string, limit, offset, userI
 		return nil, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	publicCategories := []entity.Category{}
-	for _, category := range categories {
-		if category.Type == entity.PublicCategory {
-			publicCategories = append(publicCategories, category)
-		} else if category.Type >= entity.PrivateCategory && category.OwnerId == userId {
-			publicCategories = append(publicCategories, category)
-		}
-	}
-
-	return publicCategories, nil
+	return u.visibleCategories(categories, userId)
 }
 
 func (u *UseCase) GetCategoriesToUser(ownerId int, isFull bool, userId int) ([]entity.Category, error) {
@@ -31,14 +22,9 @@ func (u *UseCase) GetCategoriesToUser(ownerId int, isFull bool, userId int) ([]e
 		return []entity.Category{}, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	if userId != ownerId {
-		publicCategories := []entity.Category{}
-		for _, category := range categories {
-			if category.Type == entity.PublicCategory {
-				publicCategories = append(publicCategories, category)
-			}
-		}
-		categories = publicCategories
+	categories, err = u.listedCategories(categories, userId)
+	if err != nil {
+		return []entity.Category{}, err
 	}
 
 	if !isFull {
@@ -58,13 +44,11 @@ func (u *UseCase) GetCategoriesToUser(ownerId int, isFull bool, userId int) ([]e
 }
 
 func (u *UseCase) GetCategoryById(id int, userId int) (entity.Category, error) {
-	category, err := u.categoryRepoRead.GetCategoryById(id)
+	category, res, err := u.categoryResource(id, nil)
 	if err != nil {
-		return entity.Category{}, u.errorsMapper.DBErrorToApp(err)
-	}
-
-	if category.Type >= entity.PrivateCategory && category.OwnerId != userId {
-		return entity.Category{}, usecase.NewNotAvailableError("category", category.Id)
+		return entity.Category{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.Category{}, u.policy.Deny(res)
 	}
 
 	modules, err := u.GetModulesToCategory(id, true, userId)
@@ -76,8 +60,12 @@ func (u *UseCase) GetCategoryById(id int, userId int) (entity.Category, error) {
 	return category, nil
 }
 
-func (u *UseCase) GetPopularCategories(limit, offset int) ([]entity.PopularCategory, error) {
-	popularCategories, err := u.categoryRepoRead.GetPopularCategories(limit, offset)
+func (u *UseCase) GetPopularCategories(limit, offset int, sortBy string) ([]entity.PopularCategory, error) {
+	sortBy, err := popularSort(sortBy)
+	if err != nil {
+		return nil, err
+	}
+	popularCategories, err := u.categoryRepoRead.GetPopularCategories(limit, offset, sortBy)
 	if err != nil {
 		return nil, u.errorsMapper.DBErrorToApp(err)
 	}
@@ -94,6 +82,26 @@ func (u *UseCase) InsertCategory(category entity.CategoryToCreate) (int, error)
 	u.categoryMutex.Lock()
 	defer u.categoryMutex.Unlock()
 
+	new_id, err := u.insertCategory(category, uow)
+	if err != nil {
+		return -1, err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+
+	return new_id, nil
+}
+
+// создает категорию и добавляет в нее модули в транзакции вызывающего
+func (u *UseCase) insertCategory(category entity.CategoryToCreate, uow uow.UnitOfWork) (int, error) {
+	if category.Type < entity.PublicCategory || category.Type > entity.UnlistedCategory {
+		return -1, usecase.NewInvalidDataError("category", errors.New("invalid type"))
+	}
+	if err := validateInfo("category", entity.Info{Description: category.Description, Difficulty: category.Difficulty}); err != nil {
+		return -1, err
+	}
 	err := uow.GetCategoryRepoWriter().InsertCategory(category)
 	if err != nil {
 		return -1, u.errorsMapper.DBErrorToApp(err)
@@ -102,14 +110,14 @@ func (u *UseCase) InsertCategory(category entity.CategoryToCreate) (int, error)
 	if err != nil {
 		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
+	if category.Type == entity.UnlistedCategory {
+		if err = u.ensureCategoryShareSlug(new_id, uow); err != nil {
+			return -1, err
+		}
+	}
 	if err = u.insertModulesToCategory(category.OwnerId, new_id, category.Modules, uow); err != nil {
 		return -1, err
 	}
-
-	if err = uow.Commit(); err != nil {
-		return -1, usecase.NewInternalError(err)
-	}
-
 	return new_id, nil
 }
 
@@ -118,19 +126,11 @@ func (u *UseCase) IsCategoryOwner(userId, categoryId int) (bool, error) {
 }
 
 func (u *UseCase) isCategoryOwner(userId, categoryId int, uow uow.UnitOfWork) (bool, error) {
-	categoryRepoRead := u.categoryRepoRead
-	if uow != nil {
-		categoryRepoRead = uow.GetCategoryRepoReader()
-	}
-
-	ownerId, err := categoryRepoRead.GetCategoryOwnerId(categoryId)
+	_, res, err := u.categoryResource(categoryId, uow)
 	if err != nil {
-		return false, u.errorsMapper.DBErrorToApp(err)
+		return false, err
 	}
-	if ownerId != userId {
-		return false, nil
-	}
-	return true, nil
+	return u.policy.CanEdit(userId, res), nil
 }
 
 func (u *UseCase) RenameCategory(userId, categoryId int, newName string) error {
@@ -143,11 +143,11 @@ func (u *UseCase) RenameCategory(userId, categoryId int, newName string) error {
 	u.categoryModulesMutex.Lock()
 	defer u.categoryModulesMutex.Unlock()
 
-	isOwner, err := u.isCategoryOwner(userId, categoryId, uow)
+	_, res, err := u.categoryResource(categoryId, uow)
 	if err != nil {
 		return err
-	} else if !isOwner {
-		return usecase.NewNotAvailableError("category", categoryId)
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
 	}
 
 	err = uow.GetCategoryRepoWriter().RenameCategory(categoryId, newName)
@@ -160,35 +160,65 @@ func (u *UseCase) RenameCategory(userId, categoryId int, newName string) error {
 	return nil
 }
 
-func (u *UseCase) UpdateCategoryType(categoryId, newType, userId int) error {
+func (u *UseCase) UpdateCategoryInfo(userId, categoryId int, info entity.Info) error {
+	if err := validateInfo("category", info); err != nil {
+		return err
+	}
+
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return usecase.NewInternalError(err)
 	}
 	defer uow.Rollback()
 
-	category, err := uow.GetCategoryRepoReader().GetCategoryById(categoryId)
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
 	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetCategoryRepoWriter().UpdateCategoryInfo(categoryId, info); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
-	isOwner, err := u.isCategoryOwner(userId, categoryId, uow)
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) UpdateCategoryType(categoryId, newType, userId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	category, res, err := u.categoryResource(categoryId, uow)
 	if err != nil {
 		return err
-	} else if !isOwner {
-		return usecase.NewNotAvailableError("category", categoryId)
+	} else if !u.policy.CanShare(userId, res) {
+		return u.policy.Deny(res)
 	}
 
-	if category.Type == newType {
+	// приватные модули закрывают категорию независимо от выбора владельца,
+	// после их удаления категория станет такой, какой ее сделал владелец
+	if category.Visibility == newType {
 		return usecase.NewChangeTypeError("category", errors.New("category already has this type"))
-	} else if newType == entity.PublicCategory && category.Type > entity.PrivateCategory {
-		return usecase.NewChangeTypeError("category", errors.New("It is impossible to set the type, the category contains a closed module."))
-	} else if newType > entity.PrivateCategory || newType < entity.PublicCategory {
+	} else if newType > entity.UnlistedCategory || newType < entity.PublicCategory {
 		return usecase.NewChangeTypeError("category", errors.New("Invalid type"))
 	}
 
-	err = uow.GetCategoryRepoWriter().UpdateCategoryType(categoryId, newType)
-	if err != nil {
+	if newType == entity.UnlistedCategory {
+		if err = u.ensureCategoryShareSlug(categoryId, uow); err != nil {
+			return err
+		}
+	}
+	if err = uow.GetCategoryRepoWriter().UpdateCategoryVisibility(categoryId, newType); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 	if err = uow.Commit(); err != nil {
@@ -205,35 +235,17 @@ func (u *UseCase) DeleteCategory(userId int, id int) error {
 	defer uow.Rollback()
 
 	u.categoryMutex.Lock()
-	u.selectedMutex.Lock()
-	defer func() {
-		u.categoryMutex.Unlock()
-		u.selectedMutex.Unlock()
-	}()
-
-	isOwner, err := u.isCategoryOwner(userId, id, uow)
-	if err != nil {
-		return err
-	} else if !isOwner {
-		return usecase.NewNotAvailableError("category", id)
-	}
-
-	err = u.deleteAllModulesFromCategory(id, uow)
-	if err != nil {
-		return err
-	}
+	defer u.categoryMutex.Unlock()
 
-	err = u.deleteResultByCategoryId(id, uow)
+	_, res, err := u.categoryResource(id, uow)
 	if err != nil {
 		return err
+	} else if !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
 	}
 
-	err = uow.GetSelectedRepoWriter().DeleteAllToCategory(id)
-	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
-	}
-
-	err = uow.GetCategoryRepoWriter().DeleteCategory(id)
+	// модули, результаты и избранное остаются до очистки корзины
+	err = uow.GetCategoryRepoWriter().TrashCategory(id)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
@@ -5,33 +5,39 @@ import (
 	"interactive_learning/internal/entity"
 	"interactive_learning/internal/uow"
 	"interactive_learning/internal/usecase"
-	"log"
 	"slices"
 )
 
 func (u *UseCase) GetModulesToCategory(categoryId int, isFull bool, userId int) ([]entity.Module, error) {
-	category, err := u.categoryRepoRead.GetCategoryById(categoryId)
+	_, res, err := u.categoryResource(categoryId, nil)
 	if err != nil {
-		return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
-	}
-
-	if category.Type >= entity.PrivateCategory && category.OwnerId != userId {
-		return []entity.Module{}, usecase.NewNotAvailableError("category", categoryId)
+		return []entity.Module{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.Module{}, u.policy.Deny(res)
 	}
+	return u.categoryModules(categoryId, isFull, userId)
+}
 
+// categoryModules возвращает модули категории, доступ к которой уже проверен.
+// Модули по ссылке видны всем, кому открыта категория, приватные - только тем, кому они доступны.
+func (u *UseCase) categoryModules(categoryId int, isFull bool, userId int) ([]entity.Module, error) {
 	modules, err := u.categoryModulesRepoRead.GetModulesToCategory(categoryId)
 	if err != nil {
 		return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
 	}
+	modules, err = u.filterModules(modules, userId, u.policy.CanOpenLink)
+	if err != nil {
+		return []entity.Module{}, err
+	}
 
 	if !isFull {
 		return modules, nil
 	}
 
 	for i := range modules {
-		cards, err := u.cardsRepoRead.GetCardsByModule(modules[i].Id)
+		cards, err := u.getCardsByModule(modules[i].Id)
 		if err != nil {
-			return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
+			return []entity.Module{}, err
 		}
 
 		modules[i].Cards = cards
@@ -61,12 +67,11 @@ func (u *UseCase) insertModulesToCategory(userId, categoryId int, modulesIds []i
 	u.categoryModulesMutex.Lock()
 	defer u.categoryModulesMutex.Unlock()
 
-	category, err := uow.GetCategoryRepoReader().GetCategoryById(categoryId)
+	category, res, err := u.categoryResource(categoryId, uow)
 	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
-	}
-	if category.OwnerId != userId {
-		return usecase.NewNotAvailableError("category", categoryId)
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
 	}
 
 	for _, moduleId := range modulesIds {
@@ -75,19 +80,14 @@ func (u *UseCase) insertModulesToCategory(userId, categoryId int, modulesIds []i
 		}
 	}
 
-	newCategoryType := category.Type
 	for _, moduleId := range modulesIds {
-		module, err := uow.GetModuleRepoReader().GetModuleById(moduleId)
+		// в категорию можно добавить любой модуль, который пользователь может просматривать,
+		// приватный модуль закрывает категорию
+		_, res, err := u.moduleResource(moduleId, uow)
 		if err != nil {
-			return u.errorsMapper.DBErrorToApp(err)
-		}
-
-		if module.Type == entity.PrivateModule {
-			if newCategoryType == entity.PublicCategory {
-				newCategoryType = entity.PrivateCategory
-			}
-			newCategoryType++
-			log.Print(newCategoryType)
+			return err
+		} else if !u.policy.CanView(userId, res) {
+			return u.policy.Deny(res)
 		}
 
 		err = uow.GetCategoryModulesRepoWriter().InsertModulesToCategory(categoryId, moduleId)
@@ -95,17 +95,16 @@ func (u *UseCase) insertModulesToCategory(userId, categoryId int, modulesIds []i
 			return u.errorsMapper.DBErrorToApp(err)
 		}
 	}
-
-	if newCategoryType != category.Type {
-		err = uow.GetCategoryRepoWriter().UpdateCategoryType(categoryId, newCategoryType)
-		if err != nil {
+	if len(modulesIds) > 0 {
+		if err = uow.GetCategoryRepoWriter().TouchCategory(categoryId); err != nil {
 			return u.errorsMapper.DBErrorToApp(err)
 		}
 	}
 	return nil
 }
 
-func (u *UseCase) DeleteModuleFromCategory(userId, categoryId, moduleId int) error {
+// ReorderCategoryModules задает порядок модулей категории, modulesIds - перестановка ее модулей
+func (u *UseCase) ReorderCategoryModules(userId, categoryId int, modulesIds []int) error {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return usecase.NewInternalError(err)
@@ -115,34 +114,67 @@ func (u *UseCase) DeleteModuleFromCategory(userId, categoryId, moduleId int) err
 	u.categoryModulesMutex.Lock()
 	defer u.categoryModulesMutex.Unlock()
 
-	isOwner, err := u.isCategoryOwner(userId, categoryId, uow)
+	_, res, err := u.categoryResource(categoryId, uow)
 	if err != nil {
 		return err
-	} else if !isOwner {
-		return usecase.NewNotAvailableError("category", categoryId)
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
 	}
 
-	err = u.deleteModuleResFromCategory(categoryId, moduleId, uow)
+	modules, err := uow.GetCategoryModulesRepoReader().GetModulesToCategory(categoryId)
 	if err != nil {
-		return err
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	currentIds := make([]int, 0, len(modules))
+	for _, module := range modules {
+		currentIds = append(currentIds, module.Id)
+	}
+	if !isPermutation(modulesIds, currentIds) {
+		return usecase.NewInvalidDataError("modules", errors.New("order must contain every module of the category exactly once"))
 	}
 
-	module, err := uow.GetModuleRepoReader().GetModuleById(moduleId)
-	if err != nil {
+	if err = uow.GetCategoryModulesRepoWriter().ReorderModules(categoryId, modulesIds); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.GetCategoryRepoWriter().TouchCategory(categoryId); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
-	if module.Type == entity.PrivateModule {
-		err = uow.GetCategoryRepoWriter().TurnDownCategoryType(categoryId)
-		if err != nil {
-			return u.errorsMapper.DBErrorToApp(err)
-		}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) DeleteModuleFromCategory(userId, categoryId, moduleId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryModulesMutex.Lock()
+	defer u.categoryModulesMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	err = u.deleteModuleResFromCategory(categoryId, moduleId, uow)
+	if err != nil {
+		return err
 	}
 
 	err = uow.GetCategoryModulesRepoWriter().DeleteModuleFromCategory(categoryId, moduleId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
+	if err = uow.GetCategoryRepoWriter().TouchCategory(categoryId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
 
 	if err = uow.Commit(); err != nil {
 		return usecase.NewInternalError(err)
@@ -169,24 +201,7 @@ func (u *UseCase) deleteModuleFromCategories(moduleId int, uow uow.UnitOfWork) e
 	u.categoryModulesMutex.Lock()
 	defer u.categoryModulesMutex.Unlock()
 
-	module, err := uow.GetModuleRepoReader().GetModuleById(moduleId)
-	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
-	}
-	if module.Type == entity.PrivateModule {
-		categories, err := uow.GetCategoryModulesRepoReader().GetCategoriesContainsModule(moduleId)
-		if err != nil {
-			return u.errorsMapper.DBErrorToApp(err)
-		}
-		for _, category := range categories {
-			err = uow.GetCategoryRepoWriter().TurnDownCategoryType(category.Id)
-			if err != nil {
-				return u.errorsMapper.DBErrorToApp(err)
-			}
-		}
-	}
-
-	if err = uow.GetCategoryModulesRepoWriter().DeleteModuleFromCategories(moduleId); err != nil {
+	if err := uow.GetCategoryModulesRepoWriter().DeleteModuleFromCategories(moduleId); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 	return nil
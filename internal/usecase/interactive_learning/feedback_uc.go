@@ -0,0 +1,381 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"strings"
+	"unicode/utf8"
+)
+
+// размер страницы комментариев, жалоб и уведомлений
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// popularSort проверяет сортировку списка популярных, по умолчанию - по ученикам
+func popularSort(sortBy string) (string, error) {
+	switch sortBy {
+	case "":
+		return entity.PopularByLearners, nil
+	case entity.PopularByLearners, entity.PopularByRating:
+		return sortBy, nil
+	}
+	return "", usecase.NewInvalidDataError("sort", errors.New("unknown sort "+sortBy))
+}
+
+func validateRating(rating int) error {
+	if rating < entity.MinRating || rating > entity.MaxRating {
+		return usecase.NewInvalidDataError("rating", errors.New("rating must be from 1 to 5"))
+	}
+	return nil
+}
+
+// validateComment возвращает текст комментария без пробелов по краям
+func validateComment(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", usecase.NewInvalidDataError("comment", errors.New("empty comment"))
+	} else if utf8.RuneCountInString(text) > entity.MaxCommentLength {
+		return "", usecase.NewInvalidDataError("comment", errors.New("comment is too long"))
+	}
+	return text, nil
+}
+
+// pageLimit проверяет страницу списка object, нулевой limit - размер по умолчанию
+func pageLimit(object string, limit, offset int) (int, error) {
+	if limit == 0 {
+		limit = defaultPageLimit
+	} else if limit < 0 || limit > maxPageLimit {
+		return 0, usecase.NewInvalidDataError(object, errors.New("limit is out of range"))
+	}
+	if offset < 0 {
+		return 0, usecase.NewInvalidDataError(object, errors.New("offset is out of range"))
+	}
+	return limit, nil
+}
+
+// commentResource загружает комментарий и автора модуля или категории, к которым он оставлен
+func (u *UseCase) commentResource(commentId int, uow uow.UnitOfWork) (entity.Comment, usecase.Resource, error) {
+	comment, err := uow.GetCommentsRepoReader().GetCommentById(commentId)
+	if err != nil {
+		return entity.Comment{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	var contentOwnerId int
+	if comment.ModuleId != nil {
+		contentOwnerId, err = uow.GetModuleRepoReader().GetModuleOwnerId(*comment.ModuleId)
+	} else {
+		contentOwnerId, err = uow.GetCategoryRepoReader().GetCategoryOwnerId(*comment.CategoryId)
+	}
+	if err != nil {
+		return entity.Comment{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return comment, usecase.CommentResource(comment, contentOwnerId), nil
+}
+
+func (u *UseCase) GetModuleRating(userId, moduleId int) (entity.RatingSummary, error) {
+	_, res, err := u.moduleResource(moduleId, nil)
+	if err != nil {
+		return entity.RatingSummary{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.RatingSummary{}, u.policy.Deny(res)
+	}
+
+	summary, err := u.ratingsRepoRead.GetModuleRating(moduleId, userId)
+	if err != nil {
+		return entity.RatingSummary{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return summary, nil
+}
+
+// RateModule ставит оценку модулю или меняет прежнюю оценку пользователя
+func (u *UseCase) RateModule(userId, moduleId, rating int) error {
+	if err := validateRating(rating); err != nil {
+		return err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanRate(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetRatingsRepoWriter().SetModuleRating(moduleId, userId, rating); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) UnrateModule(userId, moduleId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	if err := uow.GetRatingsRepoWriter().DeleteModuleRating(moduleId, userId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err := uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) GetCategoryRating(userId, categoryId int) (entity.RatingSummary, error) {
+	_, res, err := u.categoryResource(categoryId, nil)
+	if err != nil {
+		return entity.RatingSummary{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.RatingSummary{}, u.policy.Deny(res)
+	}
+
+	summary, err := u.ratingsRepoRead.GetCategoryRating(categoryId, userId)
+	if err != nil {
+		return entity.RatingSummary{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return summary, nil
+}
+
+func (u *UseCase) RateCategory(userId, categoryId, rating int) error {
+	if err := validateRating(rating); err != nil {
+		return err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanRate(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetRatingsRepoWriter().SetCategoryRating(categoryId, userId, rating); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) UnrateCategory(userId, categoryId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	if err := uow.GetRatingsRepoWriter().DeleteCategoryRating(categoryId, userId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err := uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) GetModuleComments(userId, moduleId, limit, offset int) ([]entity.Comment, error) {
+	limit, err := pageLimit("comments", limit, offset)
+	if err != nil {
+		return []entity.Comment{}, err
+	}
+	_, res, err := u.moduleResource(moduleId, nil)
+	if err != nil {
+		return []entity.Comment{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.Comment{}, u.policy.Deny(res)
+	}
+
+	comments, err := u.commentsRepoRead.GetModuleComments(moduleId, limit, offset)
+	if err != nil {
+		return []entity.Comment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return comments, nil
+}
+
+func (u *UseCase) AddModuleComment(userId, moduleId int, text string) (int, error) {
+	text, err := validateComment(text)
+	if err != nil {
+		return -1, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err = uow.Begin(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return -1, err
+	} else if !u.policy.CanComment(userId, res) {
+		return -1, u.policy.Deny(res)
+	}
+
+	if err = uow.GetCommentsRepoWriter().InsertModuleComment(moduleId, userId, text); err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	id, err := uow.GetCommentsRepoReader().GetLastInsertedCommentId()
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	return id, nil
+}
+
+func (u *UseCase) GetCategoryComments(userId, categoryId, limit, offset int) ([]entity.Comment, error) {
+	limit, err := pageLimit("comments", limit, offset)
+	if err != nil {
+		return []entity.Comment{}, err
+	}
+	_, res, err := u.categoryResource(categoryId, nil)
+	if err != nil {
+		return []entity.Comment{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.Comment{}, u.policy.Deny(res)
+	}
+
+	comments, err := u.commentsRepoRead.GetCategoryComments(categoryId, limit, offset)
+	if err != nil {
+		return []entity.Comment{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return comments, nil
+}
+
+func (u *UseCase) AddCategoryComment(userId, categoryId int, text string) (int, error) {
+	text, err := validateComment(text)
+	if err != nil {
+		return -1, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err = uow.Begin(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return -1, err
+	} else if !u.policy.CanComment(userId, res) {
+		return -1, u.policy.Deny(res)
+	}
+
+	if err = uow.GetCommentsRepoWriter().InsertCategoryComment(categoryId, userId, text); err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	id, err := uow.GetCommentsRepoReader().GetLastInsertedCommentId()
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	return id, nil
+}
+
+// UpdateComment - текст комментария меняет только его автор
+func (u *UseCase) UpdateComment(userId, commentId int, text string) error {
+	text, err := validateComment(text)
+	if err != nil {
+		return err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err = uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	_, res, err := u.commentResource(commentId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetCommentsRepoWriter().UpdateCommentText(commentId, text); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// DeleteComment - удалить комментарий может его автор или автор модуля или категории
+func (u *UseCase) DeleteComment(userId, commentId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+
+	_, res, err := u.commentResource(commentId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetCommentsRepoWriter().DeleteComment(commentId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package interactivelearning
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/usecase"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
+)
+
+// курсор ленты непрозрачен для клиента: это закодированное последнее событие страницы
+func encodeFeedCursor(cursor entity.FeedCursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeFeedCursor(encoded string) (*entity.FeedCursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, usecase.NewInvalidDataError("cursor", err)
+	}
+	cursor := entity.FeedCursor{}
+	if err = json.Unmarshal(raw, &cursor); err != nil {
+		return nil, usecase.NewInvalidDataError("cursor", err)
+	}
+	return &cursor, nil
+}
+
+func (u *UseCase) Follow(userId, followeeId int) error {
+	if userId == followeeId {
+		return usecase.NewInvalidDataError("follow", errors.New("can not follow yourself"))
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.usersMutex.Lock()
+	defer u.usersMutex.Unlock()
+
+	if _, err := uow.GetUsersRepoReader().GetUserInfoById(followeeId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	isFollowing, err := uow.GetFollowsRepoReader().IsFollowing(userId, followeeId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	} else if isFollowing {
+		return usecase.NewAlreadyExistsError("follow", followeeId)
+	}
+
+	if err = uow.GetFollowsRepoWriter().InsertFollow(userId, followeeId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) Unfollow(userId, followeeId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.usersMutex.Lock()
+	defer u.usersMutex.Unlock()
+
+	if err := uow.GetFollowsRepoWriter().DeleteFollow(userId, followeeId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err := uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// GetFollowers возвращает подписчиков пользователя, списки подписок открыты всем
+func (u *UseCase) GetFollowers(ownerId, userId int) ([]entity.User, error) {
+	if res := usecase.UserResource(ownerId); !u.policy.CanView(userId, res) {
+		return []entity.User{}, u.policy.Deny(res)
+	}
+	if _, err := u.usersRepoRead.GetUserInfoById(ownerId); err != nil {
+		return []entity.User{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	users, err := u.followsRepoRead.GetFollowers(ownerId)
+	if err != nil {
+		return []entity.User{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return users, nil
+}
+
+func (u *UseCase) GetFollowing(ownerId, userId int) ([]entity.User, error) {
+	if res := usecase.UserResource(ownerId); !u.policy.CanView(userId, res) {
+		return []entity.User{}, u.policy.Deny(res)
+	}
+	if _, err := u.usersRepoRead.GetUserInfoById(ownerId); err != nil {
+		return []entity.User{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	users, err := u.followsRepoRead.GetFollowing(ownerId)
+	if err != nil {
+		return []entity.User{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return users, nil
+}
+
+// GetFeed возвращает страницу ленты подписок. Пустой cursor - первая страница,
+// NextCursor пустой, если дальше событий нет.
+func (u *UseCase) GetFeed(userId int, cursor string, limit int) (entity.FeedPage, error) {
+	if limit == 0 {
+		limit = defaultFeedLimit
+	} else if limit < 0 || limit > maxFeedLimit {
+		return entity.FeedPage{}, usecase.NewInvalidDataError("feed", errors.New("limit is out of range"))
+	}
+	after, err := decodeFeedCursor(cursor)
+	if err != nil {
+		return entity.FeedPage{}, err
+	}
+
+	items, err := u.followsRepoRead.GetFeed(userId, after, limit)
+	if err != nil {
+		return entity.FeedPage{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	page := entity.FeedPage{Items: items}
+	if len(items) == limit {
+		last := items[len(items)-1]
+		if page.NextCursor, err = encodeFeedCursor(entity.FeedCursor{At: last.At, Kind: last.Kind, Id: last.Id}); err != nil {
+			return entity.FeedPage{}, err
+		}
+	}
+	return page, nil
+}
+
+// SetShareActivity включает показ успехов пользователя в ленте его подписчиков
+func (u *UseCase) SetShareActivity(userId int, share bool) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.usersMutex.Lock()
+	defer u.usersMutex.Unlock()
+
+	if err := uow.GetUsersRepoWriter().SetShareActivity(userId, share); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err := uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package interactivelearning
+
+import (
+	"encoding/base64"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/usecase"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFeedCursorRoundTrip(t *testing.T) {
+	cursors := []entity.FeedCursor{
+		{},
+		{At: time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.UTC), Kind: "module", Id: 42},
+		{At: time.Date(2023, 12, 31, 23, 59, 59, 0, time.FixedZone("MSK", 3*60*60)), Kind: "category", Id: 1},
+	}
+
+	for _, cursor := range cursors {
+		encoded, err := encodeFeedCursor(cursor)
+		if err != nil {
+			t.Fatalf("encode %+v: %v", cursor, err)
+		}
+		decoded, err := decodeFeedCursor(encoded)
+		if err != nil {
+			t.Fatalf("decode %q: %v", encoded, err)
+		}
+		if decoded == nil || !decoded.At.Equal(cursor.At) || decoded.Kind != cursor.Kind || decoded.Id != cursor.Id {
+			t.Errorf("got %+v, want %+v", decoded, cursor)
+		}
+	}
+}
+
+func TestDecodeFeedCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    *entity.FeedCursor
+		wantErr bool
+	}{
+		{"empty cursor is first page", "", nil, false},
+		{"not base64", "курсор", nil, true},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"id":1}`)), nil, true},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("cursor")), nil, true},
+		{"wrong field type", base64.RawURLEncoding.EncodeToString([]byte(`{"id":"1"}`)), nil, true},
+		{"bad time", base64.RawURLEncoding.EncodeToString([]byte(`{"at":"yesterday"}`)), nil, true},
+		{"partial cursor", base64.RawURLEncoding.EncodeToString([]byte(`{"kind":"module","id":7}`)), &entity.FeedCursor{Kind: "module", Id: 7}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := decodeFeedCursor(test.encoded)
+			if test.wantErr {
+				var invalid *usecase.InvalidDataError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("got error %v, want invalid data error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
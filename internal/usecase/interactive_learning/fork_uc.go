@@ -0,0 +1,102 @@
+package interactivelearning
+
+import (
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/usecase"
+)
+
+// ForkModule копирует доступный пользователю модуль вместе с карточками в его аккаунт
+func (u *UseCase) ForkModule(userId, moduleId int) (int, []int, error) {
+	module, err := u.GetModuleById(moduleId, userId)
+	if err != nil {
+		return -1, []int{}, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return -1, []int{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	u.cardMutex.Lock()
+	defer func() {
+		u.moduleMutex.Unlock()
+		u.cardMutex.Unlock()
+	}()
+
+	id, cardsIds, err := u.insertModule(forkedModule(userId, module), uow)
+	if err != nil {
+		return -1, []int{}, err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return -1, []int{}, usecase.NewInternalError(err)
+	}
+	return id, cardsIds, nil
+}
+
+// ForkCategory копирует категорию и все ее модули в аккаунт пользователя
+func (u *UseCase) ForkCategory(userId, categoryId int) (int, []int, error) {
+	category, err := u.GetCategoryById(categoryId, userId)
+	if err != nil {
+		return -1, []int{}, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return -1, []int{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	u.cardMutex.Lock()
+	u.categoryMutex.Lock()
+	defer func() {
+		u.moduleMutex.Unlock()
+		u.cardMutex.Unlock()
+		u.categoryMutex.Unlock()
+	}()
+
+	modulesIds := make([]int, 0, len(category.Modules))
+	for _, module := range category.Modules {
+		id, _, err := u.insertModule(forkedModule(userId, module), uow)
+		if err != nil {
+			return -1, []int{}, err
+		}
+		modulesIds = append(modulesIds, id)
+	}
+
+	id, err := u.insertCategory(entity.CategoryToCreate{
+		Name:       category.Name,
+		OwnerId:    userId,
+		Modules:    modulesIds,
+		Type:       category.Visibility,
+		ForkedFrom: &entity.ForkOrigin{Id: &category.Id, OwnerId: category.OwnerId},
+
+		Description: category.Description,
+		Difficulty:  category.Difficulty,
+	}, uow)
+	if err != nil {
+		return -1, []int{}, err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return -1, []int{}, usecase.NewInternalError(err)
+	}
+	return id, modulesIds, nil
+}
+
+func forkedModule(userId int, module entity.Module) entity.ModuleToCreate {
+	return entity.ModuleToCreate{
+		Name:       module.Name,
+		Cards:      cardsToAdd(module.Cards),
+		OwnerId:    userId,
+		Type:       module.Type,
+		Tags:       module.Tags,
+		ForkedFrom: &entity.ForkOrigin{Id: &module.Id, OwnerId: module.OwnerId},
+
+		Description: module.Description,
+		Difficulty:  module.Difficulty,
+	}
+}
@@ -0,0 +1,182 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"slices"
+)
+
+// checkGrantee проверяет роль и что пользователь, которому выдается доступ, существует и не владелец
+func (u *UseCase) checkGrantee(ownerId, granteeId int, role string, uow uow.UnitOfWork) error {
+	if !slices.Contains(entity.GrantRoles, role) {
+		return usecase.NewInvalidDataError("grant", errors.New("unknown role "+role))
+	}
+	if granteeId == ownerId {
+		return usecase.NewInvalidDataError("grant", errors.New("owner already has full access"))
+	}
+	if _, err := uow.GetUsersRepoReader().GetUserInfoById(granteeId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	return nil
+}
+
+func (u *UseCase) GetModuleGrants(userId, moduleId int) ([]entity.Grant, error) {
+	_, res, err := u.moduleResource(moduleId, nil)
+	if err != nil {
+		return []entity.Grant{}, err
+	} else if !u.policy.CanShare(userId, res) {
+		return []entity.Grant{}, u.policy.Deny(res)
+	}
+
+	grants, err := u.grantsRepoRead.GetModuleGrants(moduleId)
+	if err != nil {
+		return []entity.Grant{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return grants, nil
+}
+
+// ShareModule выдает пользователю роль в модуле, повторный вызов меняет роль
+func (u *UseCase) ShareModule(userId, moduleId, granteeId int, role string) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanShare(userId, res) {
+		return u.policy.Deny(res)
+	}
+	if err = u.checkGrantee(res.OwnerId, granteeId, role, uow); err != nil {
+		return err
+	}
+
+	if err = uow.GetGrantsRepoWriter().SetModuleGrant(moduleId, granteeId, role); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) UnshareModule(userId, moduleId, granteeId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanShare(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetGrantsRepoWriter().DeleteModuleGrant(moduleId, granteeId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) GetCategoryGrants(userId, categoryId int) ([]entity.Grant, error) {
+	_, res, err := u.categoryResource(categoryId, nil)
+	if err != nil {
+		return []entity.Grant{}, err
+	} else if !u.policy.CanShare(userId, res) {
+		return []entity.Grant{}, u.policy.Deny(res)
+	}
+
+	grants, err := u.grantsRepoRead.GetCategoryGrants(categoryId)
+	if err != nil {
+		return []entity.Grant{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return grants, nil
+}
+
+// ShareCategory выдает пользователю роль в категории. Зритель категории
+// видит и ее модули, если их автор - владелец категории.
+func (u *UseCase) ShareCategory(userId, categoryId, granteeId int, role string) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanShare(userId, res) {
+		return u.policy.Deny(res)
+	}
+	if err = u.checkGrantee(res.OwnerId, granteeId, role, uow); err != nil {
+		return err
+	}
+
+	if err = uow.GetGrantsRepoWriter().SetCategoryGrant(categoryId, granteeId, role); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) UnshareCategory(userId, categoryId, granteeId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanShare(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetGrantsRepoWriter().DeleteCategoryGrant(categoryId, granteeId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) GetSharedWithUser(userId int) (entity.Shared, error) {
+	modules, err := u.grantsRepoRead.GetModulesSharedWithUser(userId)
+	if err != nil {
+		return entity.Shared{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	categories, err := u.grantsRepoRead.GetCategoriesSharedWithUser(userId)
+	if err != nil {
+		return entity.Shared{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return entity.Shared{Modules: modules, Categories: categories}, nil
+}
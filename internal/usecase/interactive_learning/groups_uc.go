@@ -0,0 +1,482 @@
+package interactivelearning
+
+import (
+	"crypto/rand"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// в коде нет похожих друг на друга символов, чтобы его было легко продиктовать
+const joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+const joinCodeLength = 8
+
+func newJoinCode() (string, error) {
+	code := make([]byte, joinCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(joinCodeAlphabet))))
+		if err != nil {
+			return "", usecase.NewInternalError(err)
+		}
+		code[i] = joinCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// hideJoinCode убирает код вступления для всех, кроме преподавателей группы
+func (u *UseCase) hideJoinCode(userId int, group entity.Group, res usecase.Resource) entity.Group {
+	if !u.policy.CanEdit(userId, res) {
+		group.JoinCode = ""
+	}
+	return group
+}
+
+func (u *UseCase) CreateGroup(userId int, name string) (entity.Group, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return entity.Group{}, usecase.NewInvalidDataError("group", errors.New("empty name"))
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return entity.Group{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	code, err := newJoinCode()
+	if err != nil {
+		return entity.Group{}, err
+	}
+	if err = uow.GetGroupsRepoWriter().InsertGroup(entity.Group{Name: name, OwnerId: userId, JoinCode: code}); err != nil {
+		return entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	groupId, err := uow.GetGroupsRepoReader().GetLastInsertedGroupId()
+	if err != nil {
+		return entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	// создатель группы - ее преподаватель
+	if err = uow.GetGroupsRepoWriter().InsertGroupMember(groupId, userId, entity.TeacherRole); err != nil {
+		return entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	group, err := uow.GetGroupsRepoReader().GetGroupById(groupId)
+	if err != nil {
+		return entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return entity.Group{}, usecase.NewInternalError(err)
+	}
+	return group, nil
+}
+
+// GetUserGroups возвращает группы, в которых пользователь преподает или учится
+func (u *UseCase) GetUserGroups(userId int) ([]entity.Group, error) {
+	groups, err := u.groupsRepoRead.GetGroupsByUser(userId)
+	if err != nil {
+		return []entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	for i := range groups {
+		if groups[i].OwnerId != userId {
+			groups[i].JoinCode = ""
+		}
+	}
+	return groups, nil
+}
+
+func (u *UseCase) GetGroup(userId, groupId int) (entity.Group, error) {
+	group, res, err := u.groupResource(groupId, nil)
+	if err != nil {
+		return entity.Group{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.Group{}, u.policy.Deny(res)
+	}
+	return u.hideJoinCode(userId, group, res), nil
+}
+
+func (u *UseCase) RenameGroup(userId, groupId int, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return usecase.NewInvalidDataError("group", errors.New("empty name"))
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	_, res, err := u.groupResource(groupId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetGroupsRepoWriter().RenameGroup(groupId, name); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// DeleteGroup удаляет группу. Материалы и результаты учеников остаются у их владельцев.
+func (u *UseCase) DeleteGroup(userId, groupId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	_, res, err := u.groupResource(groupId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetGroupsRepoWriter().DeleteGroup(groupId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// RotateJoinCode заменяет код вступления, старый перестает работать.
+// Уже вступившие ученики остаются в группе.
+func (u *UseCase) RotateJoinCode(userId, groupId int) (string, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	_, res, err := u.groupResource(groupId, uow)
+	if err != nil {
+		return "", err
+	} else if !u.policy.CanEdit(userId, res) {
+		return "", u.policy.Deny(res)
+	}
+
+	code, err := newJoinCode()
+	if err != nil {
+		return "", err
+	}
+	if err = uow.GetGroupsRepoWriter().SetGroupJoinCode(groupId, code); err != nil {
+		return "", u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	return code, nil
+}
+
+// JoinGroup добавляет пользователя в группу учеником по коду вступления
+func (u *UseCase) JoinGroup(userId int, code string) (entity.Group, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return entity.Group{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	group, err := uow.GetGroupsRepoReader().GetGroupByJoinCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	roles, err := uow.GetGroupsRepoReader().GetGroupRoles(group.Id)
+	if err != nil {
+		return entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	if roles[userId] != "" {
+		return entity.Group{}, usecase.NewAlreadyExistsError("group member", userId)
+	}
+
+	if err = uow.GetGroupsRepoWriter().InsertGroupMember(group.Id, userId, entity.StudentRole); err != nil {
+		return entity.Group{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return entity.Group{}, usecase.NewInternalError(err)
+	}
+	group.JoinCode = ""
+	group.MembersCount++
+	return group, nil
+}
+
+// LeaveGroup - ученик выходит из группы. Преподаватель не может выйти, только удалить группу.
+func (u *UseCase) LeaveGroup(userId, groupId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	_, res, err := u.groupResource(groupId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanView(userId, res) {
+		return u.policy.Deny(res)
+	} else if res.OwnerId == userId {
+		return usecase.NewInvalidDataError("group", errors.New("teacher can not leave own group"))
+	}
+
+	if err = uow.GetGroupsRepoWriter().DeleteGroupMember(groupId, userId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// KickMember исключает ученика из группы
+func (u *UseCase) KickMember(userId, groupId, memberId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	_, res, err := u.groupResource(groupId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	} else if res.Roles[memberId] == entity.TeacherRole {
+		return usecase.NewInvalidDataError("group", errors.New("teacher can not be kicked"))
+	}
+
+	if err = uow.GetGroupsRepoWriter().DeleteGroupMember(groupId, memberId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) GetGroupMembers(userId, groupId int) ([]entity.GroupMember, error) {
+	_, res, err := u.groupResource(groupId, nil)
+	if err != nil {
+		return []entity.GroupMember{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.GroupMember{}, u.policy.Deny(res)
+	}
+
+	members, err := u.groupsRepoRead.GetGroupMembers(groupId)
+	if err != nil {
+		return []entity.GroupMember{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return members, nil
+}
+
+// GetGroupMaterials возвращает модули и категории группы. Участники видят их,
+// даже если они приватные: доступ выдается через роли в GetModuleRoles и GetCategoryRoles.
+func (u *UseCase) GetGroupMaterials(userId, groupId int) (entity.GroupMaterials, error) {
+	_, res, err := u.groupResource(groupId, nil)
+	if err != nil {
+		return entity.GroupMaterials{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.GroupMaterials{}, u.policy.Deny(res)
+	}
+
+	modules, err := u.groupsRepoRead.GetGroupModules(groupId)
+	if err != nil {
+		return entity.GroupMaterials{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	categories, err := u.groupsRepoRead.GetGroupCategories(groupId)
+	if err != nil {
+		return entity.GroupMaterials{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return entity.GroupMaterials{Modules: modules, Categories: categories}, nil
+}
+
+// checkGroupMaterial проверяет, что преподаватель может открыть материал группе:
+// он должен его видеть, а приватный материал открывает только его владелец
+func (u *UseCase) checkGroupMaterial(userId int, res usecase.Resource) error {
+	if !u.policy.CanView(userId, res) {
+		return u.policy.Deny(res)
+	} else if res.Private && !u.policy.CanShare(userId, res) {
+		return u.policy.Deny(res)
+	}
+	return nil
+}
+
+func (u *UseCase) AttachModuleToGroup(userId, groupId, moduleId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	if err := u.checkGroupEditor(userId, groupId, uow); err != nil {
+		return err
+	}
+	_, moduleRes, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return err
+	} else if err = u.checkGroupMaterial(userId, moduleRes); err != nil {
+		return err
+	}
+
+	modules, err := uow.GetGroupsRepoReader().GetGroupModules(groupId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if slices.ContainsFunc(modules, func(m entity.Module) bool { return m.Id == moduleId }) {
+		return usecase.NewAlreadyExistsError("module", moduleId)
+	}
+
+	if err = uow.GetGroupsRepoWriter().InsertGroupModule(groupId, moduleId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) DetachModuleFromGroup(userId, groupId, moduleId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	if err := u.checkGroupEditor(userId, groupId, uow); err != nil {
+		return err
+	}
+	if err := uow.GetGroupsRepoWriter().DeleteGroupModule(groupId, moduleId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err := uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) AttachCategoryToGroup(userId, groupId, categoryId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	if err := u.checkGroupEditor(userId, groupId, uow); err != nil {
+		return err
+	}
+	_, categoryRes, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return err
+	} else if err = u.checkGroupMaterial(userId, categoryRes); err != nil {
+		return err
+	}
+
+	categories, err := uow.GetGroupsRepoReader().GetGroupCategories(groupId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if slices.ContainsFunc(categories, func(c entity.Category) bool { return c.Id == categoryId }) {
+		return usecase.NewAlreadyExistsError("category", categoryId)
+	}
+
+	if err = uow.GetGroupsRepoWriter().InsertGroupCategory(groupId, categoryId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) DetachCategoryFromGroup(userId, groupId, categoryId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.groupsMutex.Lock()
+	defer u.groupsMutex.Unlock()
+
+	if err := u.checkGroupEditor(userId, groupId, uow); err != nil {
+		return err
+	}
+	if err := uow.GetGroupsRepoWriter().DeleteGroupCategory(groupId, categoryId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err := uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) checkGroupEditor(userId, groupId int, uow uow.UnitOfWork) error {
+	_, res, err := u.groupResource(groupId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+	return nil
+}
+
+// GetGroupResults возвращает преподавателю результаты учеников по материалам группы
+func (u *UseCase) GetGroupResults(userId, groupId int) (entity.GroupResults, error) {
+	_, res, err := u.groupResource(groupId, nil)
+	if err != nil {
+		return entity.GroupResults{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return entity.GroupResults{}, u.policy.Deny(res)
+	}
+
+	modulesResults, err := u.groupsRepoRead.GetGroupModulesResults(groupId)
+	if err != nil {
+		return entity.GroupResults{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	categoriesResults, err := u.groupsRepoRead.GetGroupCategoriesResults(groupId)
+	if err != nil {
+		return entity.GroupResults{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return entity.GroupResults{Modules: modulesResults, Categories: categoriesResults}, nil
+}
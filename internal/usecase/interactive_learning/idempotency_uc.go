@@ -0,0 +1,67 @@
+package interactivelearning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"time"
+)
+
+// время, в течение которого повторный запрос с тем же ключом возвращает исходный ответ
+const idempotencyKeyRetention = 24 * time.Hour
+
+const maxIdempotencyKeyLen = 255
+
+func requestHash(request any) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// возвращает сохраненный ответ, если запрос с этим ключом уже выполнялся пользователем
+func (u *UseCase) getIdempotentResponse(userId int, key, kind, hash string, uow uow.UnitOfWork) (entity.IdempotentResponse, bool, error) {
+	if len(key) > maxIdempotencyKeyLen {
+		return entity.IdempotentResponse{}, false, usecase.NewInvalidDataError("idempotency key", errors.New("key is too long"))
+	}
+
+	stored, err := uow.GetIdempotencyRepoReader().GetIdempotencyKey(userId, key, time.Now().Add(-idempotencyKeyRetention))
+	if err != nil {
+		if errors.Is(err, repo.NoSuchRecordToSelect) {
+			return entity.IdempotentResponse{}, false, nil
+		}
+		return entity.IdempotentResponse{}, false, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if stored.Kind != kind || stored.RequestHash != hash {
+		return entity.IdempotentResponse{}, false, usecase.NewConflictError("idempotency key", errors.New("key was already used with another request"))
+	}
+	return stored.Response, true, nil
+}
+
+func (u *UseCase) saveIdempotentResponse(userId int, key, kind, hash string, response entity.IdempotentResponse, uow uow.UnitOfWork) error {
+	now := time.Now()
+	if err := uow.GetIdempotencyRepoWriter().DeleteExpiredIdempotencyKeys(now.Add(-idempotencyKeyRetention)); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	err := uow.GetIdempotencyRepoWriter().InsertIdempotencyKey(entity.IdempotencyKey{
+		Owner:       userId,
+		Key:         key,
+		Kind:        kind,
+		RequestHash: hash,
+		Response:    response,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	"interactive_learning/internal/usecase"
+	"interactive_learning/internal/utils/cardsimport"
+	"io"
+)
+
+// ImportModule разбирает CSV/TSV файл с карточками. В режиме DryRun модуль не создается,
+// возвращается предпросмотр карточек и ошибки по строкам.
+func (u *UseCase) ImportModule(userId int, req httputils.ImportModuleReq, file io.Reader) (entity.ModuleImport, error) {
+	separator, err := cardsimport.ParseSeparator(req.Separator)
+	if err != nil {
+		return entity.ModuleImport{}, usecase.NewInvalidDataError("module import", err)
+	}
+
+	cards, rowErrors, err := cardsimport.Parse(file, cardsimport.Options{
+		Separator:      separator,
+		HasHeader:      req.HasHeader,
+		TermColumn:     req.TermColumn,
+		DefColumn:      req.DefColumn,
+		TermLangColumn: req.TermLangColumn,
+		DefLangColumn:  req.DefLangColumn,
+		TermLang:       req.TermLang,
+		DefLang:        req.DefLang,
+	})
+	if err != nil {
+		return entity.ModuleImport{}, usecase.NewInvalidDataError("module import", err)
+	}
+
+	preview := entity.ModuleImport{Cards: cards, Errors: rowErrors}
+	if req.DryRun {
+		return preview, nil
+	}
+
+	if len(rowErrors) > 0 && !req.SkipInvalid {
+		return preview, usecase.NewInvalidDataError("module import", errors.New("file contains invalid rows"))
+	} else if len(cards) == 0 {
+		return preview, usecase.NewInvalidDataError("module import", errors.New("file contains no cards"))
+	} else if req.Name == "" {
+		return preview, usecase.NewInvalidDataError("module import", errors.New("empty module name"))
+	}
+
+	preview.ModuleId, preview.CardsIds, err = u.InsertModule(entity.ModuleToCreate{
+		Name:    req.Name,
+		Cards:   cards,
+		OwnerId: userId,
+		Type:    req.Type,
+	})
+	if err != nil {
+		return entity.ModuleImport{}, err
+	}
+	return preview, nil
+}
@@ -4,6 +4,7 @@ import (
 	errors_mapper "interactive_learning/internal/mappers/errors"
 	"interactive_learning/internal/repo"
 	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
 	"sync"
 )
 
@@ -21,6 +22,20 @@ type UseCase struct {
 	modulesResultsRepoRead         repo.ModulesResultsRepoRead
 	categoryModulesResultsRepoRead repo.CategoryModulesResultsRepoRead
 	selectedRepoRead               repo.SelectedRepoRead
+	attachmentsRepoRead            repo.AttachmentsRepoRead
+	revisionsRepoRead              repo.RevisionsRepoRead
+	tagsRepoRead                   repo.TagsRepoRead
+	grantsRepoRead                 repo.GrantsRepoRead
+	groupsRepoRead                 repo.GroupsRepoRead
+	assignmentsRepoRead            repo.AssignmentsRepoRead
+	followsRepoRead                repo.FollowsRepoRead
+	ratingsRepoRead                repo.RatingsRepoRead
+	commentsRepoRead               repo.CommentsRepoRead
+	moderationRepoRead             repo.ModerationRepoRead
+	notificationsRepoRead          repo.NotificationsRepoRead
+
+	blobStore repo.BlobStore
+	policy    *usecase.Policy
 
 	usersMutex                  sync.Mutex
 	cardMutex                   sync.Mutex
@@ -32,6 +47,10 @@ type UseCase struct {
 	modulesResultsMutex         sync.Mutex
 	categoryModulesResultsMutex sync.Mutex
 	selectedMutex               sync.Mutex
+	attachmentsMutex            sync.Mutex
+	groupsMutex                 sync.Mutex
+	feedbackMutex               sync.Mutex
+	moderationMutex             sync.Mutex
 
 	errorsMapper *errors_mapper.DomainsErrorsMapper
 }
@@ -48,6 +67,19 @@ func New(unitOfWorkFactory func() uow.UnitOfWork,
 	modulesResultsRepoRead repo.ModulesResultsRepoRead,
 	categoryModulesResultsRepoRead repo.CategoryModulesResultsRepoRead,
 	selectedRepoRead repo.SelectedRepoRead,
+	attachmentsRepoRead repo.AttachmentsRepoRead,
+	revisionsRepoRead repo.RevisionsRepoRead,
+	tagsRepoRead repo.TagsRepoRead,
+	grantsRepoRead repo.GrantsRepoRead,
+	groupsRepoRead repo.GroupsRepoRead,
+	assignmentsRepoRead repo.AssignmentsRepoRead,
+	followsRepoRead repo.FollowsRepoRead,
+	ratingsRepoRead repo.RatingsRepoRead,
+	commentsRepoRead repo.CommentsRepoRead,
+	moderationRepoRead repo.ModerationRepoRead,
+	notificationsRepoRead repo.NotificationsRepoRead,
+	blobStore repo.BlobStore,
+	policy *usecase.Policy,
 	errorsMapper *errors_mapper.DomainsErrorsMapper) *UseCase {
 
 	return &UseCase{unitOfWorkFactory: unitOfWorkFactory,
@@ -62,6 +94,19 @@ func New(unitOfWorkFactory func() uow.UnitOfWork,
 		modulesResultsRepoRead:         modulesResultsRepoRead,
 		categoryModulesResultsRepoRead: categoryModulesResultsRepoRead,
 		selectedRepoRead:               selectedRepoRead,
+		attachmentsRepoRead:            attachmentsRepoRead,
+		revisionsRepoRead:              revisionsRepoRead,
+		tagsRepoRead:                   tagsRepoRead,
+		grantsRepoRead:                 grantsRepoRead,
+		groupsRepoRead:                 groupsRepoRead,
+		assignmentsRepoRead:            assignmentsRepoRead,
+		followsRepoRead:                followsRepoRead,
+		ratingsRepoRead:                ratingsRepoRead,
+		commentsRepoRead:               commentsRepoRead,
+		moderationRepoRead:             moderationRepoRead,
+		notificationsRepoRead:          notificationsRepoRead,
+		blobStore:                      blobStore,
+		policy:                         policy,
 		errorsMapper:                   errorsMapper,
 	}
 }
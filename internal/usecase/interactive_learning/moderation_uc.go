@@ -0,0 +1,352 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"unicode/utf8"
+)
+
+func validateReport(req httputils.ReportReq) error {
+	switch req.Reason {
+	case entity.SpamReason, entity.AbuseReason, entity.CopyrightReason, entity.InappropriateReason, entity.OtherReason:
+	default:
+		return usecase.NewInvalidDataError("report", errors.New("unknown reason "+req.Reason))
+	}
+	return validateModerationNote(req.Note)
+}
+
+func validateModerationNote(note string) error {
+	if utf8.RuneCountInString(note) > entity.MaxReportNoteLength {
+		return usecase.NewInvalidDataError("report", errors.New("note is too long"))
+	}
+	return nil
+}
+
+// moderationResource проверяет, администратор ли пользователь
+func (u *UseCase) moderationResource(userId int, uow uow.UnitOfWork) (usecase.Resource, error) {
+	usersRepoRead := u.usersRepoRead
+	if uow != nil {
+		usersRepoRead = uow.GetUsersRepoReader()
+	}
+
+	isAdmin, err := usersRepoRead.IsAdmin(userId)
+	if err != nil {
+		return usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return usecase.ModerationResource(userId, isAdmin), nil
+}
+
+// reportTarget загружает объект жалобы для проверки доступа, владелец объекта - res.OwnerId
+func (u *UseCase) reportTarget(targetKind string, targetId int, uow uow.UnitOfWork) (usecase.Resource, error) {
+	var res usecase.Resource
+	var err error
+	switch targetKind {
+	case entity.ReportModule:
+		_, res, err = u.moduleResource(targetId, uow)
+	case entity.ReportCategory:
+		_, res, err = u.categoryResource(targetId, uow)
+	case entity.ReportCard:
+		_, res, err = u.cardResource(targetId, uow)
+	case entity.ReportComment:
+		_, res, err = u.commentResource(targetId, uow)
+	default:
+		return usecase.Resource{}, usecase.NewInvalidDataError("report", errors.New("unknown target "+targetKind))
+	}
+	return res, err
+}
+
+// hiddenTarget - что скрывается по жалобе. Карточки отдельно не скрываются,
+// вместо них скрывается модуль.
+func (u *UseCase) hiddenTarget(targetKind string, targetId int, uow uow.UnitOfWork) (string, int, error) {
+	if targetKind != entity.ReportCard {
+		return targetKind, targetId, nil
+	}
+	moduleId, err := uow.GetCardRepoReader().GetParentModuleId(targetId)
+	if err != nil {
+		return "", 0, u.errorsMapper.DBErrorToApp(err)
+	}
+	return entity.ReportModule, moduleId, nil
+}
+
+func (u *UseCase) setHidden(targetKind string, targetId int, hidden bool, uow uow.UnitOfWork) error {
+	var err error
+	switch targetKind {
+	case entity.ReportModule:
+		err = uow.GetModuleRepoWriter().SetModuleHidden(targetId, hidden)
+	case entity.ReportCategory:
+		err = uow.GetCategoryRepoWriter().SetCategoryHidden(targetId, hidden)
+	case entity.ReportComment:
+		err = uow.GetCommentsRepoWriter().SetCommentHidden(targetId, hidden)
+	default:
+		return usecase.NewInvalidDataError("moderation", errors.New("can not hide "+targetKind))
+	}
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	return nil
+}
+
+// moderate скрывает или возвращает объект, уведомляет владельца и пишет действие в журнал
+func (u *UseCase) moderate(adminId int, action, targetKind string, targetId int, reportId *int, note string, uow uow.UnitOfWork) error {
+	res, err := u.reportTarget(targetKind, targetId, uow)
+	if err != nil {
+		return err
+	}
+	if err = u.setHidden(targetKind, targetId, action == entity.HideAction, uow); err != nil {
+		return err
+	}
+
+	kind := entity.ContentHiddenNotification
+	if action == entity.RestoreAction {
+		kind = entity.ContentRestoredNotification
+	}
+	err = uow.GetNotificationsRepoWriter().InsertNotification(entity.Notification{
+		UserId: res.OwnerId, Kind: kind, TargetKind: targetKind, TargetId: targetId, Text: note})
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	err = uow.GetModerationRepoWriter().InsertModerationEntry(entity.ModerationEntry{
+		AdminId: adminId, Action: action, TargetKind: targetKind, TargetId: targetId, ReportId: reportId, Note: note})
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	return nil
+}
+
+// Report принимает жалобу на модуль, категорию, карточку или комментарий
+func (u *UseCase) Report(userId int, targetKind string, targetId int, req httputils.ReportReq) (int, error) {
+	if err := validateReport(req); err != nil {
+		return -1, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moderationMutex.Lock()
+	defer u.moderationMutex.Unlock()
+
+	res, err := u.reportTarget(targetKind, targetId, uow)
+	if err != nil {
+		return -1, err
+	} else if !u.policy.CanReport(userId, res) {
+		return -1, u.policy.Deny(res)
+	}
+
+	hasReport, err := uow.GetModerationRepoReader().HasOpenReport(targetKind, targetId, userId)
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	} else if hasReport {
+		return -1, usecase.NewAlreadyExistsError("report", targetId)
+	}
+
+	err = uow.GetModerationRepoWriter().InsertReport(entity.Report{
+		TargetKind: targetKind, TargetId: targetId, ReporterId: userId, Reason: req.Reason, Note: req.Note})
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	id, err := uow.GetModerationRepoReader().GetLastInsertedReportId()
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.Commit(); err != nil {
+		return -1, usecase.NewInternalError(err)
+	}
+	return id, nil
+}
+
+// GetReports возвращает очередь жалоб, по умолчанию - открытые
+func (u *UseCase) GetReports(userId int, status string, limit, offset int) ([]entity.Report, error) {
+	switch status {
+	case "":
+		status = entity.OpenReport
+	case entity.OpenReport, entity.ResolvedReport, entity.DismissedReport:
+	default:
+		return []entity.Report{}, usecase.NewInvalidDataError("reports", errors.New("unknown status "+status))
+	}
+	limit, err := pageLimit("reports", limit, offset)
+	if err != nil {
+		return []entity.Report{}, err
+	}
+
+	res, err := u.moderationResource(userId, nil)
+	if err != nil {
+		return []entity.Report{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.Report{}, u.policy.Deny(res)
+	}
+
+	reports, err := u.moderationRepoRead.GetReports(status, limit, offset)
+	if err != nil {
+		return []entity.Report{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return reports, nil
+}
+
+// ResolveReport закрывает жалобу. hide скрывает объект и закрывает все открытые
+// жалобы на него, dismiss отклоняет только эту жалобу.
+func (u *UseCase) ResolveReport(userId, reportId int, req httputils.ModerationReq) error {
+	if req.Action != entity.HideAction && req.Action != entity.DismissAction {
+		return usecase.NewInvalidDataError("moderation", errors.New("unknown action "+req.Action))
+	}
+	if err := validateModerationNote(req.Note); err != nil {
+		return err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+	u.moderationMutex.Lock()
+	defer u.moderationMutex.Unlock()
+
+	res, err := u.moderationResource(userId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	report, err := uow.GetModerationRepoReader().GetReportById(reportId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	} else if report.Status != entity.OpenReport {
+		return usecase.NewConflictError("report", errors.New("report is already closed"))
+	}
+
+	moderationRepoWrite := uow.GetModerationRepoWriter()
+	if req.Action == entity.DismissAction {
+		if err = moderationRepoWrite.ResolveReport(reportId, userId, entity.DismissedReport); err != nil {
+			return u.errorsMapper.DBErrorToApp(err)
+		}
+		err = moderationRepoWrite.InsertModerationEntry(entity.ModerationEntry{AdminId: userId, Action: entity.DismissAction,
+			TargetKind: report.TargetKind, TargetId: report.TargetId, ReportId: &report.Id, Note: req.Note})
+		if err != nil {
+			return u.errorsMapper.DBErrorToApp(err)
+		}
+	} else {
+		var hiddenKind string
+		var hiddenId int
+		if hiddenKind, hiddenId, err = u.hiddenTarget(report.TargetKind, report.TargetId, uow); err != nil {
+			return err
+		}
+		if err = u.moderate(userId, entity.HideAction, hiddenKind, hiddenId, &report.Id, req.Note, uow); err != nil {
+			return err
+		}
+		err = moderationRepoWrite.ResolveTargetReports(report.TargetKind, report.TargetId, userId, entity.ResolvedReport)
+		if err != nil {
+			return u.errorsMapper.DBErrorToApp(err)
+		}
+		if hiddenKind != report.TargetKind {
+			err = moderationRepoWrite.ResolveTargetReports(hiddenKind, hiddenId, userId, entity.ResolvedReport)
+			if err != nil {
+				return u.errorsMapper.DBErrorToApp(err)
+			}
+		}
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// RestoreContent возвращает скрытый модератором модуль, категорию или комментарий
+func (u *UseCase) RestoreContent(userId int, req httputils.RestoreReq) error {
+	if err := validateModerationNote(req.Note); err != nil {
+		return err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+	u.feedbackMutex.Lock()
+	defer u.feedbackMutex.Unlock()
+	u.moderationMutex.Lock()
+	defer u.moderationMutex.Unlock()
+
+	res, err := u.moderationResource(userId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanEdit(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = u.moderate(userId, entity.RestoreAction, req.TargetKind, req.TargetId, nil, req.Note, uow); err != nil {
+		return err
+	}
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) GetModerationLog(userId, limit, offset int) ([]entity.ModerationEntry, error) {
+	limit, err := pageLimit("moderation log", limit, offset)
+	if err != nil {
+		return []entity.ModerationEntry{}, err
+	}
+
+	res, err := u.moderationResource(userId, nil)
+	if err != nil {
+		return []entity.ModerationEntry{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.ModerationEntry{}, u.policy.Deny(res)
+	}
+
+	entries, err := u.moderationRepoRead.GetModerationLog(limit, offset)
+	if err != nil {
+		return []entity.ModerationEntry{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return entries, nil
+}
+
+func (u *UseCase) GetNotifications(userId, limit, offset int) ([]entity.Notification, error) {
+	limit, err := pageLimit("notifications", limit, offset)
+	if err != nil {
+		return []entity.Notification{}, err
+	}
+
+	notifications, err := u.notificationsRepoRead.GetNotifications(userId, limit, offset)
+	if err != nil {
+		return []entity.Notification{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return notifications, nil
+}
+
+func (u *UseCase) MarkNotificationRead(userId, notificationId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	if err := uow.GetNotificationsRepoWriter().MarkNotificationRead(notificationId, userId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err := uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
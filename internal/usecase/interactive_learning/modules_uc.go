@@ -2,26 +2,21 @@ package interactivelearning
 
 import (
 	"errors"
+	"fmt"
 	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/uow"
 	"interactive_learning/internal/usecase"
+	"unicode/utf8"
 )
 
-func (u *UseCase) GetModulesWithSimilarName(name string, limit, offset, userId int) ([]entity.Module, error) {
-	modules, err := u.moduleRepoRead.GetModulesWithSimilarName(name, limit, offset)
+func (u *UseCase) GetModulesWithSimilarName(name, tag string, limit, offset, userId int) ([]entity.Module, error) {
+	modules, err := u.moduleRepoRead.GetModulesWithSimilarName(name, normalizeTag(tag), limit, offset)
 	if err != nil {
 		return nil, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	publicModules := []entity.Module{}
-	for _, module := range modules {
-		if module.Type == entity.PrivateModule && module.OwnerId == userId {
-			publicModules = append(publicModules, module)
-		} else if module.Type == entity.PublicModule {
-			publicModules = append(publicModules, module)
-		}
-	}
-
-	return publicModules, nil
+	return u.visibleModules(modules, userId)
 }
 
 func (u *UseCase) GetModulesByUser(ownerId int, withCards bool, userId int) ([]entity.Module, error) {
@@ -30,14 +25,9 @@ func (u *UseCase) GetModulesByUser(ownerId int, withCards bool, userId int) ([]e
 		return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	if ownerId != userId {
-		publicModules := []entity.Module{}
-		for _, module := range modules {
-			if module.Type == entity.PublicModule {
-				publicModules = append(publicModules, module)
-			}
-		}
-		modules = publicModules
+	modules, err = u.listedModules(modules, userId)
+	if err != nil {
+		return []entity.Module{}, err
 	}
 
 	if !withCards {
@@ -45,9 +35,9 @@ func (u *UseCase) GetModulesByUser(ownerId int, withCards bool, userId int) ([]e
 	}
 
 	for i := range modules {
-		cards, err := u.cardsRepoRead.GetCardsByModule(modules[i].Id)
+		cards, err := u.getCardsByModule(modules[i].Id)
 		if err != nil {
-			return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
+			return []entity.Module{}, err
 		}
 		modules[i].Cards = cards
 	}
@@ -56,18 +46,16 @@ func (u *UseCase) GetModulesByUser(ownerId int, withCards bool, userId int) ([]e
 }
 
 func (u *UseCase) GetModuleById(moduleId, userId int) (entity.Module, error) {
-	module, err := u.moduleRepoRead.GetModuleById(moduleId)
+	module, res, err := u.moduleResource(moduleId, nil)
 	if err != nil {
-		return entity.Module{}, u.errorsMapper.DBErrorToApp(err)
-	}
-
-	if module.Type == entity.PrivateModule && userId != module.OwnerId {
-		return entity.Module{}, usecase.NewNotAvailableError("module", moduleId)
+		return entity.Module{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.Module{}, u.policy.Deny(res)
 	}
 
-	cards, err := u.cardsRepoRead.GetCardsByModule(moduleId)
+	cards, err := u.getCardsByModule(moduleId)
 	if err != nil {
-		return entity.Module{}, u.errorsMapper.DBErrorToApp(err)
+		return entity.Module{}, err
 	}
 	module.Cards = cards
 	return module, nil
@@ -77,19 +65,17 @@ func (u *UseCase) GetModulesByIds(modulesIds []int, isFull bool, userId int) ([]
 	modules := []entity.Module{}
 
 	for _, moduleId := range modulesIds {
-		module, err := u.moduleRepoRead.GetModuleById(moduleId)
+		module, res, err := u.moduleResource(moduleId, nil)
 		if err != nil {
-			return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
-		}
-
-		if module.Type == entity.PrivateModule && userId != module.OwnerId {
-			return []entity.Module{}, usecase.NewNotAvailableError("module", moduleId)
+			return []entity.Module{}, err
+		} else if !u.policy.CanView(userId, res) {
+			return []entity.Module{}, u.policy.Deny(res)
 		}
 
 		if isFull {
-			cards, err := u.cardsRepoRead.GetCardsByModule(moduleId)
+			cards, err := u.getCardsByModule(moduleId)
 			if err != nil {
-				return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
+				return []entity.Module{}, err
 			}
 			module.Cards = cards
 		}
@@ -108,8 +94,12 @@ func (u *UseCase) GetModuleOwnerId(moduleId int) (int, error) {
 	return ownerId, nil
 }
 
-func (u *UseCase) GetPopularModules(limit, offset int) ([]entity.PopularModule, error) {
-	popularModules, err := u.moduleRepoRead.GetPopularModules(limit, offset)
+func (u *UseCase) GetPopularModules(limit, offset int, sortBy string) ([]entity.PopularModule, error) {
+	sortBy, err := popularSort(sortBy)
+	if err != nil {
+		return []entity.PopularModule{}, err
+	}
+	popularModules, err := u.moduleRepoRead.GetPopularModules(limit, offset, sortBy)
 	if err != nil {
 		return []entity.PopularModule{}, u.errorsMapper.DBErrorToApp(err)
 	}
@@ -124,172 +114,247 @@ func (u *UseCase) InsertModule(module entity.ModuleToCreate) (int, []int, error)
 	defer uow.Rollback()
 
 	u.moduleMutex.Lock()
-	defer u.moduleMutex.Unlock()
+	u.cardMutex.Lock()
+	defer func() {
+		u.moduleMutex.Unlock()
+		u.cardMutex.Unlock()
+	}()
 
-	err := uow.GetModuleRepoWriter().InsertModule(module)
+	id, insertIds, err := u.insertModule(module, uow)
 	if err != nil {
-		return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
+		return -1, []int{}, err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return -1, []int{}, usecase.NewInternalError(err)
 	}
-	insertIds, err := u.InsertCards(entity.CardsToAdd{Cards: module.Cards, ParentModule: module.Id})
+
+	return id, insertIds, nil
+}
+
+// создает модуль вместе с карточками в транзакции вызывающего
+func (u *UseCase) insertModule(module entity.ModuleToCreate, uow uow.UnitOfWork) (int, []int, error) {
+	if module.Type != entity.PublicModule && module.Type != entity.PrivateModule && module.Type != entity.UnlistedModule {
+		return -1, []int{}, usecase.NewInvalidDataError("module", errors.New("invalid type"))
+	}
+	if err := validateInfo("module", entity.Info{Description: module.Description, Difficulty: module.Difficulty}); err != nil {
+		return -1, []int{}, err
+	}
+	tags, err := normalizeTags(module.Tags)
 	if err != nil {
 		return -1, []int{}, err
 	}
+
+	err = uow.GetModuleRepoWriter().InsertModule(module)
+	if err != nil {
+		return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
+	}
 	id, err := uow.GetModuleRepoReader().GetLastInsertedModuleId()
 	if err != nil {
 		return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
 	}
-
-	if err = uow.Commit(); err != nil {
-		return -1, []int{}, usecase.NewInternalError(err)
+	if len(tags) > 0 {
+		if err = uow.GetTagsRepoWriter().SetModuleTags(id, tags); err != nil {
+			return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
+		}
+	}
+	if module.Type == entity.UnlistedModule {
+		if err = u.ensureModuleShareSlug(id, uow); err != nil {
+			return -1, []int{}, err
+		}
 	}
 
+	insertIds, err := u.insertCards(entity.CardsToAdd{Cards: module.Cards, ParentModule: id}, uow)
+	if err != nil {
+		return -1, []int{}, err
+	}
 	return id, insertIds, nil
 }
 
-func (u *UseCase) RenameModule(userId, moduleId int, newName string) error {
+// validateInfo проверяет описание и сложность модуля или категории
+func validateInfo(object string, info entity.Info) error {
+	if utf8.RuneCountInString(info.Description) > entity.MaxDescriptionLen {
+		return usecase.NewInvalidDataError(object, fmt.Errorf("description is longer than %d characters", entity.MaxDescriptionLen))
+	}
+	if info.Difficulty < entity.NoDifficulty || info.Difficulty > entity.MaxDifficulty {
+		return usecase.NewInvalidDataError(object, fmt.Errorf("difficulty must be from %d to %d", entity.NoDifficulty, entity.MaxDifficulty))
+	}
+	return nil
+}
+
+func (u *UseCase) UpdateModuleInfo(userId, moduleId, version int, info entity.Info) (int, error) {
+	if err := validateInfo("module", info); err != nil {
+		return -1, err
+	}
+
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
-		return usecase.NewInternalError(err)
+		return -1, usecase.NewInternalError(err)
 	}
 	defer uow.Rollback()
 
 	u.moduleMutex.Lock()
 	defer u.moduleMutex.Unlock()
 
-	ownerId, err := uow.GetModuleRepoReader().GetModuleOwnerId(moduleId)
+	module, res, err := u.moduleResource(moduleId, uow)
 	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
-	}
-	if ownerId != userId {
-		return usecase.NewNotAvailableError("module", moduleId)
+		return -1, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return -1, u.policy.Deny(res)
+	} else if module.Version != version {
+		return -1, usecase.NewVersionMismatchError("module", module.Version, module)
 	}
 
-	err = uow.GetModuleRepoWriter().RenameModule(moduleId, newName)
-	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
+	newVersion, err := uow.GetModuleRepoWriter().UpdateModuleInfo(moduleId, version, info)
+	if errors.Is(err, repo.NoSuchRecordToUpdate) {
+		return -1, u.moduleVersionMismatch(moduleId, uow)
+	} else if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
 
 	if err = uow.Commit(); err != nil {
-		return usecase.NewInternalError(err)
+		return -1, usecase.NewInternalError(err)
 	}
-	return nil
+	return newVersion, nil
 }
 
-func (u *UseCase) UpdateModuleType(moduleId, newType, userId int) error {
+// RenameModule переименовывает модуль, если его версия все еще version, и возвращает новую версию
+func (u *UseCase) RenameModule(userId, moduleId, version int, newName string) (int, error) {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
-		return usecase.NewInternalError(err)
+		return -1, usecase.NewInternalError(err)
 	}
 	defer uow.Rollback()
 
 	u.moduleMutex.Lock()
-	u.categoryMutex.Lock()
-	u.categoryModulesMutex.Lock()
-	defer func() {
-		u.moduleMutex.Unlock()
-		u.categoryMutex.Unlock()
-		u.categoryModulesMutex.Unlock()
-	}()
+	defer u.moduleMutex.Unlock()
 
-	module, err := uow.GetModuleRepoReader().GetModuleById(moduleId)
+	module, res, err := u.moduleResource(moduleId, uow)
 	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
-	} else if module.OwnerId != userId {
-		return usecase.NewNotAvailableError("module", moduleId)
-	} else if module.Type == newType {
-		return usecase.NewChangeTypeError("module", errors.New("module already has this type"))
+		return -1, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return -1, u.policy.Deny(res)
+	} else if module.Version != version {
+		return -1, usecase.NewVersionMismatchError("module", module.Version, module)
+	}
+
+	// гонку между чтением и записью ловит условие на версию в UPDATE
+	newVersion, err := uow.GetModuleRepoWriter().RenameModule(moduleId, version, newName)
+	if errors.Is(err, repo.NoSuchRecordToUpdate) {
+		return -1, u.moduleVersionMismatch(moduleId, uow)
+	} else if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	err = uow.GetModuleRepoWriter().UpdateModuleType(moduleId, newType)
+	err = uow.GetRevisionsRepoWriter().InsertModuleRevision(entity.ModuleRevision{
+		ModuleId: moduleId,
+		EditorId: userId,
+		Action:   entity.RenameRevision,
+		OldName:  module.Name,
+		NewName:  newName,
+		OldType:  module.Type,
+		NewType:  module.Type,
+	})
 	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
-	}
-
-	switch newType {
-	case entity.PrivateModule:
-		categories, err := uow.GetCategoryModulesRepoReader().GetCategoriesContainsModule(moduleId)
-		if err != nil {
-			return u.errorsMapper.DBErrorToApp(err)
-		}
-		for _, category := range categories {
-			if category.Type == entity.PublicCategory {
-				category.Type = entity.PrivateCategory
-			}
-			category.Type++
-			err = uow.GetCategoryRepoWriter().UpdateCategoryType(category.Id, category.Type)
-			if err != nil {
-				return u.errorsMapper.DBErrorToApp(err)
-			}
-		}
-	case entity.PublicModule:
-		categories, err := uow.GetCategoryModulesRepoReader().GetCategoriesContainsModule(moduleId)
-		if err != nil {
-			return u.errorsMapper.DBErrorToApp(err)
-		}
-		for _, category := range categories {
-			err = uow.GetCategoryRepoWriter().TurnDownCategoryType(category.Id)
-			if err != nil {
-				return u.errorsMapper.DBErrorToApp(err)
-			}
-		}
-	default:
-		return usecase.NewChangeTypeError("module", errors.New("Invalid type"))
+		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
 
 	if err = uow.Commit(); err != nil {
-		return usecase.NewInternalError(err)
+		return -1, usecase.NewInternalError(err)
 	}
-	return nil
+	return newVersion, nil
 }
 
-func (u *UseCase) DeleteModule(userId int, moduleId int) error {
+func (u *UseCase) UpdateModuleType(moduleId, newType, userId, version int) (int, error) {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
-		return usecase.NewInternalError(err)
+		return -1, usecase.NewInternalError(err)
 	}
 	defer uow.Rollback()
 
 	u.moduleMutex.Lock()
-	u.selectedMutex.Lock()
-	defer func() {
-		u.moduleMutex.Unlock()
-		u.selectedMutex.Unlock()
-	}()
+	defer u.moduleMutex.Unlock()
 
-	ownerId, err := uow.GetModuleRepoReader().GetModuleOwnerId(moduleId)
+	module, res, err := u.moduleResource(moduleId, uow)
 	if err != nil {
-		return usecase.NewInternalError(err)
+		return -1, err
+	} else if !u.policy.CanShare(userId, res) {
+		return -1, u.policy.Deny(res)
+	} else if module.Version != version {
+		return -1, usecase.NewVersionMismatchError("module", module.Version, module)
+	} else if module.Type == newType {
+		return -1, usecase.NewChangeTypeError("module", errors.New("module already has this type"))
+	} else if newType != entity.PublicModule && newType != entity.PrivateModule && newType != entity.UnlistedModule {
+		return -1, usecase.NewChangeTypeError("module", errors.New("Invalid type"))
 	}
-	if ownerId != userId {
-		return usecase.NewAlreadyExistsError("module", moduleId)
+
+	newVersion, err := uow.GetModuleRepoWriter().UpdateModuleType(moduleId, version, newType)
+	if errors.Is(err, repo.NoSuchRecordToUpdate) {
+		return -1, u.moduleVersionMismatch(moduleId, uow)
+	} else if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	err = u.deleteCardsToParentModule(moduleId, uow)
-	if err != nil {
-		return err
+	if newType == entity.UnlistedModule {
+		if err = u.ensureModuleShareSlug(moduleId, uow); err != nil {
+			return -1, err
+		}
 	}
 
-	err = u.deleteModuleFromCategories(moduleId, uow)
+	err = uow.GetRevisionsRepoWriter().InsertModuleRevision(entity.ModuleRevision{
+		ModuleId: moduleId,
+		EditorId: userId,
+		Action:   entity.ChangeTypeRevision,
+		OldName:  module.Name,
+		NewName:  module.Name,
+		OldType:  module.Type,
+		NewType:  newType,
+	})
 	if err != nil {
-		return err
+		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	err = u.deleteResultByModuleId(moduleId, uow)
-	if err != nil {
-		return err
+	if err = uow.Commit(); err != nil {
+		return -1, usecase.NewInternalError(err)
 	}
+	return newVersion, nil
+}
 
-	err = u.deleteModuleResFromCategories(moduleId, uow)
+// moduleVersionMismatch объясняет, почему условное обновление не нашло модуль:
+// его версию уже сменил другой запрос
+func (u *UseCase) moduleVersionMismatch(moduleId int, uow uow.UnitOfWork) error {
+	current, err := uow.GetModuleRepoReader().GetModuleById(moduleId)
 	if err != nil {
-		return err
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	return usecase.NewVersionMismatchError("module", current.Version, current)
+}
+
+func (u *UseCase) DeleteModule(userId int, moduleId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
 	}
+	defer uow.Rollback()
 
-	err = uow.GetSelectedRepoWriter().DeleteAllToModule(moduleId)
+	u.moduleMutex.Lock()
+	u.categoryModulesMutex.Lock()
+	defer func() {
+		u.moduleMutex.Unlock()
+		u.categoryModulesMutex.Unlock()
+	}()
+
+	_, res, err := u.moduleResource(moduleId, uow)
 	if err != nil {
-		return u.errorsMapper.DBErrorToApp(err)
+		return err
+	} else if !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
 	}
 
-	err = uow.GetModuleRepoWriter().DeleteModule(moduleId)
+	// карточки, результаты, связи с категориями и избранное остаются до очистки корзины,
+	// модуль в корзине уже не закрывает категории
+
+	err = uow.GetModuleRepoWriter().TrashModule(moduleId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
@@ -0,0 +1,184 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+)
+
+// moduleResource загружает модуль и описывает его для политики доступа.
+// uow может быть nil, тогда чтение идет вне транзакции.
+func (u *UseCase) moduleResource(moduleId int, uow uow.UnitOfWork) (entity.Module, usecase.Resource, error) {
+	moduleRepoRead, grantsRepoRead := u.moduleRepoRead, u.grantsRepoRead
+	if uow != nil {
+		moduleRepoRead, grantsRepoRead = uow.GetModuleRepoReader(), uow.GetGrantsRepoReader()
+	}
+
+	module, err := moduleRepoRead.GetModuleById(moduleId)
+	if err != nil {
+		return entity.Module{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	res := usecase.ModuleResource(module)
+	if res.Roles, err = grantsRepoRead.GetModuleRoles(moduleId); err != nil {
+		return entity.Module{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return module, res, nil
+}
+
+// cardResource загружает модуль карточки: доступ к карточке определяется им
+func (u *UseCase) cardResource(cardId int, uow uow.UnitOfWork) (entity.Module, usecase.Resource, error) {
+	cardsRepoRead := u.cardsRepoRead
+	if uow != nil {
+		cardsRepoRead = uow.GetCardRepoReader()
+	}
+
+	moduleId, err := cardsRepoRead.GetParentModuleId(cardId)
+	if err != nil {
+		return entity.Module{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	module, moduleRes, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return entity.Module{}, usecase.Resource{}, err
+	}
+	res := usecase.CardResource(cardId, module)
+	res.Roles = moduleRes.Roles
+	return module, res, nil
+}
+
+func (u *UseCase) categoryResource(categoryId int, uow uow.UnitOfWork) (entity.Category, usecase.Resource, error) {
+	categoryRepoRead, grantsRepoRead := u.categoryRepoRead, u.grantsRepoRead
+	if uow != nil {
+		categoryRepoRead, grantsRepoRead = uow.GetCategoryRepoReader(), uow.GetGrantsRepoReader()
+	}
+
+	category, err := categoryRepoRead.GetCategoryById(categoryId)
+	if err != nil {
+		return entity.Category{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	res := usecase.CategoryResource(category)
+	if res.Roles, err = grantsRepoRead.GetCategoryRoles(categoryId); err != nil {
+		return entity.Category{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return category, res, nil
+}
+
+// visibleModules оставляет модули, которые пользователь может просматривать
+func (u *UseCase) visibleModules(modules []entity.Module, userId int) ([]entity.Module, error) {
+	return u.filterModules(modules, userId, u.policy.CanView)
+}
+
+// listedModules оставляет модули, которые показываются в списке автора
+func (u *UseCase) listedModules(modules []entity.Module, userId int) ([]entity.Module, error) {
+	return u.filterModules(modules, userId, u.policy.CanList)
+}
+
+// filterModules проверяет модули правилом политики.
+// Роли загружаются только для модулей, закрытых без них.
+func (u *UseCase) filterModules(modules []entity.Module, userId int, can func(int, usecase.Resource) bool) ([]entity.Module, error) {
+	filtered := []entity.Module{}
+	for _, module := range modules {
+		res := usecase.ModuleResource(module)
+		if !can(userId, res) {
+			roles, err := u.grantsRepoRead.GetModuleRoles(module.Id)
+			if err != nil {
+				return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
+			}
+			res.Roles = roles
+		}
+		if can(userId, res) {
+			filtered = append(filtered, module)
+		}
+	}
+	return filtered, nil
+}
+
+// visibleCategories оставляет категории, которые пользователь может просматривать
+func (u *UseCase) visibleCategories(categories []entity.Category, userId int) ([]entity.Category, error) {
+	return u.filterCategories(categories, userId, u.policy.CanView)
+}
+
+// listedCategories оставляет категории, которые показываются в списке автора
+func (u *UseCase) listedCategories(categories []entity.Category, userId int) ([]entity.Category, error) {
+	return u.filterCategories(categories, userId, u.policy.CanList)
+}
+
+func (u *UseCase) filterCategories(categories []entity.Category, userId int, can func(int, usecase.Resource) bool) ([]entity.Category, error) {
+	filtered := []entity.Category{}
+	for _, category := range categories {
+		res := usecase.CategoryResource(category)
+		if !can(userId, res) {
+			roles, err := u.grantsRepoRead.GetCategoryRoles(category.Id)
+			if err != nil {
+				return []entity.Category{}, u.errorsMapper.DBErrorToApp(err)
+			}
+			res.Roles = roles
+		}
+		if can(userId, res) {
+			filtered = append(filtered, category)
+		}
+	}
+	return filtered, nil
+}
+
+// moduleResultResource описывает результат по модулю: кроме владельца его видят
+// преподаватели групп, которым открыт модуль.
+// Результаты по удаленному модулю остаются доступны только их владельцу.
+func (u *UseCase) moduleResultResource(resultId, ownerId, moduleId int) (usecase.Resource, error) {
+	res := usecase.ResultResource(resultId, ownerId)
+	if _, err := u.moduleRepoRead.GetModuleOwnerId(moduleId); errors.Is(err, repo.NoSuchRecordToSelect) {
+		return res, nil
+	} else if err != nil {
+		return usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	teachers, err := u.groupsRepoRead.GetModuleTeachers(ownerId, moduleId)
+	if err != nil {
+		return usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	res.Roles = teacherRoles(teachers)
+	return res, nil
+}
+
+// categoryResultResource описывает результат по категории: кроме владельца его видят
+// преподаватели групп, которым открыта категория
+func (u *UseCase) categoryResultResource(resultId, ownerId, categoryId int) (usecase.Resource, error) {
+	res := usecase.ResultResource(resultId, ownerId)
+	if _, err := u.categoryRepoRead.GetCategoryOwnerId(categoryId); errors.Is(err, repo.NoSuchRecordToSelect) {
+		return res, nil
+	} else if err != nil {
+		return usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	teachers, err := u.groupsRepoRead.GetCategoryTeachers(ownerId, categoryId)
+	if err != nil {
+		return usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	res.Roles = teacherRoles(teachers)
+	return res, nil
+}
+
+func teacherRoles(teachers []int) map[int]string {
+	roles := make(map[int]string, len(teachers))
+	for _, teacherId := range teachers {
+		roles[teacherId] = entity.TeacherRole
+	}
+	return roles
+}
+
+// groupResource загружает группу и роли ее участников
+func (u *UseCase) groupResource(groupId int, uow uow.UnitOfWork) (entity.Group, usecase.Resource, error) {
+	groupsRepoRead := u.groupsRepoRead
+	if uow != nil {
+		groupsRepoRead = uow.GetGroupsRepoReader()
+	}
+
+	group, err := groupsRepoRead.GetGroupById(groupId)
+	if err != nil {
+		return entity.Group{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	roles, err := groupsRepoRead.GetGroupRoles(groupId)
+	if err != nil {
+		return entity.Group{}, usecase.Resource{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return group, usecase.GroupResource(group, roles), nil
+}
@@ -2,20 +2,56 @@ package interactivelearning
 
 import (
 	"errors"
+	"fmt"
 	"interactive_learning/internal/entity"
 	httputils "interactive_learning/internal/http_utils"
+	"interactive_learning/internal/repo"
 	"interactive_learning/internal/uow"
 	"interactive_learning/internal/usecase"
+	"slices"
 	"time"
 )
 
-func (u *UseCase) GetResultsByOwner(userId int) ([]entity.CategoryModulesResult, []entity.ModuleResult, error) {
-	categoriesRes, err := u.categoryModulesResultsRepoRead.GetCategoriesResByOwner(userId)
+func validateResult(result httputils.ResultForReq) error {
+	if !slices.Contains(entity.ResultTypes, result.Type) {
+		return usecase.NewInvalidDataError("result", errors.New("unknown result type "+result.Type))
+	}
+
+	isScored := slices.Contains(entity.ScoredResultTypes, result.Type)
+	if !isScored && (result.Score != nil || result.QuestionsCount != nil) {
+		return usecase.NewInvalidDataError("result", errors.New("score is not supported for result type "+result.Type))
+	}
+	if result.Type == entity.ExamResult && (result.Score == nil || result.QuestionsCount == nil) {
+		return usecase.NewInvalidDataError("result", errors.New("exam result must contain score and questions count"))
+	}
+
+	if result.Score != nil && *result.Score < 0 {
+		return usecase.NewInvalidDataError("result", errors.New("negative score"))
+	}
+	if result.Duration != nil && *result.Duration < 0 {
+		return usecase.NewInvalidDataError("result", errors.New("negative duration"))
+	}
+	if result.QuestionsCount != nil && *result.QuestionsCount < 0 {
+		return usecase.NewInvalidDataError("result", errors.New("negative questions count"))
+	}
+	if result.Score != nil && result.QuestionsCount != nil && *result.Score > *result.QuestionsCount {
+		return usecase.NewInvalidDataError("result", errors.New("score is greater than questions count"))
+	}
+	return nil
+}
+
+func (u *UseCase) GetResultsByOwner(ownerId, userId int) ([]entity.CategoryModulesResult, []entity.ModuleResult, error) {
+	// все результаты пользователя видит только он сам
+	if !u.policy.CanView(userId, usecase.ResultResource(ownerId, ownerId)) {
+		return nil, nil, usecase.NewNotAvailableError("user results", ownerId)
+	}
+
+	categoriesRes, err := u.categoryModulesResultsRepoRead.GetCategoriesResByOwner(ownerId)
 	if err != nil {
 		return nil, nil, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	modulesRes, err := u.modulesResultsRepoRead.GetModulesResByOwner(userId)
+	modulesRes, err := u.modulesResultsRepoRead.GetModulesResByOwner(ownerId)
 	if err != nil {
 		return nil, nil, u.errorsMapper.DBErrorToApp(err)
 	}
@@ -23,15 +59,34 @@ func (u *UseCase) GetResultsByOwner(userId int) ([]entity.CategoryModulesResult,
 	return categoriesRes, modulesRes, nil
 }
 
-func (u *UseCase) GetModuleResultById(resultId int) (entity.ModuleResult, error) {
+func (u *UseCase) GetModuleResultById(resultId, userId int) (entity.ModuleResult, error) {
 	moduleResult, err := u.modulesResultsRepoRead.GetModulesResultById(resultId)
 	if err != nil {
 		return entity.ModuleResult{}, u.errorsMapper.DBErrorToApp(err)
 	}
+
+	res, err := u.moduleResultResource(resultId, moduleResult.Owner, moduleResult.ModuleId)
+	if err != nil {
+		return entity.ModuleResult{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.ModuleResult{}, u.policy.Deny(res)
+	}
 	return moduleResult, nil
 }
 
-func (u *UseCase) GetCardsResultById(resultId int) ([]entity.CardsResult, error) {
+func (u *UseCase) GetCardsResultById(resultId, userId int) ([]entity.CardsResult, error) {
+	ownerId, moduleId, err := u.resultsRepoRead.GetResultOwnerAndModule(resultId)
+	if err != nil {
+		return []entity.CardsResult{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	res, err := u.moduleResultResource(resultId, ownerId, moduleId)
+	if err != nil {
+		return []entity.CardsResult{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.CardsResult{}, u.policy.Deny(res)
+	}
+
 	cardsResult, err := u.cardsResultsRepoRead.GetCardsResultById(resultId)
 	if err != nil {
 		return []entity.CardsResult{}, u.errorsMapper.DBErrorToApp(err)
@@ -39,31 +94,109 @@ func (u *UseCase) GetCardsResultById(resultId int) ([]entity.CardsResult, error)
 	return cardsResult, nil
 }
 
-func (u *UseCase) GetResultsToModuleId(moduleId, userId int) ([]entity.ModuleResult, error) {
-	modulesResults, err := u.modulesResultsRepoRead.GetResultsToModuleOwner(moduleId, userId)
+func (u *UseCase) GetResultsToModuleId(moduleId, ownerId, userId int) ([]entity.ModuleResult, error) {
+	res, err := u.moduleResultResource(moduleId, ownerId, moduleId)
+	if err != nil {
+		return []entity.ModuleResult{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.ModuleResult{}, usecase.NewNotAvailableError("module results", moduleId)
+	}
+
+	modulesResults, err := u.modulesResultsRepoRead.GetResultsToModuleOwner(moduleId, ownerId)
 	if err != nil {
 		return []entity.ModuleResult{}, u.errorsMapper.DBErrorToApp(err)
 	}
 	return modulesResults, nil
 }
 
-func (u *UseCase) GetResultsByCategoryId(categoryId, userId int) ([]entity.CategoryModulesResult, error) {
-	categoryResults, err := u.categoryModulesResultsRepoRead.GetResultsByCategoryOwner(categoryId, userId)
+func (u *UseCase) GetResultsByCategoryId(categoryId, ownerId, userId int) ([]entity.CategoryModulesResult, error) {
+	res, err := u.categoryResultResource(categoryId, ownerId, categoryId)
+	if err != nil {
+		return []entity.CategoryModulesResult{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.CategoryModulesResult{}, usecase.NewNotAvailableError("category results", categoryId)
+	}
+
+	categoryResults, err := u.categoryModulesResultsRepoRead.GetResultsByCategoryOwner(categoryId, ownerId)
 	if err != nil {
 		return []entity.CategoryModulesResult{}, u.errorsMapper.DBErrorToApp(err)
 	}
 	return categoryResults, nil
 }
 
-func (u *UseCase) GetCategoryResById(categoryResultsId int) (entity.CategoryModulesResult, error) {
+func (u *UseCase) GetCategoryResById(categoryResultsId, userId int) (entity.CategoryModulesResult, error) {
 	categoryResult, err := u.categoryModulesResultsRepoRead.GetCategoryResById(categoryResultsId)
 	if err != nil {
 		return entity.CategoryModulesResult{}, u.errorsMapper.DBErrorToApp(err)
+	} else if len(categoryResult.Modules) == 0 {
+		return entity.CategoryModulesResult{}, u.errorsMapper.DBErrorToApp(repo.NoSuchRecordToSelect)
+	}
+
+	res, err := u.categoryResultResource(categoryResultsId, categoryResult.Owner, categoryResult.CategoryId)
+	if err != nil {
+		return entity.CategoryModulesResult{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return entity.CategoryModulesResult{}, u.policy.Deny(res)
 	}
 	return categoryResult, nil
 }
 
-func (u *UseCase) InsertModuleResult(result httputils.InsertModuleResultReq) (int, error) {
+func (u *UseCase) checkModuleResultAvailable(userId int, result httputils.InsertModuleResultReq) error {
+	if err := validateResult(result.Result); err != nil {
+		return err
+	}
+
+	_, res, err := u.moduleResource(result.ModuleId, nil)
+	if err != nil {
+		return err
+	} else if !u.policy.CanView(userId, res) {
+		return u.policy.Deny(res)
+	}
+	return nil
+}
+
+// checkCategoryResultAvailable вызывается в транзакции после блокировок. Модули результата
+// должны входить в категорию и быть доступны пользователю, иначе в результат
+// скопируется текст карточек чужого закрытого модуля
+func (u *UseCase) checkCategoryResultAvailable(userId int, result httputils.InsertCategoryModulesResultReq, uow uow.UnitOfWork) error {
+	for _, modulesRes := range result.Modules {
+		if err := validateResult(modulesRes.Result); err != nil {
+			return err
+		}
+	}
+
+	_, res, err := u.categoryResource(result.CategoryId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanView(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	categoryModules, err := uow.GetCategoryModulesRepoReader().GetModulesToCategory(result.CategoryId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	for _, modulesRes := range result.Modules {
+		if !slices.ContainsFunc(categoryModules, func(module entity.Module) bool { return module.Id == modulesRes.ModuleId }) {
+			return usecase.NewInvalidDataError("result",
+				fmt.Errorf("module %d does not belong to category %d", modulesRes.ModuleId, result.CategoryId))
+		}
+		_, moduleRes, err := u.moduleResource(modulesRes.ModuleId, uow)
+		if err != nil {
+			return err
+		} else if !u.policy.CanView(userId, moduleRes) {
+			return u.policy.Deny(moduleRes)
+		}
+	}
+	return nil
+}
+
+func (u *UseCase) InsertModuleResult(userId int, idempotencyKey string, result httputils.InsertModuleResultReq) (int, error) {
+	if err := u.checkModuleResultAvailable(userId, result); err != nil {
+		return -1, err
+	}
+	result.Owner = userId
+
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return -1, usecase.NewInternalError(err)
@@ -71,15 +204,59 @@ func (u *UseCase) InsertModuleResult(result httputils.InsertModuleResultReq) (in
 	defer uow.Rollback()
 
 	u.resultsMutex.Lock()
-	defer u.resultsMutex.Unlock()
+	u.cardsResultsMutex.Lock()
+	u.modulesResultsMutex.Lock()
+	defer func() {
+		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
+		u.modulesResultsMutex.Unlock()
+	}()
 
-	time, err := time.Parse(time.DateTime, result.Time)
+	var hash string
+	var err error
+	if idempotencyKey != "" {
+		if hash, err = requestHash(result); err != nil {
+			return -1, err
+		}
+		response, isReplay, err := u.getIdempotentResponse(userId, idempotencyKey, entity.ModuleResultIdempotency, hash, uow)
+		if err != nil {
+			return -1, err
+		} else if isReplay {
+			return response.ResultId, nil
+		}
+	}
+
+	insertedResId, err := u.insertModuleResult(result, time.Time{}, uow)
 	if err != nil {
+		return -1, err
+	}
+
+	if idempotencyKey != "" {
+		err = u.saveIdempotentResponse(userId, idempotencyKey, entity.ModuleResultIdempotency, hash,
+			entity.IdempotentResponse{ResultId: insertedResId}, uow)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	if err = uow.Commit(); err != nil {
 		return -1, usecase.NewInternalError(err)
 	}
 
+	return insertedResId, nil
+}
+
+// сохраняет результат по модулю, вызывающий отвечает за проверки и блокировки.
+// studiedAt - когда ученик видел карточки, нулевое время - сейчас.
+func (u *UseCase) insertModuleResult(result httputils.InsertModuleResultReq, studiedAt time.Time, uow uow.UnitOfWork) (int, error) {
+	time, err := time.Parse(time.DateTime, result.Time)
+	if err != nil {
+		return -1, usecase.NewInvalidDataError("result", err)
+	}
+
 	err = uow.GetResultsRepoWriter().InsertResult(entity.Result{
-		Type: result.Result.Type})
+		Type:       result.Result.Type,
+		ResultMeta: result.Result.ResultMeta})
 	if err != nil {
 		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
@@ -89,46 +266,69 @@ func (u *UseCase) InsertModuleResult(result httputils.InsertModuleResultReq) (in
 		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	u.cardsResultsMutex.Lock()
-	defer u.cardsResultsMutex.Unlock()
-
-	for _, cardRes := range result.Result.CardsRes {
-		err = uow.GetCardsResultsRepoWriter().InsertCardResult(insertedResId, cardRes.CardId, cardRes.Result)
+	cardsRes, err := u.snapshotCardsResults(result.ModuleId, result.Result.CardsRes, studiedAt, uow)
+	if err != nil {
+		return -1, err
+	}
+	for _, cardRes := range cardsRes {
+		err = uow.GetCardsResultsRepoWriter().InsertCardResult(insertedResId, cardRes)
 		if err != nil {
 			return -1, u.errorsMapper.DBErrorToApp(err)
 		}
 	}
 
-	u.modulesResultsMutex.Lock()
-	defer u.modulesResultsMutex.Unlock()
-
 	err = uow.GetModulesResultsRepoWriter().InsertResultToModule(result.ModuleId, insertedResId, result.Owner, time)
 	if err != nil {
 		return -1, u.errorsMapper.DBErrorToApp(err)
 	}
+	if err = u.submitModuleResult(result, insertedResId, uow); err != nil {
+		return -1, err
+	}
+	return insertedResId, nil
+}
 
-	if err = uow.Commit(); err != nil {
-		return -1, usecase.NewInternalError(err)
+// сохраняет в результатах текст карточек, который видел ученик,
+// чтобы история не менялась после редактирования или удаления карточек.
+// Для результатов, пройденных раньше studiedAt, текст восстанавливается по ревизиям.
+func (u *UseCase) snapshotCardsResults(moduleId int, cardsRes []entity.CardsResult, studiedAt time.Time, uow uow.UnitOfWork) ([]entity.CardsResult, error) {
+	cards, err := uow.GetCardRepoReader().GetCardsByModule(moduleId)
+	if err != nil {
+		return nil, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	return insertedResId, nil
+	snapshots := make([]entity.CardsResult, 0, len(cardsRes))
+	for _, cardRes := range cardsRes {
+		idx := slices.IndexFunc(cards, func(card entity.Card) bool { return card.Id == cardRes.CardId })
+		if idx < 0 {
+			return nil, usecase.NewInvalidDataError("result", fmt.Errorf("card %d does not belong to module %d", cardRes.CardId, moduleId))
+		}
+		text := entity.CardToAdd{Term: cards[idx].Term, Definition: cards[idx].Definition}
+		if !studiedAt.IsZero() {
+			revisions, err := uow.GetRevisionsRepoReader().GetCardRevisions(cardRes.CardId)
+			if err != nil {
+				return nil, u.errorsMapper.DBErrorToApp(err)
+			}
+			text = cardTextAt(cards[idx], revisions, studiedAt)
+		}
+		snapshots = append(snapshots, entity.CardsResult{
+			CardId:     cardRes.CardId,
+			Result:     cardRes.Result,
+			Term:       text.Term,
+			Definition: text.Definition,
+		})
+	}
+	return snapshots, nil
 }
 
-func (u *UseCase) InsertCategoryResult(result httputils.InsertCategoryModulesResultReq) (int, []int, error) {
+func (u *UseCase) InsertCategoryResult(userId int, idempotencyKey string, result httputils.InsertCategoryModulesResultReq) (int, []int, error) {
+	result.Owner = userId
+
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return -1, []int{}, usecase.NewInternalError(err)
 	}
 	defer uow.Rollback()
 
-	insertedResIds := []int{}
-
-	lastInsertedResId, err := uow.GetCategoryModulesResultsRepoReader().GetLastInsertedResId()
-	if err != nil {
-		return -1, []int{}, err
-	}
-	newInsertResultId := lastInsertedResId + 1
-
 	u.resultsMutex.Lock()
 	u.cardsResultsMutex.Lock()
 	u.categoryModulesResultsMutex.Lock()
@@ -138,9 +338,64 @@ func (u *UseCase) InsertCategoryResult(result httputils.InsertCategoryModulesRes
 		u.categoryModulesResultsMutex.Unlock()
 	}()
 
+	if err := u.checkCategoryResultAvailable(userId, result, uow); err != nil {
+		return -1, []int{}, err
+	}
+
+	var hash string
+	var err error
+	if idempotencyKey != "" {
+		if hash, err = requestHash(result); err != nil {
+			return -1, []int{}, err
+		}
+		response, isReplay, err := u.getIdempotentResponse(userId, idempotencyKey, entity.CategoryResultIdempotency, hash, uow)
+		if err != nil {
+			return -1, []int{}, err
+		} else if isReplay {
+			return response.CategoryResultId, response.ResultsIds, nil
+		}
+	}
+
+	newInsertResultId, insertedResIds, err := u.insertCategoryResult(result, time.Time{}, uow)
+	if err != nil {
+		return -1, []int{}, err
+	}
+
+	if idempotencyKey != "" {
+		err = u.saveIdempotentResponse(userId, idempotencyKey, entity.CategoryResultIdempotency, hash,
+			entity.IdempotentResponse{CategoryResultId: newInsertResultId, ResultsIds: insertedResIds}, uow)
+		if err != nil {
+			return -1, []int{}, err
+		}
+	}
+
+	if err := uow.Commit(); err != nil {
+		return -1, []int{}, usecase.NewInternalError(err)
+	}
+
+	return newInsertResultId, insertedResIds, nil
+}
+
+// сохраняет результат по категории, вызывающий отвечает за проверки и блокировки.
+// studiedAt - как в insertModuleResult.
+func (u *UseCase) insertCategoryResult(result httputils.InsertCategoryModulesResultReq, studiedAt time.Time, uow uow.UnitOfWork) (int, []int, error) {
+	insertedResIds := []int{}
+
+	time, err := time.Parse(time.DateTime, result.Time)
+	if err != nil {
+		return -1, []int{}, usecase.NewInvalidDataError("result", err)
+	}
+
+	lastInsertedResId, err := uow.GetCategoryModulesResultsRepoReader().GetLastInsertedResId()
+	if err != nil {
+		return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	newInsertResultId := lastInsertedResId + 1
+
 	for _, modulesRes := range result.Modules {
 		err = uow.GetResultsRepoWriter().InsertResult(entity.Result{
-			Type: modulesRes.Result.Type})
+			Type:       modulesRes.Result.Type,
+			ResultMeta: modulesRes.Result.ResultMeta})
 		if err != nil {
 			return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
 		}
@@ -150,57 +405,67 @@ func (u *UseCase) InsertCategoryResult(result httputils.InsertCategoryModulesRes
 			return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
 		}
 
-		for _, cardRes := range modulesRes.Result.CardsRes {
-			err = uow.GetCardsResultsRepoWriter().InsertCardResult(insertedResId, cardRes.CardId, cardRes.Result)
+		cardsRes, err := u.snapshotCardsResults(modulesRes.ModuleId, modulesRes.Result.CardsRes, studiedAt, uow)
+		if err != nil {
+			return -1, []int{}, err
+		}
+		for _, cardRes := range cardsRes {
+			err = uow.GetCardsResultsRepoWriter().InsertCardResult(insertedResId, cardRes)
 			if err != nil {
 				return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
 			}
 		}
 		insertedResIds = append(insertedResIds, insertedResId)
 
-		time, err := time.Parse(time.DateTime, result.Time)
-		if err != nil {
-			return -1, []int{}, usecase.NewInternalError(err)
-		}
-
 		err = uow.GetCategoryModulesResultsRepoWriter().InsertCategoryModule(newInsertResultId, result.CategoryId, modulesRes.ModuleId, insertedResId, result.Owner, time)
 		if err != nil {
 			return -1, []int{}, u.errorsMapper.DBErrorToApp(err)
 		}
 	}
-
-	if err := uow.Commit(); err != nil {
-		return -1, []int{}, usecase.NewInternalError(err)
+	if err = u.submitCategoryResult(result, newInsertResultId, uow); err != nil {
+		return -1, []int{}, err
 	}
-
 	return newInsertResultId, insertedResIds, nil
 }
 
-func (u *UseCase) DeleteModuleResult(resultId int) error {
+func (u *UseCase) DeleteModuleResult(userId, resultId int) error {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return usecase.NewInternalError(err)
 	}
 	defer uow.Rollback()
 
-	u.cardsResultsMutex.Lock()
-	defer u.cardsResultsMutex.Unlock()
-
-	err := uow.GetCardsResultsRepoWriter().DeleteCardsToResult(resultId)
+	moduleRes, err := uow.GetModulesResultsRepoReader().GetModulesResultById(resultId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
+	if res := usecase.ResultResource(resultId, moduleRes.Owner); !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetAssignmentsRepoWriter().DeleteModuleResultSubmissions(resultId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
 
+	// порядок блокировок тот же, что при сохранении результата
+	u.resultsMutex.Lock()
+	u.cardsResultsMutex.Lock()
 	u.modulesResultsMutex.Lock()
-	defer u.modulesResultsMutex.Unlock()
+	defer func() {
+		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
+		u.modulesResultsMutex.Unlock()
+	}()
 
-	err = uow.GetModulesResultsRepoWriter().DeleteResultToModule(resultId)
+	err = uow.GetCardsResultsRepoWriter().DeleteCardsToResult(resultId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
-	u.resultsMutex.Lock()
-	defer u.resultsMutex.Unlock()
+	err = uow.GetModulesResultsRepoWriter().DeleteResultToModule(resultId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
 
 	err = uow.GetResultsRepoWriter().DeleteResultById(resultId)
 	if err != nil {
@@ -213,7 +478,7 @@ func (u *UseCase) DeleteModuleResult(resultId int) error {
 	return nil
 }
 
-func (u *UseCase) DeleteCategoryResultById(categoryResultId int) error {
+func (u *UseCase) DeleteCategoryResultById(userId, categoryResultId int) error {
 	uow := u.unitOfWorkFactory()
 	if err := uow.Begin(); err != nil {
 		return usecase.NewInternalError(err)
@@ -223,22 +488,31 @@ func (u *UseCase) DeleteCategoryResultById(categoryResultId int) error {
 	categoryRes, err := uow.GetCategoryModulesResultsRepoReader().GetCategoryResById(categoryResultId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
+	} else if len(categoryRes.Modules) == 0 {
+		return u.errorsMapper.DBErrorToApp(repo.NoSuchRecordToDelete)
+	}
+	if res := usecase.ResultResource(categoryResultId, categoryRes.Owner); !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetAssignmentsRepoWriter().DeleteCategoryResultSubmissions(categoryResultId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
 	}
 
+	u.resultsMutex.Lock()
+	u.cardsResultsMutex.Lock()
 	u.categoryModulesResultsMutex.Lock()
-	defer u.categoryModulesResultsMutex.Unlock()
+	defer func() {
+		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
+		u.categoryModulesResultsMutex.Unlock()
+	}()
 
 	err = uow.GetCategoryModulesResultsRepoWriter().DeleteResultById(categoryResultId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
-	u.cardsResultsMutex.Lock()
-	defer u.cardsResultsMutex.Unlock()
-
-	u.resultsMutex.Lock()
-	defer u.resultsMutex.Unlock()
-
 	for _, moduleRes := range categoryRes.Modules {
 		err = uow.GetCardsResultsRepoWriter().DeleteCardsToResult(moduleRes.Result.Id)
 		if err != nil {
@@ -267,17 +541,22 @@ func (u *UseCase) deleteResultByModuleId(moduleId int, uow uow.UnitOfWork) error
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
+	u.resultsMutex.Lock()
 	u.cardsResultsMutex.Lock()
-	defer u.cardsResultsMutex.Unlock()
-
 	u.modulesResultsMutex.Lock()
-	defer u.modulesResultsMutex.Unlock()
-
-	u.resultsMutex.Lock()
-	defer u.resultsMutex.Unlock()
+	defer func() {
+		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
+		u.modulesResultsMutex.Unlock()
+	}()
 
 	for _, moduleRes := range modulesRes {
-		err := uow.GetCardsResultsRepoWriter().DeleteCardsToResult(moduleRes.Result.Id)
+		err := uow.GetAssignmentsRepoWriter().DeleteModuleResultSubmissions(moduleRes.Result.Id)
+		if err != nil {
+			return u.errorsMapper.DBErrorToApp(err)
+		}
+
+		err = uow.GetCardsResultsRepoWriter().DeleteCardsToResult(moduleRes.Result.Id)
 		if err != nil {
 			return u.errorsMapper.DBErrorToApp(err)
 		}
@@ -301,19 +580,22 @@ func (u *UseCase) deleteResultByCategoryId(categoryId int, uow uow.UnitOfWork) e
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
-	u.categoryModulesResultsMutex.Lock()
-	u.cardsResultsMutex.Lock()
 	u.resultsMutex.Lock()
+	u.cardsResultsMutex.Lock()
+	u.categoryModulesResultsMutex.Lock()
 	defer func() {
-		u.categoryModulesResultsMutex.Unlock()
-		u.cardsResultsMutex.Unlock()
 		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
+		u.categoryModulesResultsMutex.Unlock()
 	}()
 
 	err = uow.GetCategoryModulesResultsRepoWriter().DeleteAllToCategory(categoryId)
 	if err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
+	if err = uow.GetAssignmentsRepoWriter().DeleteOrphanedCategorySubmissions(); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
 
 	for _, moduleRes := range categoryRes.Modules {
 		err = uow.GetCardsResultsRepoWriter().DeleteCardsToResult(moduleRes.Result.Id)
@@ -335,12 +617,12 @@ func (u *UseCase) deleteModuleResFromCategories(moduleId int, uow uow.UnitOfWork
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
-	u.cardsResultsMutex.Lock()
 	u.resultsMutex.Lock()
+	u.cardsResultsMutex.Lock()
 	u.categoryModulesResultsMutex.Lock()
 	defer func() {
-		u.cardsResultsMutex.Unlock()
 		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
 		u.categoryModulesResultsMutex.Unlock()
 	}()
 
@@ -359,6 +641,10 @@ func (u *UseCase) deleteModuleResFromCategories(moduleId int, uow uow.UnitOfWork
 	if err = uow.GetCategoryModulesResultsRepoWriter().DeleteModulesFromCategories(moduleId); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
+	// прохождения категорий, где модуль был единственным, больше не на что засчитывать
+	if err = uow.GetAssignmentsRepoWriter().DeleteOrphanedCategorySubmissions(); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
 	return nil
 }
 
@@ -368,12 +654,12 @@ func (u *UseCase) deleteModuleResFromCategory(categoryId, moduleId int, uow uow.
 		return u.errorsMapper.DBErrorToApp(err)
 	}
 
-	u.cardsResultsMutex.Lock()
 	u.resultsMutex.Lock()
+	u.cardsResultsMutex.Lock()
 	u.categoryModulesResultsMutex.Lock()
 	defer func() {
-		u.cardsResultsMutex.Unlock()
 		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
 		u.categoryModulesResultsMutex.Unlock()
 	}()
 
@@ -392,5 +678,8 @@ func (u *UseCase) deleteModuleResFromCategory(categoryId, moduleId int, uow uow.
 	if err = uow.GetCategoryModulesResultsRepoWriter().DeleteModulesFromCategory(categoryId, moduleId); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
+	if err = uow.GetAssignmentsRepoWriter().DeleteOrphanedCategorySubmissions(); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
 	return nil
 }
@@ -0,0 +1,59 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	"interactive_learning/internal/usecase"
+	"testing"
+)
+
+func TestValidateResult(t *testing.T) {
+	num := func(n int) *int { return &n }
+	result := func(resultType string, score, duration, questions *int) httputils.ResultForReq {
+		return httputils.ResultForReq{
+			Type:       resultType,
+			ResultMeta: entity.ResultMeta{Score: score, Duration: duration, QuestionsCount: questions},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		result  httputils.ResultForReq
+		wantErr bool
+	}{
+		{"learning without meta", result(entity.LearningResult, nil, nil, nil), false},
+		{"learning with duration", result(entity.LearningResult, nil, num(60), nil), false},
+		{"test with score", result(entity.TestResult, num(8), num(120), num(10)), false},
+		{"write without score", result(entity.WriteResult, nil, nil, nil), false},
+		{"exam with full score", result(entity.ExamResult, num(10), nil, num(10)), false},
+		{"exam with zero score", result(entity.ExamResult, num(0), nil, num(0)), false},
+
+		{"unknown type", result("quiz", nil, nil, nil), true},
+		{"empty type", result("", nil, nil, nil), true},
+		{"score of unscored type", result(entity.MatchResult, num(1), nil, nil), true},
+		{"questions of unscored type", result(entity.LearningResult, nil, nil, num(1)), true},
+		{"exam without score", result(entity.ExamResult, nil, nil, num(10)), true},
+		{"exam without questions", result(entity.ExamResult, num(5), nil, nil), true},
+		{"negative score", result(entity.TestResult, num(-1), nil, num(10)), true},
+		{"negative duration", result(entity.MatchResult, nil, num(-1), nil), true},
+		{"negative questions", result(entity.TestResult, nil, nil, num(-1)), true},
+		{"score greater than questions", result(entity.TestResult, num(11), nil, num(10)), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateResult(test.result)
+			if !test.wantErr {
+				if err != nil {
+					t.Errorf("got error %v", err)
+				}
+				return
+			}
+			var invalid *usecase.InvalidDataError
+			if !errors.As(err, &invalid) {
+				t.Errorf("got error %v, want invalid data error", err)
+			}
+		})
+	}
+}
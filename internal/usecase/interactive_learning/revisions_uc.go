@@ -0,0 +1,139 @@
+package interactivelearning
+
+import (
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"time"
+)
+
+// cardTextAt восстанавливает текст карточки на момент at, отменяя более поздние ревизии.
+// revisions отсортированы от новых к старым, как их возвращает репозиторий.
+func cardTextAt(card entity.Card, revisions []entity.CardRevision, at time.Time) entity.CardToAdd {
+	text := entity.CardToAdd{Term: card.Term, Definition: card.Definition}
+	for _, revision := range revisions {
+		if !revision.CreatedAt.After(at) {
+			break
+		}
+		text = revision.Before
+	}
+	return text
+}
+
+// GetCardHistory возвращает ревизии карточки, новые первыми
+func (u *UseCase) GetCardHistory(userId, cardId int) ([]entity.CardRevision, error) {
+	_, res, err := u.cardResource(cardId, nil)
+	if err != nil {
+		return []entity.CardRevision{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.CardRevision{}, u.policy.Deny(res)
+	}
+
+	revisions, err := u.revisionsRepoRead.GetCardRevisions(cardId)
+	if err != nil {
+		return []entity.CardRevision{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return revisions, nil
+}
+
+// RevertCard отменяет ревизию: карточка получает состояние, которое было до нее.
+// Сам откат тоже сохраняется в истории.
+func (u *UseCase) RevertCard(userId, cardId, revisionId int) (entity.Card, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return entity.Card{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.cardMutex.Lock()
+	defer u.cardMutex.Unlock()
+
+	_, res, err := u.cardResource(cardId, uow)
+	if err != nil {
+		return entity.Card{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return entity.Card{}, u.policy.Deny(res)
+	}
+
+	revision, err := uow.GetRevisionsRepoReader().GetCardRevisionById(revisionId)
+	if err != nil {
+		return entity.Card{}, u.errorsMapper.DBErrorToApp(err)
+	} else if revision.CardId != cardId {
+		return entity.Card{}, usecase.NewNotAvailableError("revision", revisionId)
+	}
+
+	old, err := uow.GetCardRepoReader().GetCardById(cardId)
+	if err != nil {
+		return entity.Card{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	card := entity.Card{Id: cardId, Term: revision.Before.Term, Definition: revision.Before.Definition, Version: old.Version}
+	if _, err = u.updateCardWithRevision(userId, old, card, entity.RevertRevision, uow); err != nil {
+		return entity.Card{}, err
+	}
+
+	card, err = uow.GetCardRepoReader().GetCardById(cardId)
+	if err != nil {
+		return entity.Card{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return entity.Card{}, usecase.NewInternalError(err)
+	}
+	return card, nil
+}
+
+func (u *UseCase) GetModuleHistory(userId, moduleId int) ([]entity.ModuleRevision, error) {
+	_, res, err := u.moduleResource(moduleId, nil)
+	if err != nil {
+		return []entity.ModuleRevision{}, err
+	} else if !u.policy.CanView(userId, res) {
+		return []entity.ModuleRevision{}, u.policy.Deny(res)
+	}
+
+	revisions, err := u.revisionsRepoRead.GetModuleRevisions(moduleId)
+	if err != nil {
+		return []entity.ModuleRevision{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return revisions, nil
+}
+
+// updateCardWithRevision обновляет карточку версии card.Version, записывает ревизию,
+// если текст изменился, и возвращает новую версию
+func (u *UseCase) updateCardWithRevision(userId int, old, card entity.Card, action string, uow uow.UnitOfWork) (int, error) {
+	version, err := uow.GetCardRepoWriter().UpdateCard(card)
+	if errors.Is(err, repo.NoSuchRecordToUpdate) {
+		return -1, u.cardVersionMismatch(card.Id, uow)
+	} else if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.GetModuleRepoWriter().TouchModule(old.ParentModule); err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if old.Term == card.Term && old.Definition == card.Definition {
+		return version, nil
+	}
+	err = uow.GetRevisionsRepoWriter().InsertCardRevision(entity.CardRevision{
+		CardId:   card.Id,
+		EditorId: userId,
+		Action:   action,
+		Before:   entity.CardToAdd{Term: old.Term, Definition: old.Definition},
+		After:    entity.CardToAdd{Term: card.Term, Definition: card.Definition},
+	})
+	if err != nil {
+		return -1, u.errorsMapper.DBErrorToApp(err)
+	}
+	return version, nil
+}
+
+// cardVersionMismatch объясняет, почему условное обновление не нашло карточку:
+// ее версию уже сменил другой запрос
+func (u *UseCase) cardVersionMismatch(cardId int, uow uow.UnitOfWork) error {
+	current, err := uow.GetCardRepoReader().GetCardById(cardId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	return usecase.NewVersionMismatchError("card", current.Version, current)
+}
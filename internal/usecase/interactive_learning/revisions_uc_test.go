@@ -0,0 +1,48 @@
+package interactivelearning
+
+import (
+	"interactive_learning/internal/entity"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCardTextAt(t *testing.T) {
+	text := func(term, definition string) entity.CardToAdd {
+		return entity.CardToAdd{
+			Term:       entity.TextWithLang{Lang: "en", Text: term},
+			Definition: entity.TextWithLang{Lang: "ru", Text: definition},
+		}
+	}
+	first := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	card := entity.Card{Term: entity.TextWithLang{Lang: "en", Text: "dog"}, Definition: entity.TextWithLang{Lang: "ru", Text: "собака"}}
+	// cat -> kitten в first, kitten -> dog в second; новые ревизии первыми
+	revisions := []entity.CardRevision{
+		{Before: text("kitten", "котенок"), After: text("dog", "собака"), CreatedAt: second},
+		{Before: text("cat", "кошка"), After: text("kitten", "котенок"), CreatedAt: first},
+	}
+
+	tests := []struct {
+		name      string
+		revisions []entity.CardRevision
+		at        time.Time
+		want      entity.CardToAdd
+	}{
+		{"no revisions", nil, first.Add(-time.Hour), text("dog", "собака")},
+		{"after last revision", revisions, second.Add(time.Minute), text("dog", "собака")},
+		{"at last revision", revisions, second, text("dog", "собака")},
+		{"between revisions", revisions, first.Add(time.Minute), text("kitten", "котенок")},
+		{"before all revisions", revisions, first.Add(-time.Minute), text("cat", "кошка")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := cardTextAt(card, test.revisions, test.at)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -11,13 +11,7 @@ func (u *UseCase) GetAllSelectedModulesByUser(userId int) ([]entity.Module, erro
 		return []entity.Module{}, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	publicModules := []entity.Module{}
-	for _, module := range modules {
-		if module.OwnerId == userId || module.Type == entity.PublicModule {
-			publicModules = append(publicModules, module)
-		}
-	}
-	return publicModules, nil
+	return u.visibleModules(modules, userId)
 }
 
 func (u *UseCase) GetAllSelectedCategoriesByUser(userId int) ([]entity.Category, error) {
@@ -26,13 +20,7 @@ func (u *UseCase) GetAllSelectedCategoriesByUser(userId int) ([]entity.Category,
 		return []entity.Category{}, u.errorsMapper.DBErrorToApp(err)
 	}
 
-	publicCategories := []entity.Category{}
-	for _, category := range categories {
-		if category.OwnerId == userId || category.Type == entity.PublicCategory {
-			publicCategories = append(publicCategories, category)
-		}
-	}
-	return publicCategories, nil
+	return u.visibleCategories(categories, userId)
 }
 
 func (u *UseCase) GetUsersCountToSelectedModule(moduleId int) (int, error) {
@@ -58,6 +46,13 @@ func (u *UseCase) InsertSelectedModuleToUser(userId, moduleId int) error {
 	}
 	defer uow.Rollback()
 
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanView(userId, res) {
+		return u.policy.Deny(res)
+	}
+
 	if err := uow.GetSelectedRepoWriter().InsertSelectedModuleToUser(userId, moduleId); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
@@ -75,6 +70,13 @@ func (u *UseCase) InsertSelectedCategoryToUser(userId, categoryId int) error {
 	}
 	defer uow.Rollback()
 
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return err
+	} else if !u.policy.CanView(userId, res) {
+		return u.policy.Deny(res)
+	}
+
 	if err := uow.GetSelectedRepoWriter().InsertSelectedCategoryToUser(userId, categoryId); err != nil {
 		return u.errorsMapper.DBErrorToApp(err)
 	}
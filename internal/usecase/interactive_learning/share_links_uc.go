@@ -0,0 +1,220 @@
+package interactivelearning
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+)
+
+func newShareSlug() (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// ensureModuleShareSlug создает ссылку, если ее еще нет. Существующая ссылка
+// сохраняется, чтобы после смены типа туда и обратно она продолжала работать.
+func (u *UseCase) ensureModuleShareSlug(moduleId int, uow uow.UnitOfWork) error {
+	slug, err := uow.GetModuleRepoReader().GetModuleShareSlug(moduleId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	} else if slug != "" {
+		return nil
+	}
+	_, err = u.rotateModuleShareSlug(moduleId, uow)
+	return err
+}
+
+func (u *UseCase) rotateModuleShareSlug(moduleId int, uow uow.UnitOfWork) (string, error) {
+	slug, err := newShareSlug()
+	if err != nil {
+		return "", err
+	}
+	if err = uow.GetModuleRepoWriter().SetModuleShareSlug(moduleId, slug); err != nil {
+		return "", u.errorsMapper.DBErrorToApp(err)
+	}
+	return slug, nil
+}
+
+func (u *UseCase) ensureCategoryShareSlug(categoryId int, uow uow.UnitOfWork) error {
+	slug, err := uow.GetCategoryRepoReader().GetCategoryShareSlug(categoryId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	} else if slug != "" {
+		return nil
+	}
+	_, err = u.rotateCategoryShareSlug(categoryId, uow)
+	return err
+}
+
+func (u *UseCase) rotateCategoryShareSlug(categoryId int, uow uow.UnitOfWork) (string, error) {
+	slug, err := newShareSlug()
+	if err != nil {
+		return "", err
+	}
+	if err = uow.GetCategoryRepoWriter().SetCategoryShareSlug(categoryId, slug); err != nil {
+		return "", u.errorsMapper.DBErrorToApp(err)
+	}
+	return slug, nil
+}
+
+// GetModuleShareSlug возвращает ссылку модуля, создавая ее при первом запросе
+func (u *UseCase) GetModuleShareSlug(userId, moduleId int) (string, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return "", err
+	} else if !u.policy.CanShare(userId, res) {
+		return "", u.policy.Deny(res)
+	}
+
+	if err = u.ensureModuleShareSlug(moduleId, uow); err != nil {
+		return "", err
+	}
+	slug, err := uow.GetModuleRepoReader().GetModuleShareSlug(moduleId)
+	if err != nil {
+		return "", u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	return slug, nil
+}
+
+// RotateModuleShareSlug заменяет ссылку модуля, старая перестает открываться
+func (u *UseCase) RotateModuleShareSlug(userId, moduleId int) (string, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return "", err
+	} else if !u.policy.CanShare(userId, res) {
+		return "", u.policy.Deny(res)
+	}
+
+	slug, err := u.rotateModuleShareSlug(moduleId, uow)
+	if err != nil {
+		return "", err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	return slug, nil
+}
+
+func (u *UseCase) GetCategoryShareSlug(userId, categoryId int) (string, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return "", err
+	} else if !u.policy.CanShare(userId, res) {
+		return "", u.policy.Deny(res)
+	}
+
+	if err = u.ensureCategoryShareSlug(categoryId, uow); err != nil {
+		return "", err
+	}
+	slug, err := uow.GetCategoryRepoReader().GetCategoryShareSlug(categoryId)
+	if err != nil {
+		return "", u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	return slug, nil
+}
+
+func (u *UseCase) RotateCategoryShareSlug(userId, categoryId int) (string, error) {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+
+	_, res, err := u.categoryResource(categoryId, uow)
+	if err != nil {
+		return "", err
+	} else if !u.policy.CanShare(userId, res) {
+		return "", u.policy.Deny(res)
+	}
+
+	slug, err := u.rotateCategoryShareSlug(categoryId, uow)
+	if err != nil {
+		return "", err
+	}
+
+	if err = uow.Commit(); err != nil {
+		return "", usecase.NewInternalError(err)
+	}
+	return slug, nil
+}
+
+// OpenShareLink открывает модуль или категорию по ссылке. Объекты по ссылке
+// открываются только так, приватные - только тем, кому они доступны и без нее.
+func (u *UseCase) OpenShareLink(userId int, slug string) (entity.ShareLink, error) {
+	module, err := u.moduleRepoRead.GetModuleByShareSlug(slug)
+	if err == nil {
+		module, res, err := u.moduleResource(module.Id, nil)
+		if err != nil {
+			return entity.ShareLink{}, err
+		} else if !u.policy.CanOpenLink(userId, res) {
+			return entity.ShareLink{}, u.policy.Deny(res)
+		}
+		if module.Cards, err = u.getCardsByModule(module.Id); err != nil {
+			return entity.ShareLink{}, err
+		}
+		return entity.ShareLink{Module: &module}, nil
+	} else if !errors.Is(err, repo.NoSuchRecordToSelect) {
+		return entity.ShareLink{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	category, err := u.categoryRepoRead.GetCategoryByShareSlug(slug)
+	if err != nil {
+		return entity.ShareLink{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	category, res, err := u.categoryResource(category.Id, nil)
+	if err != nil {
+		return entity.ShareLink{}, err
+	} else if !u.policy.CanOpenLink(userId, res) {
+		return entity.ShareLink{}, u.policy.Deny(res)
+	}
+	if category.Modules, err = u.categoryModules(category.Id, true, userId); err != nil {
+		return entity.ShareLink{}, err
+	}
+	return entity.ShareLink{Category: &category}, nil
+}
@@ -0,0 +1,226 @@
+package interactivelearning
+
+import (
+	"errors"
+	"fmt"
+	"interactive_learning/internal/entity"
+	httputils "interactive_learning/internal/http_utils"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/uow"
+	"interactive_learning/internal/usecase"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxSyncBatchSize = 500
+
+type syncItem struct {
+	idx        int
+	req        httputils.SyncItemReq
+	clientTime time.Time
+}
+
+func isValidUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i, r := range id {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Sync применяет результаты, накопленные клиентом без сети, в порядке их получения на клиенте.
+// Каждый элемент применяется в отдельной транзакции, статусы возвращаются в порядке запроса.
+func (u *UseCase) Sync(userId int, req httputils.SyncReq) ([]entity.SyncItemStatus, error) {
+	if len(req.Items) > maxSyncBatchSize {
+		return nil, usecase.NewInvalidDataError("sync", fmt.Errorf("batch contains more than %d items", maxSyncBatchSize))
+	}
+
+	statuses := make([]entity.SyncItemStatus, len(req.Items))
+	items := []syncItem{}
+	for i, item := range req.Items {
+		item.ClientId = strings.ToLower(item.ClientId)
+		statuses[i] = entity.SyncItemStatus{ClientId: item.ClientId}
+
+		if !isValidUUID(item.ClientId) {
+			statuses[i].Status, statuses[i].Message = entity.SyncRejected, "bad client id"
+			continue
+		}
+		if (item.ModuleResult == nil) == (item.CategoryResult == nil) {
+			statuses[i].Status, statuses[i].Message = entity.SyncRejected, "item must contain either a module or a category result"
+			continue
+		}
+		clientTime, err := time.Parse(time.RFC3339, item.ClientTime)
+		if err != nil {
+			statuses[i].Status, statuses[i].Message = entity.SyncRejected, "bad client time"
+			continue
+		}
+
+		items = append(items, syncItem{idx: i, req: item, clientTime: clientTime})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].clientTime.Before(items[j].clientTime)
+	})
+
+	for _, item := range items {
+		status, err := u.syncItem(userId, item)
+		if err != nil {
+			if errors.Is(err, usecase.InternalErr) {
+				return nil, err
+			}
+			status = entity.SyncItemStatus{Status: entity.SyncRejected, Message: err.Error()}
+		}
+		status.ClientId = item.req.ClientId
+		statuses[item.idx] = status
+	}
+
+	return statuses, nil
+}
+
+func (u *UseCase) syncItem(userId int, item syncItem) (entity.SyncItemStatus, error) {
+	kind := entity.ModuleResultIdempotency
+	if item.req.CategoryResult != nil {
+		kind = entity.CategoryResultIdempotency
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return entity.SyncItemStatus{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.resultsMutex.Lock()
+	u.cardsResultsMutex.Lock()
+	u.modulesResultsMutex.Lock()
+	u.categoryModulesResultsMutex.Lock()
+	defer func() {
+		u.resultsMutex.Unlock()
+		u.cardsResultsMutex.Unlock()
+		u.modulesResultsMutex.Unlock()
+		u.categoryModulesResultsMutex.Unlock()
+	}()
+
+	synced, err := uow.GetSyncedItemsRepoReader().GetSyncedItem(userId, item.req.ClientId)
+	if err == nil {
+		return entity.SyncItemStatus{
+			Status:           entity.SyncDuplicate,
+			ResultId:         synced.Response.ResultId,
+			CategoryResultId: synced.Response.CategoryResultId,
+			ResultsIds:       synced.Response.ResultsIds,
+		}, nil
+	} else if !errors.Is(err, repo.NoSuchRecordToSelect) {
+		return entity.SyncItemStatus{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	status := entity.SyncItemStatus{Status: entity.SyncApplied}
+	response := entity.IdempotentResponse{}
+	switch kind {
+	case entity.ModuleResultIdempotency:
+		result := *item.req.ModuleResult
+		if result.Time == "" {
+			result.Time = item.clientTime.Format(time.DateTime)
+		}
+		if err = u.checkModuleResultAvailable(userId, result); err != nil {
+			return entity.SyncItemStatus{}, err
+		}
+		result.Owner = userId
+
+		result.Result.CardsRes, status.SkippedCards, err = u.filterSyncedCardsResults(result.ModuleId, result.Result.CardsRes, uow)
+		if err != nil {
+			return entity.SyncItemStatus{}, err
+		}
+
+		if response.ResultId, err = u.insertModuleResult(result, item.clientTime, uow); err != nil {
+			return entity.SyncItemStatus{}, err
+		}
+		status.ResultId = response.ResultId
+	case entity.CategoryResultIdempotency:
+		result := *item.req.CategoryResult
+		if result.Time == "" {
+			result.Time = item.clientTime.Format(time.DateTime)
+		}
+		// модули, которые удалили или убрали из категории, пока клиент был без сети, пропускаются
+		var categoryModules []entity.Module
+		if categoryModules, err = uow.GetCategoryModulesRepoReader().GetModulesToCategory(result.CategoryId); err != nil {
+			return entity.SyncItemStatus{}, u.errorsMapper.DBErrorToApp(err)
+		}
+		modules := []httputils.InsertModuleResultReq{}
+		for _, moduleRes := range result.Modules {
+			if slices.ContainsFunc(categoryModules, func(module entity.Module) bool { return module.Id == moduleRes.ModuleId }) {
+				modules = append(modules, moduleRes)
+			}
+		}
+		if len(modules) == 0 {
+			return entity.SyncItemStatus{}, usecase.NewInvalidDataError("category result", errors.New("all modules of the result were deleted"))
+		}
+		result.Modules = modules
+
+		if err = u.checkCategoryResultAvailable(userId, result, uow); err != nil {
+			return entity.SyncItemStatus{}, err
+		}
+		result.Owner = userId
+
+		for i, moduleRes := range result.Modules {
+			var skipped []int
+			result.Modules[i].Result.CardsRes, skipped, err = u.filterSyncedCardsResults(moduleRes.ModuleId, moduleRes.Result.CardsRes, uow)
+			if err != nil {
+				return entity.SyncItemStatus{}, err
+			}
+			status.SkippedCards = append(status.SkippedCards, skipped...)
+		}
+
+		response.CategoryResultId, response.ResultsIds, err = u.insertCategoryResult(result, item.clientTime, uow)
+		if err != nil {
+			return entity.SyncItemStatus{}, err
+		}
+		status.CategoryResultId, status.ResultsIds = response.CategoryResultId, response.ResultsIds
+	}
+
+	err = uow.GetSyncedItemsRepoWriter().InsertSyncedItem(entity.SyncedItem{
+		Owner:      userId,
+		ClientId:   item.req.ClientId,
+		Kind:       kind,
+		ClientTime: item.clientTime,
+		Response:   response,
+	})
+	if err != nil {
+		return entity.SyncItemStatus{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return entity.SyncItemStatus{}, usecase.NewInternalError(err)
+	}
+	return status, nil
+}
+
+// отбрасывает результаты по карточкам, которые удалили из модуля, пока клиент был без сети
+func (u *UseCase) filterSyncedCardsResults(moduleId int, cardsRes []entity.CardsResult, uow uow.UnitOfWork) ([]entity.CardsResult, []int, error) {
+	cards, err := uow.GetCardRepoReader().GetCardsByModule(moduleId)
+	if err != nil {
+		return nil, nil, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	kept := []entity.CardsResult{}
+	skipped := []int{}
+	for _, cardRes := range cardsRes {
+		if slices.ContainsFunc(cards, func(card entity.Card) bool { return card.Id == cardRes.CardId }) {
+			kept = append(kept, cardRes)
+		} else {
+			skipped = append(skipped, cardRes.CardId)
+		}
+	}
+	return kept, skipped, nil
+}
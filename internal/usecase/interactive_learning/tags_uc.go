@@ -0,0 +1,135 @@
+package interactivelearning
+
+import (
+	"errors"
+	"fmt"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/usecase"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxTagsSuggestions = 20
+
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// normalizeTags приводит теги к нижнему регистру, схлопывает пробелы и убирает повторы
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > entity.MaxTagLen {
+			return nil, usecase.NewInvalidDataError("tags", fmt.Errorf("tag %q is longer than %d characters", tag, entity.MaxTagLen))
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" -_+#.", r) {
+				return nil, usecase.NewInvalidDataError("tags", fmt.Errorf("tag %q contains forbidden character %q", tag, r))
+			}
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if len(normalized) > entity.MaxTagsLen {
+		return nil, usecase.NewInvalidDataError("tags", fmt.Errorf("more than %d tags", entity.MaxTagsLen))
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+func (u *UseCase) SetModuleTags(userId, moduleId int, tags []string) ([]string, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return []string{}, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return []string{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	defer u.moduleMutex.Unlock()
+
+	_, res, err := u.moduleResource(moduleId, uow)
+	if err != nil {
+		return []string{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return []string{}, u.policy.Deny(res)
+	}
+
+	if err = uow.GetTagsRepoWriter().SetModuleTags(moduleId, tags); err != nil {
+		return []string{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return []string{}, usecase.NewInternalError(err)
+	}
+	return tags, nil
+}
+
+func (u *UseCase) SetCardTags(userId, cardId int, tags []string) ([]string, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return []string{}, err
+	}
+
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return []string{}, usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.cardMutex.Lock()
+	defer u.cardMutex.Unlock()
+
+	_, res, err := u.cardResource(cardId, uow)
+	if err != nil {
+		return []string{}, err
+	} else if !u.policy.CanEdit(userId, res) {
+		return []string{}, u.policy.Deny(res)
+	}
+
+	if err = uow.GetTagsRepoWriter().SetCardTags(cardId, tags); err != nil {
+		return []string{}, u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return []string{}, usecase.NewInternalError(err)
+	}
+	return tags, nil
+}
+
+// GetTagsByPrefix подсказывает теги публичных модулей и модулей пользователя
+func (u *UseCase) GetTagsByPrefix(prefix string, limit, userId int) ([]entity.TagCount, error) {
+	prefix = normalizeTag(prefix)
+	if prefix == "" {
+		return []entity.TagCount{}, usecase.NewInvalidDataError("tags", errors.New("empty prefix"))
+	}
+	if limit <= 0 || limit > maxTagsSuggestions {
+		limit = maxTagsSuggestions
+	}
+
+	tags, err := u.tagsRepoRead.GetTagsByPrefix(prefix, userId, limit)
+	if err != nil {
+		return []entity.TagCount{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return tags, nil
+}
+
+func (u *UseCase) GetPopularTags(limit, offset int) ([]entity.TagCount, error) {
+	tags, err := u.tagsRepoRead.GetPopularTags(limit, offset)
+	if err != nil {
+		return []entity.TagCount{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,296 @@
+package interactivelearning
+
+import (
+	"errors"
+	"fmt"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/repo"
+	"interactive_learning/internal/usecase"
+	"time"
+)
+
+func (u *UseCase) GetTrash(userId int) (entity.Trash, error) {
+	modules, err := u.moduleRepoRead.GetTrashedModules(userId)
+	if err != nil {
+		return entity.Trash{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	categories, err := u.categoryRepoRead.GetTrashedCategories(userId)
+	if err != nil {
+		return entity.Trash{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	cards, err := u.cardsRepoRead.GetTrashedCards(userId)
+	if err != nil {
+		return entity.Trash{}, u.errorsMapper.DBErrorToApp(err)
+	}
+	return entity.Trash{Modules: modules, Categories: categories, Cards: cards}, nil
+}
+
+// RestoreModule возвращает модуль из корзины. Связи с категориями и избранное
+// не удалялись, поэтому модуль снова появляется в них.
+func (u *UseCase) RestoreModule(userId, moduleId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	u.categoryModulesMutex.Lock()
+	defer func() {
+		u.moduleMutex.Unlock()
+		u.categoryModulesMutex.Unlock()
+	}()
+
+	trashed, err := uow.GetModuleRepoReader().GetTrashedModuleById(moduleId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	// восстановление - отмена удаления, поэтому право то же
+	if res := usecase.ModuleResource(trashed.Mod); !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetModuleRepoWriter().RestoreModule(moduleId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) RestoreCategory(userId, categoryId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	defer u.categoryMutex.Unlock()
+
+	trashed, err := uow.GetCategoryRepoReader().GetTrashedCategoryById(categoryId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if res := usecase.CategoryResource(trashed.Cat); !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetCategoryRepoWriter().RestoreCategory(categoryId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+func (u *UseCase) RestoreCard(userId, cardId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.cardMutex.Lock()
+	defer u.cardMutex.Unlock()
+
+	trashed, err := uow.GetCardRepoReader().GetTrashedCardById(cardId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	module, err := uow.GetModuleRepoReader().GetModuleById(trashed.Card.ParentModule)
+	if errors.Is(err, repo.NoSuchRecordToSelect) {
+		return usecase.NewConflictError("card", errors.New("parent module is in trash, restore it first"))
+	} else if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if res := usecase.CardResource(cardId, module); !u.policy.CanDelete(userId, res) {
+		return u.policy.Deny(res)
+	}
+
+	if err = uow.GetCardRepoWriter().RestoreCard(cardId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.GetModuleRepoWriter().TouchModule(module.Id); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
+
+// PurgeTrash окончательно удаляет то, что лежит в корзине дольше retention.
+// Каждый объект удаляется в своей транзакции, карточки - раньше модулей.
+// Ошибка на одном объекте не останавливает удаление остальных, все ошибки возвращаются вместе.
+func (u *UseCase) PurgeTrash(retention time.Duration) error {
+	deletedBefore := time.Now().Add(-retention)
+	errs := []error{}
+
+	cardsIds, err := u.cardsRepoRead.GetCardsDeletedBefore(deletedBefore)
+	if err != nil {
+		errs = append(errs, u.errorsMapper.DBErrorToApp(err))
+	}
+	for _, cardId := range cardsIds {
+		if err = u.purgeCard(cardId); err != nil {
+			errs = append(errs, fmt.Errorf("card %d: %w", cardId, err))
+		}
+	}
+
+	modulesIds, err := u.moduleRepoRead.GetModulesDeletedBefore(deletedBefore)
+	if err != nil {
+		errs = append(errs, u.errorsMapper.DBErrorToApp(err))
+	}
+	for _, moduleId := range modulesIds {
+		if err = u.purgeModule(moduleId); err != nil {
+			errs = append(errs, fmt.Errorf("module %d: %w", moduleId, err))
+		}
+	}
+
+	categoriesIds, err := u.categoryRepoRead.GetCategoriesDeletedBefore(deletedBefore)
+	if err != nil {
+		errs = append(errs, u.errorsMapper.DBErrorToApp(err))
+	}
+	for _, categoryId := range categoriesIds {
+		if err = u.purgeCategory(categoryId); err != nil {
+			errs = append(errs, fmt.Errorf("category %d: %w", categoryId, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (u *UseCase) purgeCard(cardId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.cardMutex.Lock()
+	defer u.cardMutex.Unlock()
+
+	attachments, err := uow.GetAttachmentsRepoReader().GetAttachmentsToCard(cardId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.GetAttachmentsRepoWriter().DeleteAttachmentsToCard(cardId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	// результаты по карточке остаются в истории учеников
+	err = uow.GetCardRepoWriter().DeleteCard(cardId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	u.deleteBlobs(attachments)
+	return nil
+}
+
+func (u *UseCase) purgeModule(moduleId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.moduleMutex.Lock()
+	u.selectedMutex.Lock()
+	defer func() {
+		u.moduleMutex.Unlock()
+		u.selectedMutex.Unlock()
+	}()
+
+	attachments, err := uow.GetAttachmentsRepoReader().GetAttachmentsToModule(moduleId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+	if err = uow.GetAttachmentsRepoWriter().DeleteAttachmentsToModule(moduleId); err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	err = u.deleteCardsToParentModule(moduleId, uow)
+	if err != nil {
+		return err
+	}
+
+	err = u.deleteModuleFromCategories(moduleId, uow)
+	if err != nil {
+		return err
+	}
+
+	err = u.deleteResultByModuleId(moduleId, uow)
+	if err != nil {
+		return err
+	}
+
+	err = u.deleteModuleResFromCategories(moduleId, uow)
+	if err != nil {
+		return err
+	}
+
+	err = uow.GetSelectedRepoWriter().DeleteAllToModule(moduleId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	err = uow.GetModuleRepoWriter().DeleteModule(moduleId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	u.deleteBlobs(attachments)
+	return nil
+}
+
+func (u *UseCase) purgeCategory(categoryId int) error {
+	uow := u.unitOfWorkFactory()
+	if err := uow.Begin(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	defer uow.Rollback()
+
+	u.categoryMutex.Lock()
+	u.selectedMutex.Lock()
+	defer func() {
+		u.categoryMutex.Unlock()
+		u.selectedMutex.Unlock()
+	}()
+
+	err := u.deleteAllModulesFromCategory(categoryId, uow)
+	if err != nil {
+		return err
+	}
+
+	err = u.deleteResultByCategoryId(categoryId, uow)
+	if err != nil {
+		return err
+	}
+
+	err = uow.GetSelectedRepoWriter().DeleteAllToCategory(categoryId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	err = uow.GetCategoryRepoWriter().DeleteCategory(categoryId)
+	if err != nil {
+		return u.errorsMapper.DBErrorToApp(err)
+	}
+
+	if err = uow.Commit(); err != nil {
+		return usecase.NewInternalError(err)
+	}
+	return nil
+}
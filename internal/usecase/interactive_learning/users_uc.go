@@ -18,6 +18,10 @@ func (u *UseCase) GetUsersWithSimilarName(name string, limit, offset int) ([]ent
 }
 
 func (u *UseCase) GetUserInfoById(ownerId int, isFull bool, userId int) (entity.User, error) {
+	if res := usecase.UserResource(ownerId); !u.policy.CanView(userId, res) {
+		return entity.User{}, u.policy.Deny(res)
+	}
+
 	user, err := u.usersRepoRead.GetUserInfoById(ownerId)
 	if err != nil {
 		return entity.User{}, u.errorsMapper.DBErrorToApp(err)
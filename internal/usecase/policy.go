@@ -0,0 +1,193 @@
+package usecase
+
+import "interactive_learning/internal/entity"
+
+// типы объектов, к которым проверяется доступ
+const (
+	UserObject     = "user"
+	CardObject     = "card"
+	ModuleObject   = "module"
+	CategoryObject = "category"
+	ResultObject   = "result"
+	GroupObject    = "group"
+	CommentObject  = "comment"
+	// очередь жалоб и журнал модерации
+	ModerationObject = "moderation"
+)
+
+// Resource - то, что политике нужно знать об объекте, чтобы решить вопрос доступа
+type Resource struct {
+	Kind    string
+	Id      int
+	OwnerId int
+	Private bool
+	// открывается по ссылке, но не показывается в списках
+	Unlisted bool
+	// скрыт администратором по жалобе
+	Hidden bool
+	// для комментариев - автор модуля или категории, к которым они относятся
+	ContentOwnerId int
+	// роли пользователей, с которыми поделились объектом. Для результатов -
+	// преподаватели групп владельца, для групп - роли участников
+	Roles map[int]string
+}
+
+func UserResource(userId int) Resource {
+	return Resource{Kind: UserObject, Id: userId, OwnerId: userId}
+}
+
+func ModuleResource(module entity.Module) Resource {
+	return Resource{Kind: ModuleObject, Id: module.Id, OwnerId: module.OwnerId,
+		Private: module.Type == entity.PrivateModule, Unlisted: module.Type == entity.UnlistedModule, Hidden: module.Hidden}
+}
+
+// CardResource - карточка наследует доступ своего модуля
+func CardResource(cardId int, module entity.Module) Resource {
+	return Resource{Kind: CardObject, Id: cardId, OwnerId: module.OwnerId,
+		Private: module.Type == entity.PrivateModule, Unlisted: module.Type == entity.UnlistedModule, Hidden: module.Hidden}
+}
+
+// CategoryResource - доступ определяет действующая видимость, а не выбор владельца
+func CategoryResource(category entity.Category) Resource {
+	return Resource{Kind: CategoryObject, Id: category.Id, OwnerId: category.OwnerId,
+		Private: category.Type == entity.PrivateCategory, Unlisted: category.Type == entity.UnlistedCategory, Hidden: category.Hidden}
+}
+
+// ResultResource - результат ученика. Автор пройденного модуля или категории его не видит,
+// кроме владельца результат открыт только преподавателям его групп из Roles
+func ResultResource(resultId, ownerId int) Resource {
+	return Resource{Kind: ResultObject, Id: resultId, OwnerId: ownerId, Private: true}
+}
+
+// GroupResource - учебная группа, roles - роли ее участников
+func GroupResource(group entity.Group, roles map[int]string) Resource {
+	return Resource{Kind: GroupObject, Id: group.Id, OwnerId: group.OwnerId, Private: true, Roles: roles}
+}
+
+// CommentResource - комментарий к модулю или категории автора contentOwnerId
+func CommentResource(comment entity.Comment, contentOwnerId int) Resource {
+	return Resource{Kind: CommentObject, Id: comment.Id, OwnerId: comment.UserId, ContentOwnerId: contentOwnerId}
+}
+
+// ModerationResource - модерация доступна только администраторам
+func ModerationResource(userId int, isAdmin bool) Resource {
+	res := Resource{Kind: ModerationObject, Id: userId, Private: true}
+	if isAdmin {
+		res.Roles = map[int]string{userId: entity.AdminRole}
+	}
+	return res
+}
+
+// Policy решает, что пользователь может делать с объектом. Все проверки доступа
+// в сценариях должны проходить через нее.
+type Policy struct{}
+
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+func (p *Policy) CanView(userId int, res Resource) bool {
+	switch res.Kind {
+	case UserObject:
+		return true
+	case CardObject, ModuleObject, CategoryObject:
+		// объекты по ссылке открываются только через нее, см. CanOpenLink
+		return (!res.Private && !res.Unlisted) || res.OwnerId == userId || res.Roles[userId] != ""
+	case ResultObject:
+		return res.OwnerId == userId || res.Roles[userId] == entity.TeacherRole
+	case GroupObject:
+		return res.OwnerId == userId || res.Roles[userId] != ""
+	case CommentObject:
+		return true
+	case ModerationObject:
+		return res.Roles[userId] == entity.AdminRole
+	}
+	return false
+}
+
+// CanOpenLink - открывается ли объект по ссылке. Объект по ссылке открывается
+// всем, у кого она есть, приватный - только тем, кому он доступен и без нее.
+func (p *Policy) CanOpenLink(userId int, res Resource) bool {
+	switch res.Kind {
+	case CardObject, ModuleObject, CategoryObject:
+		return res.Unlisted || p.CanView(userId, res)
+	}
+	return false
+}
+
+// CanList - показывать ли объект в списке модулей и категорий автора.
+// Объекты по ссылке там видят только владелец и те, с кем ими поделились.
+func (p *Policy) CanList(userId int, res Resource) bool {
+	if !p.CanView(userId, res) {
+		return false
+	}
+	return !res.Unlisted || res.OwnerId == userId || res.Roles[userId] != ""
+}
+
+func (p *Policy) CanEdit(userId int, res Resource) bool {
+	switch res.Kind {
+	case UserObject:
+		return res.OwnerId == userId
+	case CardObject, ModuleObject, CategoryObject:
+		return res.OwnerId == userId || res.Roles[userId] == entity.EditorRole
+	case GroupObject:
+		// состав группы и ее материалы меняет преподаватель
+		return res.OwnerId == userId || res.Roles[userId] == entity.TeacherRole
+	case CommentObject:
+		return res.OwnerId == userId
+	case ModerationObject:
+		return res.Roles[userId] == entity.AdminRole
+	}
+	// результаты не редактируются
+	return false
+}
+
+func (p *Policy) CanDelete(userId int, res Resource) bool {
+	switch res.Kind {
+	case UserObject, CardObject, ModuleObject, CategoryObject, ResultObject, GroupObject:
+		return res.OwnerId == userId
+	case CommentObject:
+		// автор модуля или категории модерирует комментарии к ним
+		return res.OwnerId == userId || res.ContentOwnerId == userId
+	}
+	return false
+}
+
+// CanShare - менять видимость объекта и выдавать доступ к нему может только владелец
+func (p *Policy) CanShare(userId int, res Resource) bool {
+	switch res.Kind {
+	case CardObject, ModuleObject, CategoryObject:
+		return res.OwnerId == userId
+	}
+	return false
+}
+
+// CanComment - комментировать можно только открытые модули и категории,
+// которые не скрыл администратор
+func (p *Policy) CanComment(userId int, res Resource) bool {
+	switch res.Kind {
+	case ModuleObject, CategoryObject:
+		return !res.Private && !res.Unlisted && !res.Hidden
+	}
+	return false
+}
+
+// CanRate - оценивать открытые модули и категории может любой, кроме автора
+func (p *Policy) CanRate(userId int, res Resource) bool {
+	return p.CanComment(userId, res) && res.OwnerId != userId
+}
+
+// CanReport - пожаловаться можно на доступный пользователю чужой объект,
+// скрытый объект уже снят администратором
+func (p *Policy) CanReport(userId int, res Resource) bool {
+	switch res.Kind {
+	case CardObject, ModuleObject, CategoryObject, CommentObject:
+		return p.CanView(userId, res) && res.OwnerId != userId && !res.Hidden
+	}
+	return false
+}
+
+// Deny возвращает ошибку отказа в доступе к объекту
+func (p *Policy) Deny(res Resource) error {
+	return NewNotAvailableError(res.Kind, res.Id)
+}
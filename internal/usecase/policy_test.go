@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"interactive_learning/internal/entity"
+	"testing"
+)
+
+const (
+	ownerId    = 1
+	strangerId = 2
+	authorId   = 3
+	viewerId   = 4
+	editorId   = 5
+)
+
+// perm - набор прав, которые политика должна дать пользователю. Все остальные
+// проверки для того же случая должны вернуть отказ.
+type perm uint
+
+const (
+	view perm = 1 << iota
+	list
+	edit
+	del
+	share
+	comment
+	rate
+	report
+	link
+
+	// все права владельца на модуль, карточку или категорию
+	owned = view | list | edit | del | share | link
+)
+
+func TestPolicy(t *testing.T) {
+	const (
+		studentId = 6
+		adminId   = 7
+	)
+
+	publicModule := entity.Module{Id: 10, OwnerId: ownerId, Type: entity.PublicModule}
+	privateModule := entity.Module{Id: 11, OwnerId: ownerId, Type: entity.PrivateModule}
+	unlistedModule := entity.Module{Id: 12, OwnerId: ownerId, Type: entity.UnlistedModule}
+	hiddenModule := entity.Module{Id: 13, OwnerId: ownerId, Type: entity.PublicModule, Hidden: true}
+	publicCategory := entity.Category{Id: 20, OwnerId: ownerId, Type: entity.PublicCategory}
+	privateCategory := entity.Category{Id: 21, OwnerId: ownerId, Type: entity.PrivateCategory, Visibility: entity.PrivateCategory}
+	// владелец открыл категорию, но приватный модуль внутри ее закрывает
+	closedCategory := entity.Category{Id: 22, OwnerId: ownerId, Type: entity.PrivateCategory, Visibility: entity.PublicCategory}
+	unlistedCategory := entity.Category{Id: 23, OwnerId: ownerId, Type: entity.UnlistedCategory, Visibility: entity.UnlistedCategory}
+	// приватный модуль делает категорию приватной, даже если владелец выбрал доступ по ссылке
+	closedUnlistedCategory := entity.Category{Id: 24, OwnerId: ownerId, Type: entity.PrivateCategory, Visibility: entity.UnlistedCategory}
+	hiddenCategory := entity.Category{Id: 25, OwnerId: ownerId, Type: entity.PublicCategory, Visibility: entity.PublicCategory, Hidden: true}
+
+	withRoles := func(res Resource, roles map[int]string) Resource {
+		res.Roles = roles
+		return res
+	}
+	grants := map[int]string{viewerId: entity.ViewerRole, editorId: entity.EditorRole}
+	sharedModule := withRoles(ModuleResource(privateModule), grants)
+	sharedCard := withRoles(CardResource(101, privateModule), grants)
+	sharedCategory := withRoles(CategoryResource(privateCategory), grants)
+	sharedUnlistedModule := withRoles(ModuleResource(unlistedModule), map[int]string{viewerId: entity.ViewerRole})
+
+	group := GroupResource(entity.Group{Id: 40, OwnerId: ownerId},
+		map[int]string{ownerId: entity.TeacherRole, studentId: entity.StudentRole})
+	// ученик группы, где преподает ownerId, прошел модуль автора
+	studentResult := withRoles(ResultResource(32, studentId), map[int]string{ownerId: entity.TeacherRole})
+
+	// strangerId прокомментировал модуль ownerId
+	strangerComment := CommentResource(entity.Comment{Id: 50, UserId: strangerId}, ownerId)
+
+	tests := []struct {
+		name   string
+		res    Resource
+		userId int
+		want   perm
+	}{
+		{"user itself", UserResource(ownerId), ownerId, view | list | edit | del},
+		{"user by other user", UserResource(ownerId), strangerId, view | list},
+
+		{"public module by owner", ModuleResource(publicModule), ownerId, owned | comment},
+		{"public module by stranger", ModuleResource(publicModule), strangerId, view | list | comment | rate | report | link},
+		{"private module by owner", ModuleResource(privateModule), ownerId, owned},
+		{"private module by stranger", ModuleResource(privateModule), strangerId, 0},
+		{"unlisted module by owner", ModuleResource(unlistedModule), ownerId, owned},
+		{"unlisted module by stranger", ModuleResource(unlistedModule), strangerId, link},
+		{"hidden module by owner", ModuleResource(hiddenModule), ownerId, owned},
+		{"hidden module by stranger", ModuleResource(hiddenModule), strangerId, view | list | link},
+
+		{"card of public module by owner", CardResource(100, publicModule), ownerId, owned},
+		{"card of public module by stranger", CardResource(100, publicModule), strangerId, view | list | report | link},
+		{"card of private module by owner", CardResource(101, privateModule), ownerId, owned},
+		{"card of private module by stranger", CardResource(101, privateModule), strangerId, 0},
+		{"card of unlisted module by stranger", CardResource(102, unlistedModule), strangerId, link},
+		{"card of hidden module by stranger", CardResource(103, hiddenModule), strangerId, view | list | link},
+
+		{"public category by owner", CategoryResource(publicCategory), ownerId, owned | comment},
+		{"public category by stranger", CategoryResource(publicCategory), strangerId, view | list | comment | rate | report | link},
+		{"private category by owner", CategoryResource(privateCategory), ownerId, owned},
+		{"private category by stranger", CategoryResource(privateCategory), strangerId, 0},
+		{"public category with private module by owner", CategoryResource(closedCategory), ownerId, owned},
+		{"public category with private module by stranger", CategoryResource(closedCategory), strangerId, 0},
+		{"unlisted category by owner", CategoryResource(unlistedCategory), ownerId, owned},
+		{"unlisted category by stranger", CategoryResource(unlistedCategory), strangerId, link},
+		{"hidden category by owner", CategoryResource(hiddenCategory), ownerId, owned},
+		{"hidden category by stranger", CategoryResource(hiddenCategory), strangerId, view | list | link},
+		{"unlisted category with private module by stranger", CategoryResource(closedUnlistedCategory), strangerId, 0},
+
+		{"shared module by owner", sharedModule, ownerId, owned},
+		{"shared module by viewer", sharedModule, viewerId, view | list | report | link},
+		{"shared module by editor", sharedModule, editorId, view | list | edit | report | link},
+		{"shared module by stranger", sharedModule, strangerId, 0},
+		{"card of shared module by viewer", sharedCard, viewerId, view | list | report | link},
+		{"card of shared module by editor", sharedCard, editorId, view | list | edit | report | link},
+		{"card of shared module by stranger", sharedCard, strangerId, 0},
+		{"shared category by owner", sharedCategory, ownerId, owned},
+		{"shared category by viewer", sharedCategory, viewerId, view | list | report | link},
+		{"shared category by editor", sharedCategory, editorId, view | list | edit | report | link},
+		{"shared category by stranger", sharedCategory, strangerId, 0},
+		{"shared unlisted module by viewer", sharedUnlistedModule, viewerId, view | list | report | link},
+
+		{"result by its owner", ResultResource(30, ownerId), ownerId, view | list | del},
+		{"result by stranger", ResultResource(30, ownerId), strangerId, 0},
+
+		{"group by teacher", group, ownerId, view | list | edit | del},
+		{"group by student", group, studentId, view | list},
+		{"group by stranger", group, strangerId, 0},
+		{"student result by teacher", studentResult, ownerId, view | list},
+		{"student result by student", studentResult, studentId, view | list | del},
+		// автор модуля не видит чужие ответы на свои карточки
+		{"student result by content author", studentResult, authorId, 0},
+		{"student result by stranger", studentResult, strangerId, 0},
+
+		{"comment by its author", strangerComment, strangerId, view | list | edit | del},
+		{"comment by content owner", strangerComment, ownerId, view | list | del | report},
+		{"comment by other user", strangerComment, viewerId, view | list | report},
+
+		{"moderation queue by admin", ModerationResource(adminId, true), adminId, view | list | edit},
+		{"moderation queue by user", ModerationResource(strangerId, false), strangerId, 0},
+
+		{"unknown object", Resource{Kind: "attachment", Id: 1, OwnerId: ownerId}, ownerId, 0},
+	}
+
+	policy := NewPolicy()
+	checks := []struct {
+		perm  perm
+		name  string
+		check func(userId int, res Resource) bool
+	}{
+		{view, "CanView", policy.CanView},
+		{list, "CanList", policy.CanList},
+		{edit, "CanEdit", policy.CanEdit},
+		{del, "CanDelete", policy.CanDelete},
+		{share, "CanShare", policy.CanShare},
+		{comment, "CanComment", policy.CanComment},
+		{rate, "CanRate", policy.CanRate},
+		{report, "CanReport", policy.CanReport},
+		{link, "CanOpenLink", policy.CanOpenLink},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range checks {
+				if got, want := c.check(tt.userId, tt.res), tt.want&c.perm != 0; got != want {
+					t.Errorf("%s() = %v, want %v", c.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyDeny(t *testing.T) {
+	err := NewPolicy().Deny(CardResource(7, entity.Module{Id: 1, OwnerId: ownerId}))
+
+	na, ok := err.(*NotAvailable)
+	if !ok {
+		t.Fatalf("Deny() returned %T, want *NotAvailable", err)
+	}
+	if na.objectType != CardObject || na.objectId != 7 {
+		t.Errorf("Deny() = %s %d, want %s 7", na.objectType, na.objectId, CardObject)
+	}
+}
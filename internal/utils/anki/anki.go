@@ -0,0 +1,257 @@
+// Package anki переводит колоды Anki (.apkg) в выгрузки и обратно.
+// Поддерживаются коллекции в формате collection.anki2/collection.anki21,
+// из заметок берутся первые два поля (лицевая и оборотная стороны).
+package anki
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"html"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/utils/sqlitefile"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	legacyCollection = "collection.anki2"
+	collection21     = "collection.anki21"
+	// новый формат сжат zstd, его Anki пишет без флажка "поддержка старых версий"
+	collection21b = "collection.anki21b"
+	mediaFile     = "media"
+
+	deckSeparator = "::"
+	fieldSep      = "\x1f"
+
+	clozeModel = 1
+
+	maxCollectionSize = 256 << 20
+)
+
+var ErrUnsupportedCollection = errors.New("collection is exported in the new Anki format, export it with \"Support older Anki versions\"")
+
+type Options struct {
+	Name     string // название модуля или категории, по умолчанию берется из колоды
+	TermLang string
+	DefLang  string
+	Type     int
+}
+
+type deck struct {
+	id    int64
+	name  string
+	cards []entity.CardToAdd
+}
+
+// Read разбирает .apkg: колода с карточками становится модулем,
+// дерево колод - категорией с модулями. Медиафайлы пропускаются.
+func Read(r io.ReaderAt, size int64, opts Options) (entity.Bundle, error) {
+	if opts.TermLang == "" || opts.DefLang == "" {
+		return entity.Bundle{}, errors.New("empty term or definition lang")
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return entity.Bundle{}, err
+	}
+	data, err := readCollection(archive)
+	if err != nil {
+		return entity.Bundle{}, err
+	}
+	db, err := sqlitefile.NewReader(data)
+	if err != nil {
+		return entity.Bundle{}, err
+	}
+
+	decks, err := readDecks(db)
+	if err != nil {
+		return entity.Bundle{}, err
+	}
+	if err = readCards(db, decks, opts); err != nil {
+		return entity.Bundle{}, err
+	}
+
+	filled := []*deck{}
+	for _, d := range decks {
+		if len(d.cards) > 0 {
+			filled = append(filled, d)
+		}
+	}
+	if len(filled) == 0 {
+		return entity.Bundle{}, errors.New("collection contains no basic notes")
+	}
+	sortDecks(filled)
+
+	return toBundle(filled, opts), nil
+}
+
+func readCollection(archive *zip.Reader) ([]byte, error) {
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	file, ok := files[collection21]
+	if !ok {
+		if _, ok = files[collection21b]; ok {
+			return nil, ErrUnsupportedCollection
+		}
+		if file, ok = files[legacyCollection]; !ok {
+			return nil, errors.New("apkg does not contain a collection")
+		}
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxCollectionSize+1))
+	if err != nil {
+		return nil, err
+	} else if len(data) > maxCollectionSize {
+		return nil, errors.New("collection is too large")
+	}
+	return data, nil
+}
+
+func readCards(db *sqlitefile.Reader, decks map[int64]*deck, opts Options) error {
+	clozeModels, err := readClozeModels(db)
+	if err != nil {
+		return err
+	}
+
+	// колода заметки определяется ее первой карточкой
+	cardRows, err := db.Rows("cards")
+	if err != nil {
+		return err
+	}
+	noteDeck := map[int64]int64{}
+	noteOrd := map[int64]int64{}
+	for _, row := range cardRows {
+		nid, did, ord := row.Int(1), row.Int(2), row.Int(3)
+		if prevOrd, ok := noteOrd[nid]; ok && prevOrd <= ord {
+			continue
+		}
+		noteDeck[nid], noteOrd[nid] = did, ord
+	}
+
+	noteRows, err := db.Rows("notes")
+	if err != nil {
+		return err
+	}
+	for _, row := range noteRows {
+		if clozeModels[row.Int(2)] {
+			continue
+		}
+		d, ok := decks[noteDeck[row.Rowid]]
+		if !ok {
+			continue
+		}
+
+		// в схеме 18 типы заметок не разобрать без protobuf, cloze узнаем по разметке
+		fields := strings.Split(row.Text(6), fieldSep)
+		if len(fields) < 2 || strings.Contains(fields[0], "{{c") {
+			continue
+		}
+		term, definition := fieldToText(fields[0]), fieldToText(fields[1])
+		if term == "" || definition == "" {
+			continue
+		}
+
+		d.cards = append(d.cards, entity.CardToAdd{
+			Term:       entity.TextWithLang{Lang: opts.TermLang, Text: term},
+			Definition: entity.TextWithLang{Lang: opts.DefLang, Text: definition},
+		})
+	}
+	return nil
+}
+
+func toBundle(decks []*deck, opts Options) entity.Bundle {
+	categoryType := entity.PublicCategory
+	if opts.Type == entity.PrivateModule {
+		categoryType = entity.PrivateCategory
+	} else if opts.Type == entity.UnlistedModule {
+		categoryType = entity.UnlistedCategory
+	}
+
+	if len(decks) == 1 {
+		name := opts.Name
+		if name == "" {
+			parts := strings.Split(decks[0].name, deckSeparator)
+			name = parts[len(parts)-1]
+		}
+		return entity.Bundle{
+			Manifest: manifest(entity.ModuleBundle),
+			Content: entity.BundleContent{
+				Modules: []entity.BundleModule{{Name: name, Type: opts.Type, Cards: decks[0].cards}},
+			},
+		}
+	}
+
+	root := commonRoot(decks)
+	content := entity.BundleContent{Name: opts.Name, Type: categoryType}
+	if content.Name == "" {
+		content.Name = root
+	}
+	if content.Name == "" {
+		content.Name = "Anki"
+	}
+
+	for _, d := range decks {
+		name := d.name
+		if root != "" && name != root {
+			name = strings.TrimPrefix(name, root+deckSeparator)
+		}
+		content.Modules = append(content.Modules, entity.BundleModule{Name: name, Type: opts.Type, Cards: d.cards})
+	}
+	return entity.Bundle{Manifest: manifest(entity.CategoryBundle), Content: content}
+}
+
+func commonRoot(decks []*deck) string {
+	root := strings.Split(decks[0].name, deckSeparator)[0]
+	for _, d := range decks[1:] {
+		if strings.Split(d.name, deckSeparator)[0] != root {
+			return ""
+		}
+	}
+	return root
+}
+
+func manifest(kind string) entity.BundleManifest {
+	return entity.BundleManifest{Format: entity.BundleFormat, Version: entity.BundleVersion, Kind: kind}
+}
+
+var (
+	lineBreakRe = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>`)
+	tagRe       = regexp.MustCompile(`<[^>]*>`)
+	soundRe     = regexp.MustCompile(`\[sound:[^\]]*\]`)
+)
+
+func fieldToText(field string) string {
+	text := lineBreakRe.ReplaceAllString(field, "\n")
+	text = tagRe.ReplaceAllString(text, "")
+	text = soundRe.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	return strings.TrimSpace(text)
+}
+
+func textToField(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+func deckName(parts ...string) string {
+	for i, part := range parts {
+		// разделитель в названии создал бы лишний уровень дерева
+		parts[i] = strings.ReplaceAll(part, deckSeparator, ":")
+	}
+	return strings.Join(parts, deckSeparator)
+}
+
+func unexpected(table string, err error) error {
+	return fmt.Errorf("bad %s table: %w", table, err)
+}
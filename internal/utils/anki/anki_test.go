@@ -0,0 +1,301 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/utils/sqlitefile"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+const (
+	basicModelId = 100
+	clozeModelId = 200
+)
+
+type testNote struct {
+	deckId int64
+	model  int64
+	fields string
+}
+
+type testCollection struct {
+	decks map[int64]string
+	notes []testNote
+	// в схеме 18 колоды лежат в таблице decks, а не в col
+	schema18 bool
+}
+
+func (c testCollection) sqlite(t testing.TB) []byte {
+	t.Helper()
+
+	decks := map[string]any{}
+	deckRows := []sqlitefile.Row{}
+	for id, name := range c.decks {
+		decks[strconv.FormatInt(id, 10)] = map[string]any{"id": id, "name": name}
+		deckRows = append(deckRows, sqlitefile.Row{Rowid: id, Values: []any{nil, name}})
+	}
+	sort.Slice(deckRows, func(i, j int) bool { return deckRows[i].Rowid < deckRows[j].Rowid })
+	models := map[string]any{
+		strconv.Itoa(basicModelId): map[string]any{"id": basicModelId, "type": 0},
+		strconv.Itoa(clozeModelId): map[string]any{"id": clozeModelId, "type": clozeModel},
+	}
+	decksJSON, _ := json.Marshal(decks)
+	modelsJSON, _ := json.Marshal(models)
+	if c.schema18 {
+		decksJSON, modelsJSON = []byte("{}"), []byte("{}")
+	}
+
+	colValues := make([]any, 13)
+	colValues[colModels], colValues[colDecks] = string(modelsJSON), string(decksJSON)
+
+	notes := []sqlitefile.Row{}
+	cards := []sqlitefile.Row{}
+	for i, note := range c.notes {
+		id := int64(i + 1)
+		notes = append(notes, sqlitefile.Row{Rowid: id, Values: []any{nil, "guid", note.model, int64(0), int64(0), "", note.fields}})
+		cards = append(cards, sqlitefile.Row{Rowid: id, Values: []any{nil, id, note.deckId, int64(0)}})
+	}
+
+	tables := []sqlitefile.Table{
+		{Name: "col", SQL: colSQL, Rows: []sqlitefile.Row{{Rowid: 1, Values: colValues}}},
+		{Name: "notes", SQL: notesSQL, Rows: notes},
+		{Name: "cards", SQL: cardsSQL, Rows: cards},
+	}
+	if c.schema18 {
+		tables = append(tables, sqlitefile.Table{Name: "decks", SQL: "CREATE TABLE decks (id integer primary key, name text)", Rows: deckRows})
+	}
+
+	var buf bytes.Buffer
+	if err := sqlitefile.Write(&buf, tables); err != nil {
+		t.Fatalf("write collection: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func apkg(t testing.TB, name string, collection []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	fw, err := archive.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fw.Write(collection); err != nil {
+		t.Fatal(err)
+	}
+	if err = archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func card(term, definition string) entity.CardToAdd {
+	return entity.CardToAdd{
+		Term:       entity.TextWithLang{Lang: "en", Text: term},
+		Definition: entity.TextWithLang{Lang: "ru", Text: definition},
+	}
+}
+
+func TestRead(t *testing.T) {
+	defaultOpts := Options{TermLang: "en", DefLang: "ru", Type: entity.PublicModule}
+
+	tests := []struct {
+		name       string
+		collection testCollection
+		opts       Options
+		want       entity.Bundle
+		wantErr    bool
+	}{
+		{
+			name: "single deck",
+			collection: testCollection{
+				decks: map[int64]string{1: "Default", 2: "Languages::English"},
+				notes: []testNote{{2, basicModelId, "cat\x1fкошка"}, {2, basicModelId, "dog\x1fсобака"}},
+			},
+			opts: defaultOpts,
+			want: entity.Bundle{
+				Manifest: manifest(entity.ModuleBundle),
+				Content: entity.BundleContent{Modules: []entity.BundleModule{
+					{Name: "English", Cards: []entity.CardToAdd{card("cat", "кошка"), card("dog", "собака")}},
+				}},
+			},
+		},
+		{
+			name: "field split and html",
+			collection: testCollection{
+				decks: map[int64]string{2: "Words"},
+				notes: []testNote{
+					{2, basicModelId, "<b>cat</b>&nbsp;[sound:cat.mp3]\x1fкошка<br>кот\x1fлишнее поле"},
+					{2, basicModelId, "only front"},
+					{2, basicModelId, "<img src=a.png>\x1fпустой термин"},
+				},
+			},
+			opts: Options{Name: "Животные", TermLang: "en", DefLang: "ru", Type: entity.PrivateModule},
+			want: entity.Bundle{
+				Manifest: manifest(entity.ModuleBundle),
+				Content: entity.BundleContent{Modules: []entity.BundleModule{
+					{Name: "Животные", Type: entity.PrivateModule, Cards: []entity.CardToAdd{card("cat", "кошка\nкот")}},
+				}},
+			},
+		},
+		{
+			name: "deck tree",
+			collection: testCollection{
+				decks: map[int64]string{2: "Lang", 3: "Lang::Words", 4: "Lang::Verbs"},
+				notes: []testNote{{3, basicModelId, "cat\x1fкошка"}, {4, basicModelId, "run\x1fбежать"}},
+			},
+			opts: defaultOpts,
+			want: entity.Bundle{
+				Manifest: manifest(entity.CategoryBundle),
+				Content: entity.BundleContent{Name: "Lang", Modules: []entity.BundleModule{
+					{Name: "Verbs", Cards: []entity.CardToAdd{card("run", "бежать")}},
+					{Name: "Words", Cards: []entity.CardToAdd{card("cat", "кошка")}},
+				}},
+			},
+		},
+		{
+			name: "decks without common root",
+			collection: testCollection{
+				decks: map[int64]string{2: "English", 3: "German"},
+				notes: []testNote{{2, basicModelId, "cat\x1fкошка"}, {3, basicModelId, "Katze\x1fкошка"}},
+			},
+			opts: Options{TermLang: "en", DefLang: "ru", Type: entity.UnlistedModule},
+			want: entity.Bundle{
+				Manifest: manifest(entity.CategoryBundle),
+				Content: entity.BundleContent{Name: "Anki", Type: entity.UnlistedCategory, Modules: []entity.BundleModule{
+					{Name: "English", Type: entity.UnlistedModule, Cards: []entity.CardToAdd{card("cat", "кошка")}},
+					{Name: "German", Type: entity.UnlistedModule, Cards: []entity.CardToAdd{card("Katze", "кошка")}},
+				}},
+			},
+		},
+		{
+			name: "cloze notes are skipped",
+			collection: testCollection{
+				decks: map[int64]string{2: "Words"},
+				notes: []testNote{
+					{2, clozeModelId, "a {{c1::cat}}\x1f"},
+					{2, basicModelId, "{{c1::dog}}\x1fсобака"},
+					{2, basicModelId, "cat\x1fкошка"},
+				},
+			},
+			opts: defaultOpts,
+			want: entity.Bundle{
+				Manifest: manifest(entity.ModuleBundle),
+				Content:  entity.BundleContent{Modules: []entity.BundleModule{{Name: "Words", Cards: []entity.CardToAdd{card("cat", "кошка")}}}},
+			},
+		},
+		{
+			name: "schema 18 decks table",
+			collection: testCollection{
+				decks:    map[int64]string{2: "Lang\x1fWords"},
+				notes:    []testNote{{2, basicModelId, "cat\x1fкошка"}},
+				schema18: true,
+			},
+			opts: defaultOpts,
+			want: entity.Bundle{
+				Manifest: manifest(entity.ModuleBundle),
+				Content:  entity.BundleContent{Modules: []entity.BundleModule{{Name: "Words", Cards: []entity.CardToAdd{card("cat", "кошка")}}}},
+			},
+		},
+		{
+			name:       "empty deck",
+			collection: testCollection{decks: map[int64]string{2: "Words"}},
+			opts:       defaultOpts,
+			wantErr:    true,
+		},
+		{
+			name: "notes of unknown deck",
+			collection: testCollection{
+				decks: map[int64]string{2: "Words"},
+				notes: []testNote{{5, basicModelId, "cat\x1fкошка"}},
+			},
+			opts:    defaultOpts,
+			wantErr: true,
+		},
+		{
+			name:       "no langs",
+			collection: testCollection{decks: map[int64]string{2: "Words"}, notes: []testNote{{2, basicModelId, "cat\x1fкошка"}}},
+			opts:       Options{},
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := apkg(t, legacyCollection, test.collection.sqlite(t))
+			bundle, err := Read(bytes.NewReader(data), int64(len(data)), test.opts)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(bundle, test.want) {
+				t.Errorf("got %+v, want %+v", bundle, test.want)
+			}
+		})
+	}
+}
+
+func TestReadCollectionFiles(t *testing.T) {
+	collection := testCollection{decks: map[int64]string{2: "Words"}, notes: []testNote{{2, basicModelId, "cat\x1fкошка"}}}
+	opts := Options{TermLang: "en", DefLang: "ru"}
+
+	tests := []struct {
+		name    string
+		file    string
+		wantErr error
+	}{
+		{"legacy collection", legacyCollection, nil},
+		{"anki 2.1 collection", collection21, nil},
+		{"zstd collection", collection21b, ErrUnsupportedCollection},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := apkg(t, test.file, collection.sqlite(t))
+			_, err := Read(bytes.NewReader(data), int64(len(data)), opts)
+			if err != test.wantErr {
+				t.Errorf("got error %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	bundle := entity.Bundle{
+		Manifest: manifest(entity.CategoryBundle),
+		Content: entity.BundleContent{Name: "Lang", Modules: []entity.BundleModule{
+			{Name: "Verbs", Cards: []entity.CardToAdd{card("run", "бежать\nнестись")}},
+			{Name: "Words", Cards: []entity.CardToAdd{card("<cat> & dog", "кошка и собака")}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, bundle); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()), Options{TermLang: "en", DefLang: "ru"})
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !reflect.DeepEqual(got, bundle) {
+		t.Errorf("got %+v, want %+v", got, bundle)
+	}
+}
+
+func FuzzReadCollection(f *testing.F) {
+	f.Add(testCollection{
+		decks: map[int64]string{2: "Lang::Words", 3: "Lang::Verbs"},
+		notes: []testNote{{2, basicModelId, "cat\x1fкошка"}, {3, clozeModelId, "{{c1::run}}\x1f"}},
+	}.sqlite(f))
+	f.Add(testCollection{decks: map[int64]string{2: "Words"}, notes: []testNote{{2, basicModelId, "a\x1fb"}}, schema18: true}.sqlite(f))
+
+	opts := Options{TermLang: "en", DefLang: "ru"}
+	f.Fuzz(func(t *testing.T, collection []byte) {
+		data := apkg(t, legacyCollection, collection)
+		Read(bytes.NewReader(data), int64(len(data)), opts)
+	})
+}
@@ -0,0 +1,289 @@
+package anki
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/utils/sqlitefile"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// колонки таблицы col в схеме 11
+const (
+	colModels = 9
+	colDecks  = 10
+)
+
+func readDecks(db *sqlitefile.Reader) (map[int64]*deck, error) {
+	decks := map[int64]*deck{}
+
+	colRows, err := db.Rows("col")
+	if err != nil {
+		return nil, err
+	} else if len(colRows) == 0 {
+		return nil, unexpected("col", errors.New("no rows"))
+	}
+
+	if raw := colRows[0].Text(colDecks); raw != "" && raw != "{}" {
+		legacy := map[string]struct {
+			Id   int64  `json:"id"`
+			Name string `json:"name"`
+		}{}
+		if err := json.Unmarshal([]byte(raw), &legacy); err != nil {
+			return nil, unexpected("col", err)
+		}
+		for _, d := range legacy {
+			decks[d.Id] = &deck{id: d.Id, name: d.Name}
+		}
+		return decks, nil
+	}
+
+	// в схеме 18 колоды лежат в отдельной таблице, уровни разделены \x1f
+	deckRows, err := db.Rows("decks")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range deckRows {
+		decks[row.Rowid] = &deck{id: row.Rowid, name: strings.ReplaceAll(row.Text(1), fieldSep, deckSeparator)}
+	}
+	return decks, nil
+}
+
+func readClozeModels(db *sqlitefile.Reader) (map[int64]bool, error) {
+	colRows, err := db.Rows("col")
+	if err != nil {
+		return nil, err
+	}
+
+	cloze := map[int64]bool{}
+	raw := colRows[0].Text(colModels)
+	if raw == "" || raw == "{}" {
+		return cloze, nil
+	}
+
+	models := map[string]struct {
+		Id   int64 `json:"id"`
+		Type int   `json:"type"`
+	}{}
+	if err := json.Unmarshal([]byte(raw), &models); err != nil {
+		return nil, unexpected("col", err)
+	}
+	for _, model := range models {
+		if model.Type == clozeModel {
+			cloze[model.Id] = true
+		}
+	}
+	return cloze, nil
+}
+
+func sortDecks(decks []*deck) {
+	sort.Slice(decks, func(i, j int) bool {
+		return decks[i].name < decks[j].name
+	})
+}
+
+// Write пишет выгрузку в .apkg с коллекцией схемы 11 и базовым типом заметок
+func Write(w io.Writer, bundle entity.Bundle) error {
+	now := time.Now()
+	base := now.UnixMilli()
+	modelId := base
+
+	decks := []*deck{}
+	switch bundle.Manifest.Kind {
+	case entity.ModuleBundle:
+		for _, module := range bundle.Content.Modules {
+			decks = append(decks, &deck{name: deckName(module.Name)})
+		}
+	case entity.CategoryBundle:
+		decks = append(decks, &deck{name: deckName(bundle.Content.Name)})
+		for _, module := range bundle.Content.Modules {
+			decks = append(decks, &deck{name: deckName(bundle.Content.Name, module.Name)})
+		}
+	default:
+		return errors.New("unknown bundle kind")
+	}
+	for i, d := range decks {
+		d.id = base + int64(i) + 1
+	}
+
+	// у категории первая колода - родительская, без карточек
+	moduleDecks := decks
+	if bundle.Manifest.Kind == entity.CategoryBundle {
+		moduleDecks = decks[1:]
+	}
+
+	notes := []sqlitefile.Row{}
+	cards := []sqlitefile.Row{}
+	noteId := base
+	for i, module := range bundle.Content.Modules {
+		for _, card := range module.Cards {
+			noteId++
+			guid, err := newGuid()
+			if err != nil {
+				return err
+			}
+			sortField := card.Term.Text
+			notes = append(notes, sqlitefile.Row{Rowid: noteId, Values: []any{
+				nil, guid, modelId, now.Unix(), int64(-1), "",
+				textToField(card.Term.Text) + fieldSep + textToField(card.Definition.Text),
+				sortField, checksum(sortField), int64(0), "",
+			}})
+			cards = append(cards, sqlitefile.Row{Rowid: noteId, Values: []any{
+				nil, noteId, moduleDecks[i].id, int64(0), now.Unix(), int64(-1),
+				int64(0), int64(0), int64(len(cards) + 1), int64(0), int64(0), int64(0),
+				int64(0), int64(0), int64(0), int64(0), int64(0), "",
+			}})
+		}
+	}
+
+	colRow, err := collectionRow(now, modelId, decks, len(cards)+1)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	fw, err := archive.Create(legacyCollection)
+	if err != nil {
+		return err
+	}
+	err = sqlitefile.Write(fw, []sqlitefile.Table{
+		{Name: "col", SQL: colSQL, Rows: []sqlitefile.Row{colRow}},
+		{Name: "notes", SQL: notesSQL, Rows: notes},
+		{Name: "cards", SQL: cardsSQL, Rows: cards},
+		{Name: "revlog", SQL: revlogSQL},
+		{Name: "graves", SQL: gravesSQL},
+	})
+	if err != nil {
+		return err
+	}
+
+	if fw, err = archive.Create(mediaFile); err != nil {
+		return err
+	}
+	if _, err = io.WriteString(fw, "{}"); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func collectionRow(now time.Time, modelId int64, decks []*deck, nextPos int) (sqlitefile.Row, error) {
+	decksJSON := map[string]any{"1": deckJSON(1, "Default", now)}
+	for _, d := range decks {
+		decksJSON[strconv.FormatInt(d.id, 10)] = deckJSON(d.id, d.name, now)
+	}
+
+	values := []any{
+		map[string]any{
+			"nextPos": nextPos, "estTimes": true, "activeDecks": []int64{1}, "sortType": "noteFld",
+			"timeLim": 0, "sortBackwards": false, "addToCur": true, "curDeck": 1, "newBust": true,
+			"newSpread": 0, "dueCounts": true, "curModel": strconv.FormatInt(modelId, 10), "collapseTime": 1200,
+		},
+		map[string]any{strconv.FormatInt(modelId, 10): basicModelJSON(modelId, now)},
+		decksJSON,
+		map[string]any{"1": deckConfJSON()},
+	}
+
+	encoded := make([]any, 0, len(values))
+	for _, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return sqlitefile.Row{}, err
+		}
+		encoded = append(encoded, string(data))
+	}
+
+	crt := time.Date(now.Year(), now.Month(), now.Day(), 4, 0, 0, 0, now.Location()).Unix()
+	return sqlitefile.Row{Rowid: 1, Values: []any{
+		nil, crt, now.UnixMilli(), now.UnixMilli(), int64(11), int64(0), int64(0), int64(0),
+		encoded[0], encoded[1], encoded[2], encoded[3], "{}",
+	}}, nil
+}
+
+func deckJSON(id int64, name string, now time.Time) map[string]any {
+	return map[string]any{
+		"id": id, "name": name, "mod": now.Unix(), "usn": -1, "desc": "", "dyn": 0, "conf": 1,
+		"collapsed": false, "browserCollapsed": false, "extendNew": 0, "extendRev": 0,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+func basicModelJSON(id int64, now time.Time) map[string]any {
+	field := func(name string, ord int) map[string]any {
+		return map[string]any{"name": name, "ord": ord, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{}}
+	}
+	return map[string]any{
+		"id": id, "name": "Basic", "type": 0, "mod": now.Unix(), "usn": -1, "sortf": 0, "did": 1,
+		"flds": []any{field("Front", 0), field("Back", 1)},
+		"tmpls": []any{map[string]any{
+			"name": "Card 1", "ord": 0, "qfmt": "{{Front}}", "afmt": "{{FrontSide}}\n\n<hr id=answer>\n\n{{Back}}",
+			"bqfmt": "", "bafmt": "", "did": nil, "bfont": "", "bsize": 0,
+		}},
+		"css":       ".card {\n font-family: arial;\n font-size: 20px;\n text-align: center;\n color: black;\n background-color: white;\n}\n",
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"latexsvg":  false,
+		"req":       []any{[]any{0, "any", []int{0}}},
+		"tags":      []string{},
+		"vers":      []any{},
+	}
+}
+
+func deckConfJSON() map[string]any {
+	return map[string]any{
+		"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true, "timer": 0,
+		"replayq": true, "dyn": false,
+		"new": map[string]any{
+			"delays": []int{1, 10}, "ints": []int{1, 4, 0}, "initialFactor": 2500, "order": 1,
+			"perDay": 20, "bury": false,
+		},
+		"rev": map[string]any{
+			"perDay": 200, "ease4": 1.3, "ivlFct": 1, "maxIvl": 36500, "bury": false, "hardFactor": 1.2,
+		},
+		"lapse": map[string]any{
+			"delays": []int{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 1,
+		},
+	}
+}
+
+// guid заметки: 10 символов, как у Anki
+func newGuid() (string, error) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!#$%&()*+,-./:;<=>?@[]^_`{|}~"
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	for i, b := range raw {
+		raw[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return string(raw), nil
+}
+
+// checksum - первые 8 hex цифр sha1 поля сортировки
+func checksum(field string) int64 {
+	sum := sha1.Sum([]byte(field))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
+
+const (
+	colSQL = `CREATE TABLE col (id integer primary key, crt integer not null, mod integer not null, scm integer not null, ` +
+		`ver integer not null, dty integer not null, usn integer not null, ls integer not null, conf text not null, ` +
+		`models text not null, decks text not null, dconf text not null, tags text not null)`
+	notesSQL = `CREATE TABLE notes (id integer primary key, guid text not null, mid integer not null, mod integer not null, ` +
+		`usn integer not null, tags text not null, flds text not null, sfld integer not null, csum integer not null, ` +
+		`flags integer not null, data text not null)`
+	cardsSQL = `CREATE TABLE cards (id integer primary key, nid integer not null, did integer not null, ord integer not null, ` +
+		`mod integer not null, usn integer not null, type integer not null, queue integer not null, due integer not null, ` +
+		`ivl integer not null, factor integer not null, reps integer not null, lapses integer not null, left integer not null, ` +
+		`odue integer not null, odid integer not null, flags integer not null, data text not null)`
+	revlogSQL = `CREATE TABLE revlog (id integer primary key, cid integer not null, usn integer not null, ease integer not null, ` +
+		`ivl integer not null, lastIvl integer not null, factor integer not null, time integer not null, type integer not null)`
+	gravesSQL = `CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null)`
+)
@@ -0,0 +1,121 @@
+package cardsexport
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/utils/csvcell"
+	"io"
+)
+
+const (
+	manifestFile = "manifest.json"
+	contentFile  = "content.json"
+
+	// ограничение на размер распакованного файла из архива
+	maxBundleFileSize = 32 << 20
+)
+
+// WriteCSV пишет карточки с заголовком, для категории добавляется колонка с названием модуля.
+// Текст ячеек экранируется, чтобы табличные редакторы не исполняли его как формулы.
+func WriteCSV(w io.Writer, bundle entity.Bundle) error {
+	writer := csv.NewWriter(w)
+	isCategory := bundle.Manifest.Kind == entity.CategoryBundle
+
+	header := []string{"term", "term_lang", "definition", "def_lang"}
+	if isCategory {
+		header = append([]string{"module"}, header...)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, module := range bundle.Content.Modules {
+		for _, card := range module.Cards {
+			record := []string{card.Term.Text, card.Term.Lang, card.Definition.Text, card.Definition.Lang}
+			if isCategory {
+				record = append([]string{module.Name}, record...)
+			}
+			for i := range record {
+				record[i] = csvcell.Escape(record[i])
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func WriteJSON(w io.Writer, bundle entity.Bundle) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bundle)
+}
+
+// WriteBundle пишет zip архив с манифестом и содержимым
+func WriteBundle(w io.Writer, bundle entity.Bundle) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{manifestFile, bundle.Manifest},
+		{contentFile, bundle.Content},
+	}
+	for _, file := range files {
+		fw, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if err = json.NewEncoder(fw).Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func ReadBundle(r io.ReaderAt, size int64) (entity.Bundle, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return entity.Bundle{}, err
+	}
+
+	bundle := entity.Bundle{}
+	if err = readJSONFile(archive, manifestFile, &bundle.Manifest); err != nil {
+		return entity.Bundle{}, err
+	}
+	if bundle.Manifest.Format != entity.BundleFormat {
+		return entity.Bundle{}, errors.New("unknown bundle format")
+	} else if bundle.Manifest.Version > entity.BundleVersion || bundle.Manifest.Version < 1 {
+		return entity.Bundle{}, fmt.Errorf("unsupported bundle version %d", bundle.Manifest.Version)
+	}
+
+	if err = readJSONFile(archive, contentFile, &bundle.Content); err != nil {
+		return entity.Bundle{}, err
+	}
+	return bundle, nil
+}
+
+func readJSONFile(archive *zip.Reader, name string, v any) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return fmt.Errorf("bundle does not contain %s", name)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBundleFileSize+1))
+	if err != nil {
+		return err
+	} else if len(data) > maxBundleFileSize {
+		return fmt.Errorf("%s is too large", name)
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,85 @@
+package cardsexport
+
+import (
+	"bytes"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/utils/cardsimport"
+	"reflect"
+	"testing"
+)
+
+func card(term, definition string) entity.CardToAdd {
+	return entity.CardToAdd{
+		Term:       entity.TextWithLang{Lang: "en", Text: term},
+		Definition: entity.TextWithLang{Lang: "ru", Text: definition},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		bundle entity.Bundle
+		want   string
+	}{
+		{
+			name: "module",
+			bundle: entity.Bundle{
+				Manifest: entity.BundleManifest{Kind: entity.ModuleBundle},
+				Content:  entity.BundleContent{Modules: []entity.BundleModule{{Name: "Words", Cards: []entity.CardToAdd{card("cat", "кошка")}}}},
+			},
+			want: "term,term_lang,definition,def_lang\ncat,en,кошка,ru\n",
+		},
+		{
+			name: "formulas in category",
+			bundle: entity.Bundle{
+				Manifest: entity.BundleManifest{Kind: entity.CategoryBundle},
+				Content: entity.BundleContent{Modules: []entity.BundleModule{
+					{Name: "=cmd|' /C calc'!A0", Cards: []entity.CardToAdd{card("=HYPERLINK(\"http://evil\")", "@SUM(A1)")}},
+				}},
+			},
+			want: "module,term,term_lang,definition,def_lang\n" +
+				"'=cmd|' /C calc'!A0,\"'=HYPERLINK(\"\"http://evil\"\")\",en,'@SUM(A1),ru\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, test.bundle); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("got\n%s\nwant\n%s", buf.String(), test.want)
+			}
+		})
+	}
+}
+
+// выгруженный файл импортируется обратно с тем же текстом карточек
+func TestWriteCSVImport(t *testing.T) {
+	cards := []entity.CardToAdd{
+		card("cat", "кошка"),
+		card("=1+1", "+2"),
+		card("-minus", "'=already quoted"),
+		card("'apostrophe", "@mention"),
+	}
+	bundle := entity.Bundle{
+		Manifest: entity.BundleManifest{Kind: entity.ModuleBundle},
+		Content:  entity.BundleContent{Modules: []entity.BundleModule{{Name: "Words", Cards: cards}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, bundle); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	opts := cardsimport.Options{Separator: ',', HasHeader: true, TermColumn: 0, TermLangColumn: 1, DefColumn: 2, DefLangColumn: 3}
+	got, rowErrors, err := cardsimport.Parse(&buf, opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	} else if len(rowErrors) != 0 {
+		t.Fatalf("got row errors %v", rowErrors)
+	}
+	if !reflect.DeepEqual(got, cards) {
+		t.Errorf("got %v, want %v", got, cards)
+	}
+}
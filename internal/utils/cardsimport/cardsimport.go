@@ -0,0 +1,158 @@
+package cardsimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/utils/csvcell"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const MaxRows = 2000
+
+// Options описывает разметку файла. Номера колонок считаются с нуля,
+// отрицательный номер колонки языка означает, что язык берется из TermLang/DefLang.
+type Options struct {
+	Separator      rune
+	HasHeader      bool
+	TermColumn     int
+	DefColumn      int
+	TermLangColumn int
+	DefLangColumn  int
+	TermLang       string
+	DefLang        string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Separator:      '\t',
+		TermColumn:     0,
+		DefColumn:      1,
+		TermLangColumn: -1,
+		DefLangColumn:  -1,
+	}
+}
+
+// ParseSeparator понимает названия разделителей и одиночный символ
+func ParseSeparator(sep string) (rune, error) {
+	switch strings.ToLower(sep) {
+	case "", "tab", "tsv", "\t":
+		return '\t', nil
+	case "comma", "csv":
+		return ',', nil
+	case "semicolon":
+		return ';', nil
+	}
+
+	runes := []rune(sep)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf("bad separator %q", sep)
+	}
+	return runes[0], nil
+}
+
+// Parse разбирает файл в карточки. Ошибки в отдельных строках не прерывают разбор
+// и возвращаются вместе с номером строки файла.
+func Parse(r io.Reader, opts Options) ([]entity.CardToAdd, []entity.ImportRowError, error) {
+	if opts.TermColumn < 0 || opts.DefColumn < 0 || opts.TermColumn == opts.DefColumn {
+		return nil, nil, errors.New("bad term or definition column")
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Separator
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	// при табуляции-разделителе csv срезал бы пустую первую колонку и сдвинул остальные
+	reader.TrimLeadingSpace = !unicode.IsSpace(opts.Separator)
+
+	cards := []entity.CardToAdd{}
+	rowErrors := []entity.ImportRowError{}
+	row := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, entity.ImportRowError{Row: parseErr.Line, Message: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, err
+		}
+		// заголовком считается первая разобранная запись
+		row++
+
+		if row == 1 && opts.HasHeader {
+			continue
+		}
+		if isEmptyRecord(record) {
+			continue
+		}
+		if len(cards) >= MaxRows {
+			return nil, nil, fmt.Errorf("file contains more than %d cards", MaxRows)
+		}
+
+		card, err := recordToCard(record, opts)
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			rowErrors = append(rowErrors, entity.ImportRowError{Row: line, Message: err.Error()})
+			continue
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, rowErrors, nil
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func column(record []string, idx int) (string, bool) {
+	if idx < 0 || idx >= len(record) {
+		return "", false
+	}
+	// текст, экранированный при выгрузке, возвращается как был
+	return csvcell.Unescape(strings.TrimSpace(record[idx])), true
+}
+
+func recordToCard(record []string, opts Options) (entity.CardToAdd, error) {
+	card := entity.CardToAdd{
+		Term:       entity.TextWithLang{Lang: opts.TermLang},
+		Definition: entity.TextWithLang{Lang: opts.DefLang},
+	}
+
+	var ok bool
+	if card.Term.Text, ok = column(record, opts.TermColumn); !ok || card.Term.Text == "" {
+		return entity.CardToAdd{}, errors.New("empty term")
+	}
+	if card.Definition.Text, ok = column(record, opts.DefColumn); !ok || card.Definition.Text == "" {
+		return entity.CardToAdd{}, errors.New("empty definition")
+	}
+
+	if opts.TermLangColumn >= 0 {
+		if lang, ok := column(record, opts.TermLangColumn); ok && lang != "" {
+			card.Term.Lang = lang
+		}
+	}
+	if opts.DefLangColumn >= 0 {
+		if lang, ok := column(record, opts.DefLangColumn); ok && lang != "" {
+			card.Definition.Lang = lang
+		}
+	}
+
+	if card.Term.Lang == "" || card.Definition.Lang == "" {
+		return entity.CardToAdd{}, errors.New("unknown term or definition language")
+	}
+	return card, nil
+}
@@ -0,0 +1,162 @@
+package cardsimport
+
+import (
+	"interactive_learning/internal/entity"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func card(term, termLang, definition, defLang string) entity.CardToAdd {
+	return entity.CardToAdd{
+		Term:       entity.TextWithLang{Lang: termLang, Text: term},
+		Definition: entity.TextWithLang{Lang: defLang, Text: definition},
+	}
+}
+
+func TestParse(t *testing.T) {
+	withLangs := func(change func(opts *Options)) Options {
+		opts := DefaultOptions()
+		opts.TermLang, opts.DefLang = "en", "ru"
+		if change != nil {
+			change(&opts)
+		}
+		return opts
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		opts      Options
+		cards     []entity.CardToAdd
+		rowErrors []entity.ImportRowError
+	}{
+		{
+			name:  "tab separated",
+			input: "cat\tкошка\ndog\tсобака\n",
+			opts:  withLangs(nil),
+			cards: []entity.CardToAdd{card("cat", "en", "кошка", "ru"), card("dog", "en", "собака", "ru")},
+		},
+		{
+			name:  "semicolon separated with spaces",
+			input: "cat; кошка \n\n ; \ndog;собака",
+			opts:  withLangs(func(opts *Options) { opts.Separator = ';' }),
+			cards: []entity.CardToAdd{card("cat", "en", "кошка", "ru"), card("dog", "en", "собака", "ru")},
+		},
+		{
+			name:  "header is skipped",
+			input: "term,definition\ncat,кошка\n",
+			opts:  withLangs(func(opts *Options) { opts.Separator, opts.HasHeader = ',', true }),
+			cards: []entity.CardToAdd{card("cat", "en", "кошка", "ru")},
+		},
+		{
+			name:  "header after empty lines",
+			input: "\n\nterm,definition\ncat,кошка\n",
+			opts:  withLangs(func(opts *Options) { opts.Separator, opts.HasHeader = ',', true }),
+			cards: []entity.CardToAdd{card("cat", "en", "кошка", "ru")},
+		},
+		{
+			name:  "quoted fields",
+			input: "\"cat, kitten\",\"кошка\nкотенок\"\n\"say \"\"meow\"\"\",мяукать\nbare \"quote\",кавычка\n",
+			opts:  withLangs(func(opts *Options) { opts.Separator = ',' }),
+			cards: []entity.CardToAdd{
+				card("cat, kitten", "en", "кошка\nкотенок", "ru"),
+				card("say \"meow\"", "en", "мяукать", "ru"),
+				card("bare \"quote\"", "en", "кавычка", "ru"),
+			},
+		},
+		{
+			name:  "escaped formulas",
+			input: "'=1+1\t'@мяу\n'cat\t''-2\n",
+			opts:  withLangs(nil),
+			cards: []entity.CardToAdd{card("=1+1", "en", "@мяу", "ru"), card("'cat", "en", "'-2", "ru")},
+		},
+		{
+			name:  "columns and langs from file",
+			input: "de\tKatze\tкошка\tru\nde\tHund\tсобака\t\n",
+			opts: Options{
+				Separator: '\t', TermColumn: 1, DefColumn: 2, TermLangColumn: 0, DefLangColumn: 3, DefLang: "uk",
+			},
+			cards: []entity.CardToAdd{card("Katze", "de", "кошка", "ru"), card("Hund", "de", "собака", "uk")},
+		},
+		{
+			name:  "invalid rows",
+			input: "cat\tкошка\nonly term\n\tпусто\nfish\tрыба\n",
+			opts:  withLangs(nil),
+			cards: []entity.CardToAdd{card("cat", "en", "кошка", "ru"), card("fish", "en", "рыба", "ru")},
+			rowErrors: []entity.ImportRowError{
+				{Row: 2, Message: "empty definition"},
+				{Row: 3, Message: "empty term"},
+			},
+		},
+		{
+			name:      "no langs",
+			input:     "cat\tкошка\n",
+			opts:      DefaultOptions(),
+			cards:     []entity.CardToAdd{},
+			rowErrors: []entity.ImportRowError{{Row: 1, Message: "unknown term or definition language"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cards, rowErrors, err := Parse(strings.NewReader(test.input), test.opts)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !reflect.DeepEqual(cards, test.cards) {
+				t.Errorf("got cards %v, want %v", cards, test.cards)
+			}
+			if test.rowErrors == nil {
+				test.rowErrors = []entity.ImportRowError{}
+			}
+			if !reflect.DeepEqual(rowErrors, test.rowErrors) {
+				t.Errorf("got errors %v, want %v", rowErrors, test.rowErrors)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+	}{
+		{"same columns", "cat\tкошка\n", Options{Separator: '\t', TermColumn: 1, DefColumn: 1}},
+		{"negative column", "cat\tкошка\n", Options{Separator: '\t', TermColumn: -1, DefColumn: 1}},
+		{"too many rows", strings.Repeat("cat\tкошка\n", MaxRows+1), Options{Separator: '\t', DefColumn: 1, TermLang: "en", DefLang: "ru"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := Parse(strings.NewReader(test.input), test.opts); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
+
+func TestParseSeparator(t *testing.T) {
+	tests := []struct {
+		sep     string
+		want    rune
+		wantErr bool
+	}{
+		{"", '\t', false},
+		{"TSV", '\t', false},
+		{"comma", ',', false},
+		{"semicolon", ';', false},
+		{"|", '|', false},
+		{"\"", 0, true},
+		{"\n", 0, true},
+		{"ab", 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseSeparator(test.sep)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseSeparator(%q) = %q, %v; want %q, error %v", test.sep, got, err, test.want, test.wantErr)
+		}
+	}
+}
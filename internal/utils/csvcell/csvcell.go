@@ -0,0 +1,31 @@
+// Package csvcell не дает табличным редакторам принять текст ячейки CSV за формулу.
+// Опасный текст получает в начале апостроф, при импорте он снимается.
+package csvcell
+
+import "strings"
+
+// символы, с которых табличные редакторы начинают формулу
+const formulaStart = "=+-@\t\r"
+
+// needsEscape - начинается ли текст с формулы, в том числе после апострофов экранирования,
+// чтобы текст, уже похожий на экранированный, после импорта не потерял свой апостроф
+func needsEscape(value string) bool {
+	value = strings.TrimLeft(value, "'")
+	return value != "" && strings.ContainsRune(formulaStart, rune(value[0]))
+}
+
+// Escape экранирует текст ячейки перед записью в CSV
+func Escape(value string) string {
+	if needsEscape(value) {
+		return "'" + value
+	}
+	return value
+}
+
+// Unescape возвращает текст, который был экранирован Escape
+func Unescape(value string) string {
+	if strings.HasPrefix(value, "'") && needsEscape(value[1:]) {
+		return value[1:]
+	}
+	return value
+}
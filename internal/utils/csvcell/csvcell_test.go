@@ -0,0 +1,33 @@
+package csvcell
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"cat", "cat"},
+		{"a=b", "a=b"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1", "'+1"},
+		{"-2", "'-2"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"'quoted", "'quoted"},
+		{"'=1", "''=1"},
+		{"''", "''"},
+	}
+
+	for _, test := range tests {
+		got := Escape(test.value)
+		if got != test.want {
+			t.Errorf("Escape(%q) = %q, want %q", test.value, got, test.want)
+		}
+		if back := Unescape(got); back != test.value {
+			t.Errorf("Unescape(%q) = %q, want %q", got, back, test.value)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package gradebook
+
+import (
+	"encoding/csv"
+	"interactive_learning/internal/entity"
+	"interactive_learning/internal/utils/csvcell"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteCSV пишет ведомость по заданию: строка на ученика, пустые ячейки - нет данных
+func WriteCSV(w io.Writer, gradebook entity.Gradebook) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"user_id", "login", "attempts", "best_score", "completed", "completed_at", "late"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, student := range gradebook.Students {
+		bestScore, completedAt := "", ""
+		if student.BestScore != nil {
+			bestScore = strconv.Itoa(*student.BestScore)
+		}
+		if student.CompletedAt != nil {
+			completedAt = student.CompletedAt.UTC().Format(time.DateTime)
+		}
+		record := []string{
+			strconv.Itoa(student.UserId),
+			csvcell.Escape(student.Login),
+			strconv.Itoa(student.Attempts),
+			bestScore,
+			strconv.FormatBool(student.Completed),
+			completedAt,
+			strconv.FormatBool(student.Late),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,62 @@
+package gradebook
+
+import (
+	"bytes"
+	"interactive_learning/internal/entity"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	score := 87
+	completedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	tests := []struct {
+		name     string
+		students []entity.AssignmentStatus
+		want     string
+	}{
+		{
+			name: "no students",
+			want: "user_id,login,attempts,best_score,completed,completed_at,late\n",
+		},
+		{
+			name: "completed and missing results",
+			students: []entity.AssignmentStatus{
+				{UserId: 1, Login: "anna", Attempts: 2, BestScore: &score, Completed: true, CompletedAt: &completedAt, Late: true},
+				{UserId: 2, Login: "boris"},
+			},
+			want: "user_id,login,attempts,best_score,completed,completed_at,late\n" +
+				"1,anna,2,87,true,2024-03-01 09:30:00,true\n" +
+				"2,boris,0,,false,,false\n",
+		},
+		{
+			name: "formulas in login",
+			students: []entity.AssignmentStatus{
+				{UserId: 1, Login: "=HYPERLINK(\"http://evil\")"},
+				{UserId: 2, Login: "+1"},
+				{UserId: 3, Login: "-2"},
+				{UserId: 4, Login: "@SUM(A1)"},
+				{UserId: 5, Login: "a=b"},
+			},
+			want: "user_id,login,attempts,best_score,completed,completed_at,late\n" +
+				"1,\"'=HYPERLINK(\"\"http://evil\"\")\",0,,false,,false\n" +
+				"2,'+1,0,,false,,false\n" +
+				"3,'-2,0,,false,,false\n" +
+				"4,'@SUM(A1),0,,false,,false\n" +
+				"5,a=b,0,,false,,false\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, entity.Gradebook{Students: test.students}); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("got\n%s\nwant\n%s", buf.String(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package sqlitefile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+type Reader struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+	pagesCount int
+}
+
+func NewReader(data []byte) (*Reader, error) {
+	if len(data) < headerSize || string(data[:len(headerMagic)]) != headerMagic {
+		return nil, ErrNotDatabase
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, ErrCorrupted
+	}
+	if textEncoding := binary.BigEndian.Uint32(data[56:60]); textEncoding > 1 {
+		return nil, fmt.Errorf("unsupported text encoding %d", textEncoding)
+	}
+	// на меньшей области страницы не сходится расчет локальной части ячейки
+	usableSize := pageSize - int(data[20])
+	if usableSize < minUsableSize {
+		return nil, ErrCorrupted
+	}
+
+	return &Reader{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: usableSize,
+		pagesCount: len(data) / pageSize,
+	}, nil
+}
+
+// Rows возвращает все строки таблицы в порядке rowid
+func (r *Reader) Rows(table string) ([]Row, error) {
+	schema, err := r.readTree(1)
+	if err != nil {
+		return nil, err
+	}
+
+	// sqlite_master: type, name, tbl_name, rootpage, sql
+	for _, row := range schema {
+		if row.Text(0) == "table" && row.Text(1) == table {
+			return r.readTree(int(row.Int(3)))
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoTable, table)
+}
+
+func (r *Reader) page(number int) ([]byte, int, error) {
+	if number < 1 || number > r.pagesCount {
+		return nil, 0, ErrCorrupted
+	}
+	page := r.data[(number-1)*r.pageSize : number*r.pageSize]
+	offset := 0
+	if number == 1 {
+		offset = headerSize
+	}
+	return page, offset, nil
+}
+
+func (r *Reader) readTree(root int) ([]Row, error) {
+	rows := []Row{}
+	visited := map[int]bool{}
+
+	var walk func(number int) error
+	walk = func(number int) error {
+		if visited[number] {
+			return ErrCorrupted
+		}
+		visited[number] = true
+
+		page, offset, err := r.page(number)
+		if err != nil {
+			return err
+		}
+
+		pageType := page[offset]
+		cellsCount := int(binary.BigEndian.Uint16(page[offset+3 : offset+5]))
+		pointersStart := offset + 8
+		if pageType == interiorTablePage {
+			pointersStart = offset + 12
+		} else if pageType != leafTablePage {
+			return ErrCorrupted
+		}
+		if pointersStart+cellsCount*2 > len(page) {
+			return ErrCorrupted
+		}
+
+		for i := 0; i < cellsCount; i++ {
+			cellOffset := int(binary.BigEndian.Uint16(page[pointersStart+i*2:]))
+			if cellOffset < pointersStart+cellsCount*2 || cellOffset >= r.usableSize {
+				return ErrCorrupted
+			}
+			cell := page[cellOffset:r.usableSize]
+
+			if pageType == interiorTablePage {
+				if len(cell) < 4 {
+					return ErrCorrupted
+				}
+				if err := walk(int(binary.BigEndian.Uint32(cell))); err != nil {
+					return err
+				}
+				continue
+			}
+
+			row, err := r.readLeafCell(cell)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+
+		if pageType == interiorTablePage {
+			return walk(int(binary.BigEndian.Uint32(page[offset+8:])))
+		}
+		return nil
+	}
+
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *Reader) readLeafCell(cell []byte) (Row, error) {
+	payloadSize, n := getVarint(cell)
+	if n == 0 {
+		return Row{}, ErrCorrupted
+	}
+	cell = cell[n:]
+	rowid, n := getVarint(cell)
+	if n == 0 {
+		return Row{}, ErrCorrupted
+	}
+	cell = cell[n:]
+
+	if payloadSize > uint64(len(r.data)) {
+		return Row{}, ErrCorrupted
+	}
+	local := localPayloadSize(int(payloadSize), r.usableSize)
+	if local < 0 || local > len(cell) {
+		return Row{}, ErrCorrupted
+	}
+
+	payload := make([]byte, 0, payloadSize)
+	payload = append(payload, cell[:local]...)
+	if local < int(payloadSize) {
+		if len(cell) < local+4 {
+			return Row{}, ErrCorrupted
+		}
+		next := int(binary.BigEndian.Uint32(cell[local:]))
+		for len(payload) < int(payloadSize) {
+			page, _, err := r.page(next)
+			if err != nil {
+				return Row{}, err
+			}
+			chunk := page[4:r.usableSize]
+			if rest := int(payloadSize) - len(payload); rest < len(chunk) {
+				chunk = chunk[:rest]
+			}
+			payload = append(payload, chunk...)
+			next = int(binary.BigEndian.Uint32(page))
+		}
+	}
+
+	values, err := decodeRecord(payload)
+	if err != nil {
+		return Row{}, err
+	}
+	return Row{Rowid: int64(rowid), Values: values}, nil
+}
+
+func decodeRecord(payload []byte) ([]any, error) {
+	headerLen, n := getVarint(payload)
+	if n == 0 || uint64(n) > headerLen || headerLen > uint64(len(payload)) {
+		return nil, ErrCorrupted
+	}
+
+	header := payload[n:headerLen]
+	body := payload[headerLen:]
+	values := []any{}
+	for len(header) > 0 {
+		serialType, n := getVarint(header)
+		if n == 0 {
+			return nil, ErrCorrupted
+		}
+		header = header[n:]
+
+		size := serialTypeSize(serialType)
+		if size > uint64(len(body)) {
+			return nil, ErrCorrupted
+		}
+		raw := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType >= 1 && serialType <= 6:
+			values = append(values, readInt(raw))
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(raw)))
+		case serialType == 8:
+			values = append(values, int64(0))
+		case serialType == 9:
+			values = append(values, int64(1))
+		case serialType >= 12 && serialType%2 == 0:
+			values = append(values, append([]byte{}, raw...))
+		case serialType >= 13:
+			values = append(values, string(raw))
+		default:
+			return nil, ErrCorrupted
+		}
+	}
+	return values, nil
+}
+
+// размер остается uint64: у поврежденной записи он может не поместиться в int
+func serialTypeSize(serialType uint64) uint64 {
+	switch {
+	case serialType <= 4:
+		return serialType
+	case serialType == 5:
+		return 6
+	case serialType == 6 || serialType == 7:
+		return 8
+	case serialType >= 12:
+		return (serialType - 12) / 2
+	}
+	return 0
+}
+
+func readInt(raw []byte) int64 {
+	var v int64
+	if len(raw) > 0 && raw[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, b := range raw {
+		v = v<<8 | int64(b)
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+// Package sqlitefile читает и пишет файлы баз SQLite без драйвера.
+// Поддерживается только то, что нужно для обмена файлами: таблицы (table b-tree)
+// в кодировке UTF-8, без индексов, WAL и автовакуума.
+package sqlitefile
+
+import "errors"
+
+const (
+	headerMagic = "SQLite format 3\x00"
+	headerSize  = 100
+	// минимальная полезная область страницы по формату SQLite
+	minUsableSize = 480
+
+	interiorTablePage = 0x05
+	leafTablePage     = 0x0d
+)
+
+var (
+	ErrNotDatabase = errors.New("file is not a sqlite database")
+	ErrCorrupted   = errors.New("sqlite database is corrupted")
+	ErrNoTable     = errors.New("no such table")
+)
+
+// Row - строка таблицы. Колонка INTEGER PRIMARY KEY хранится как NULL,
+// ее значение нужно брать из Rowid.
+type Row struct {
+	Rowid  int64
+	Values []any // nil, int64, float64, string или []byte
+}
+
+func (r Row) Int(i int) int64 {
+	if i >= len(r.Values) {
+		return 0
+	}
+	switch v := r.Values[i].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+func (r Row) Text(i int) string {
+	if i >= len(r.Values) {
+		return ""
+	}
+	switch v := r.Values[i].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+func getVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	if len(b) < 9 {
+		return 0, 0
+	}
+	return v<<8 | uint64(b[8]), 9
+}
+
+func putVarint(v uint64) []byte {
+	if v > 0x00ffffffffffffff {
+		b := make([]byte, 9)
+		b[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			b[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return b
+	}
+
+	var tmp [9]byte
+	n := 0
+	for {
+		tmp[n] = byte(v&0x7f) | 0x80
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	tmp[0] &= 0x7f
+
+	b := make([]byte, n)
+	for i := 0; i < n; i++ {
+		b[i] = tmp[n-1-i]
+	}
+	return b
+}
+
+// размер локальной части ячейки листа таблицы, остальное уходит в страницы переполнения
+func localPayloadSize(payloadSize, usableSize int) int {
+	maxLocal := usableSize - 35
+	if payloadSize <= maxLocal {
+		return payloadSize
+	}
+	minLocal := (usableSize-12)*32/255 - 23
+	local := minLocal + (payloadSize-minLocal)%(usableSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	return local
+}
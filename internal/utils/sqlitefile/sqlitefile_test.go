@@ -0,0 +1,178 @@
+package sqlitefile
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTables(t testing.TB, tables []Table) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, tables); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteRead(t *testing.T) {
+	manyRows := []Row{}
+	for i := 1; i <= 2000; i++ {
+		manyRows = append(manyRows, Row{Rowid: int64(i), Values: []any{nil, int64(i), strings.Repeat("x", i%50)}})
+	}
+
+	tests := []struct {
+		name string
+		rows []Row
+	}{
+		{"empty table", []Row{}},
+		{"integers", []Row{{Rowid: 1, Values: []any{
+			int64(0), int64(1), int64(-1), int64(127), int64(-129), int64(1 << 20),
+			int64(1 << 40), int64(-1 << 62),
+		}}}},
+		{"mixed values", []Row{
+			{Rowid: 1, Values: []any{nil, "термин", 2.5, []byte{0, 1, 2}}},
+			{Rowid: 5, Values: []any{nil, "", -0.5, []byte{}}},
+		}},
+		{"overflow pages", []Row{{Rowid: 7, Values: []any{strings.Repeat("длинное поле ", 2000)}}}},
+		{"interior pages", manyRows},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := writeTables(t, []Table{{Name: "items", SQL: "CREATE TABLE items (id integer primary key, v)", Rows: test.rows}})
+			db, err := NewReader(data)
+			if err != nil {
+				t.Fatalf("new reader: %v", err)
+			}
+			rows, err := db.Rows("items")
+			if err != nil {
+				t.Fatalf("rows: %v", err)
+			}
+			if !reflect.DeepEqual(rows, test.rows) {
+				t.Errorf("got %d rows, want %d rows equal to written", len(rows), len(test.rows))
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	valid := writeTables(t, []Table{{Name: "items", SQL: "CREATE TABLE items (v)", Rows: []Row{{Rowid: 1, Values: []any{"a"}}}}})
+	corrupt := func(change func(data []byte)) []byte {
+		data := append([]byte{}, valid...)
+		change(data)
+		return data
+	}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		table string
+		err   error
+	}{
+		{"short file", valid[:50], "items", ErrNotDatabase},
+		{"bad magic", corrupt(func(d []byte) { d[0] = 'X' }), "items", ErrNotDatabase},
+		{"bad page size", corrupt(func(d []byte) { d[16], d[17] = 0x03, 0x00 }), "items", ErrCorrupted},
+		{"too much reserved space", corrupt(func(d []byte) { d[20] = 255 }), "items", ErrCorrupted},
+		{"bad page type", corrupt(func(d []byte) { d[headerSize] = 0x02 }), "items", ErrCorrupted},
+		{"no table", valid, "other", ErrNoTable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := NewReader(test.data)
+			if err == nil {
+				_, err = db.Rows(test.table)
+			}
+			if !errors.Is(err, test.err) {
+				t.Errorf("got error %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestDecodeRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		values  []any
+		err     error
+	}{
+		{"empty header", []byte{0x01}, []any{}, nil},
+		{"text and int", []byte{0x03, 0x0f, 0x01, 'a', 0x05}, []any{"a", int64(5)}, nil},
+		{"empty payload", []byte{}, nil, ErrCorrupted},
+		{"header shorter than its length", []byte{0x00}, nil, ErrCorrupted},
+		{"header longer than payload", []byte{0x05, 0x01}, nil, ErrCorrupted},
+		{"body shorter than serial type", []byte{0x02, 0x06, 0x01}, nil, ErrCorrupted},
+		{"huge serial type", []byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, nil, ErrCorrupted},
+		{"reserved serial type", []byte{0x02, 0x0a}, nil, ErrCorrupted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := decodeRecord(test.payload)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if !reflect.DeepEqual(values, test.values) {
+				t.Errorf("got %v, want %v", values, test.values)
+			}
+		})
+	}
+}
+
+func TestVarint(t *testing.T) {
+	for _, v := range []uint64{0, 1, 127, 128, 16383, 16384, 1 << 32, 0x00ffffffffffffff, 0x0100000000000000, 1<<64 - 1} {
+		encoded := putVarint(v)
+		got, n := getVarint(encoded)
+		if got != v || n != len(encoded) {
+			t.Errorf("varint %d: got %d, read %d of %d bytes", v, got, n, len(encoded))
+		}
+	}
+}
+
+func FuzzDecodeRecord(f *testing.F) {
+	for _, values := range [][]any{{}, {nil, int64(-5), 1.5, "text", []byte{1}}, {strings.Repeat("a", 300)}} {
+		record, err := encodeRecord(values)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(record)
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		values, err := decodeRecord(payload)
+		if err != nil {
+			return
+		}
+		// разобранная запись кодируется обратно без потерь
+		record, err := encodeRecord(values)
+		if err != nil {
+			t.Fatalf("encode decoded record: %v", err)
+		}
+		again, err := decodeRecord(record)
+		if err != nil {
+			t.Fatalf("decode encoded record: %v", err)
+		}
+		if encoded, _ := encodeRecord(again); !bytes.Equal(encoded, record) {
+			t.Errorf("round trip changed record %x to %x", record, encoded)
+		}
+	})
+}
+
+func FuzzReader(f *testing.F) {
+	f.Add(writeTables(f, []Table{{Name: "items", SQL: "CREATE TABLE items (v)", Rows: []Row{
+		{Rowid: 1, Values: []any{"a", int64(1)}},
+		{Rowid: 2, Values: []any{strings.Repeat("b", 5000)}},
+	}}}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		db, err := NewReader(data)
+		if err != nil {
+			return
+		}
+		db.Rows("items")
+	})
+}
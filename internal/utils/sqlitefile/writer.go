@@ -0,0 +1,276 @@
+package sqlitefile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	pageSize      = 4096
+	sqliteVersion = 3045001
+)
+
+type Table struct {
+	Name string
+	SQL  string
+	Rows []Row
+}
+
+// Write пишет новую базу с заданными таблицами
+func Write(w io.Writer, tables []Table) error {
+	fw := &fileWriter{pages: [][]byte{nil}}
+
+	schema := make([]Row, 0, len(tables))
+	for i, table := range tables {
+		root, err := fw.writeTree(table.Rows)
+		if err != nil {
+			return fmt.Errorf("table %s: %w", table.Name, err)
+		}
+		schema = append(schema, Row{
+			Rowid:  int64(i + 1),
+			Values: []any{"table", table.Name, table.Name, int64(root), table.SQL},
+		})
+	}
+
+	// sqlite_master всегда лежит на первой странице
+	cells := make([][]byte, 0, len(schema))
+	for _, row := range schema {
+		cell, err := fw.leafCell(row)
+		if err != nil {
+			return err
+		}
+		cells = append(cells, cell)
+	}
+	firstPage, rest := fw.packLeaf(cells, headerSize)
+	if len(rest) > 0 {
+		return errors.New("too many tables")
+	}
+	fw.pages[0] = firstPage
+	fw.writeHeader()
+
+	for _, page := range fw.pages {
+		if _, err := w.Write(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fileWriter struct {
+	pages [][]byte
+}
+
+func (fw *fileWriter) allocate() (int, []byte) {
+	page := make([]byte, pageSize)
+	fw.pages = append(fw.pages, page)
+	return len(fw.pages), page
+}
+
+func (fw *fileWriter) writeHeader() {
+	h := fw.pages[0][:headerSize]
+	copy(h, headerMagic)
+	binary.BigEndian.PutUint16(h[16:], pageSize)
+	h[18], h[19] = 1, 1
+	h[21], h[22], h[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(h[24:], 1)
+	binary.BigEndian.PutUint32(h[28:], uint32(len(fw.pages)))
+	binary.BigEndian.PutUint32(h[40:], 1)
+	binary.BigEndian.PutUint32(h[44:], 4)
+	binary.BigEndian.PutUint32(h[56:], 1)
+	binary.BigEndian.PutUint32(h[92:], 1)
+	binary.BigEndian.PutUint32(h[96:], sqliteVersion)
+}
+
+// writeTree пишет b-дерево таблицы и возвращает номер корневой страницы
+func (fw *fileWriter) writeTree(rows []Row) (int, error) {
+	type child struct {
+		page   int
+		maxKey int64
+	}
+
+	cells := make([][]byte, 0, len(rows))
+	for _, row := range rows {
+		cell, err := fw.leafCell(row)
+		if err != nil {
+			return 0, err
+		}
+		cells = append(cells, cell)
+	}
+
+	level := []child{}
+	packedRows := 0
+	for len(level) == 0 || len(cells) > 0 {
+		packed, rest := fw.packLeaf(cells, 0)
+		count := len(cells) - len(rest)
+		if count == 0 && len(cells) > 0 {
+			return 0, errors.New("cell does not fit into page")
+		}
+		number, page := fw.allocate()
+		copy(page, packed)
+
+		packedRows += count
+		maxKey := int64(0)
+		if packedRows > 0 {
+			maxKey = rows[packedRows-1].Rowid
+		}
+		level = append(level, child{page: number, maxKey: maxKey})
+		cells = rest
+	}
+
+	for len(level) > 1 {
+		next := []child{}
+		for len(level) > 0 {
+			number, page := fw.allocate()
+			header := page[:12]
+			header[0] = interiorTablePage
+
+			contentStart := pageSize
+			count := 0
+			for len(level) > 1 {
+				cell := make([]byte, 4, 13)
+				binary.BigEndian.PutUint32(cell, uint32(level[0].page))
+				cell = append(cell, putVarint(uint64(level[0].maxKey))...)
+				if 12+(count+1)*2 > contentStart-len(cell) {
+					break
+				}
+				contentStart -= len(cell)
+				copy(page[contentStart:], cell)
+				binary.BigEndian.PutUint16(page[12+count*2:], uint16(contentStart))
+				count++
+				level = level[1:]
+			}
+
+			// последний ребенок страницы становится правым указателем
+			right := level[0]
+			level = level[1:]
+			binary.BigEndian.PutUint32(header[8:], uint32(right.page))
+			binary.BigEndian.PutUint16(header[3:], uint16(count))
+			binary.BigEndian.PutUint16(header[5:], uint16(contentStart))
+			next = append(next, child{page: number, maxKey: right.maxKey})
+		}
+		level = next
+	}
+	return level[0].page, nil
+}
+
+// packLeaf собирает страницу-лист из первых влезающих ячеек
+func (fw *fileWriter) packLeaf(cells [][]byte, offset int) ([]byte, [][]byte) {
+	page := make([]byte, pageSize)
+	page[offset] = leafTablePage
+
+	contentStart := pageSize
+	count := 0
+	for _, cell := range cells {
+		if offset+8+(count+1)*2 > contentStart-len(cell) {
+			break
+		}
+		contentStart -= len(cell)
+		copy(page[contentStart:], cell)
+		binary.BigEndian.PutUint16(page[offset+8+count*2:], uint16(contentStart))
+		count++
+	}
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(count))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(contentStart))
+	return page, cells[count:]
+}
+
+func (fw *fileWriter) leafCell(row Row) ([]byte, error) {
+	payload, err := encodeRecord(row.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	cell := putVarint(uint64(len(payload)))
+	cell = append(cell, putVarint(uint64(row.Rowid))...)
+
+	local := localPayloadSize(len(payload), pageSize)
+	cell = append(cell, payload[:local]...)
+	if local == len(payload) {
+		return cell, nil
+	}
+
+	overflow := payload[local:]
+	firstPage, page := fw.allocate()
+	for {
+		n := copy(page[4:], overflow)
+		overflow = overflow[n:]
+		if len(overflow) == 0 {
+			break
+		}
+		next, nextPage := fw.allocate()
+		binary.BigEndian.PutUint32(page, uint32(next))
+		page = nextPage
+	}
+
+	pointer := make([]byte, 4)
+	binary.BigEndian.PutUint32(pointer, uint32(firstPage))
+	return append(cell, pointer...), nil
+}
+
+func encodeRecord(values []any) ([]byte, error) {
+	types := []byte{}
+	body := []byte{}
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			types = append(types, putVarint(0)...)
+		case int:
+			serialType, raw := encodeInt(int64(v))
+			types = append(types, putVarint(serialType)...)
+			body = append(body, raw...)
+		case int64:
+			serialType, raw := encodeInt(v)
+			types = append(types, putVarint(serialType)...)
+			body = append(body, raw...)
+		case float64:
+			types = append(types, putVarint(7)...)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			types = append(types, putVarint(uint64(13+2*len(v)))...)
+			body = append(body, v...)
+		case []byte:
+			types = append(types, putVarint(uint64(12+2*len(v)))...)
+			body = append(body, v...)
+		default:
+			return nil, fmt.Errorf("unsupported value type %T", value)
+		}
+	}
+
+	// длина заголовка учитывает саму себя
+	lenSize := 1
+	for len(putVarint(uint64(len(types)+lenSize))) > lenSize {
+		lenSize++
+	}
+	record := putVarint(uint64(len(types) + lenSize))
+	record = append(record, types...)
+	return append(record, body...), nil
+}
+
+func encodeInt(v int64) (uint64, []byte) {
+	if v == 0 {
+		return 8, nil
+	} else if v == 1 {
+		return 9, nil
+	}
+
+	sizes := []struct {
+		serialType uint64
+		bytes      int
+	}{{1, 1}, {2, 2}, {3, 3}, {4, 4}, {5, 6}, {6, 8}}
+	for _, size := range sizes {
+		bits := uint(size.bytes * 8)
+		if size.bytes == 8 || (v >= -(1<<(bits-1)) && v < 1<<(bits-1)) {
+			raw := make([]byte, size.bytes)
+			u := uint64(v)
+			for i := size.bytes - 1; i >= 0; i-- {
+				raw[i] = byte(u)
+				u >>= 8
+			}
+			return size.serialType, raw
+		}
+	}
+	return 0, nil
+}
@@ -23,6 +23,9 @@ window.addEventListener('DOMContentLoaded', async () => {
     let userLoaded = false;
     let moduleLoaded = false;
     let originalModuleName = '';
+    // версии для If-Match: сервер отвечает 412, если модуль или карточку уже изменили
+    let moduleETag = null;
+    const cardETags = {};
 
     const studyModuleBtn = document.getElementById('study-module-btn');
     const testModuleBtn = document.getElementById('test-module-btn');
@@ -190,6 +193,7 @@ window.addEventListener('DOMContentLoaded', async () => {
             if (!res.ok) {
                 throw new Error('Ошибка загрузки модуля');
             }
+            moduleETag = res.headers.get('ETag');
             return res.json();
         })
         .then(moduleData => {
@@ -211,6 +215,7 @@ window.addEventListener('DOMContentLoaded', async () => {
                 emptyMessage.style.display = 'none';
                 cardsContainer.innerHTML = '';
                 moduleData.cards.forEach((card) => {
+                    cardETags[card.id] = `"${card.version}"`;
                     const cardElem = document.createElement('div');
                     cardElem.className = 'card';
                     cardElem.dataset.cardId = card.id;
@@ -256,6 +261,34 @@ window.addEventListener('DOMContentLoaded', async () => {
         }
     }
 
+    // модуль изменили в другом окне: показываем его текущее состояние и просим повторить
+    function applyModuleConflict(res) {
+        return res.json().then(data => {
+            moduleETag = res.headers.get('ETag');
+            if (data.module) {
+                document.getElementById('module-name').textContent = data.module.name;
+                originalModuleName = data.module.name;
+                moduleType = data.module.type || 0;
+                updateModuleTypeButton();
+            }
+            throw new Error('Модуль уже изменили, проверьте данные и повторите');
+        });
+    }
+
+    function applyCardConflict(cardId, res) {
+        return res.json().then(data => {
+            cardETags[cardId] = res.headers.get('ETag');
+            const card = document.querySelector(`[data-card-id="${cardId}"]`);
+            if (card && data.card) {
+                card.querySelector('.card-title').textContent = data.card.term.text;
+                card.querySelector('.card-definition').textContent = data.card.definition.text;
+                editTermInput.value = data.card.term.text;
+                editDefInput.value = data.card.definition.text;
+            }
+            throw new Error('Карточку уже изменили, проверьте текст и сохраните еще раз');
+        });
+    }
+
     function updateModuleTypeButton() {  
         if (!toggleModuleTypeBtn || moduleType === null) return; 
         
@@ -389,7 +422,8 @@ window.addEventListener('DOMContentLoaded', async () => {
                 method: 'PUT',
                 headers: {
                     'Authorization': `Bearer ${token}`,
-                    'Content-Type': 'application/json'
+                    'Content-Type': 'application/json',
+                    'If-Match': moduleETag
                 },
                 body: JSON.stringify({ type: newType })
             })
@@ -398,11 +432,15 @@ window.addEventListener('DOMContentLoaded', async () => {
                     window.location.href = `/static/login.html?redirect=${encodeURIComponent(window.location.href)}`;
                     return Promise.reject();
                 }
+                if (res.status === 412) {
+                    return applyModuleConflict(res);
+                }
                 if (!res.ok) {
                     if (res.status === 403) throw new Error('Нет прав для изменения типа модуля');
                     if (res.status === 404) throw new Error('Модуль не найден');
                     throw new Error('Ошибка изменения типа модуля');
                 }
+                moduleETag = res.headers.get('ETag');
             })
             .then(() => {
                 moduleType = newType;
@@ -517,7 +555,8 @@ window.addEventListener('DOMContentLoaded', async () => {
                 method: 'PUT',
                 headers: { 
                     'Authorization': `Bearer ${token}`, 
-                    'Content-Type': 'application/json' 
+                    'Content-Type': 'application/json',
+                    'If-Match': moduleETag
                 },
                 body: JSON.stringify({ new_name: newName })
             })
@@ -526,9 +565,11 @@ window.addEventListener('DOMContentLoaded', async () => {
                     window.location.href = `/static/login.html?redirect=${encodeURIComponent(window.location.href)}`;
                     return;
                 }
+                if (res.status === 412) return applyModuleConflict(res);
                 if (res.status === 400) throw new Error('Неверное название модуля');
                 if (res.status === 409) throw new Error('Модуль с таким названием уже существует');
                 if (!res.ok) throw new Error('Ошибка переименования модуля');
+                moduleETag = res.headers.get('ETag');
                 return res.json();
             })
             .then(() => {
@@ -764,6 +805,7 @@ window.addEventListener('DOMContentLoaded', async () => {
                     const cardElem = document.createElement('div');
                     cardElem.className = 'card';
                     cardElem.dataset.cardId = cardId;
+                    cardETags[cardId] = '"1"';
                     cardElem.innerHTML = `
                         <div class="card-title">${newCardData.term.text}</div>
                         <div class="card-definition">${newCardData.definition.text}</div>
@@ -868,7 +910,8 @@ window.addEventListener('DOMContentLoaded', async () => {
                 method: 'PUT',
                 headers: {
                     'Authorization': `Bearer ${token}`,
-                    'Content-Type': 'application/json'
+                    'Content-Type': 'application/json',
+                    'If-Match': cardETags[cardId]
                 },
                 body: JSON.stringify({
                     term: { text: term, lang: termLang },
@@ -880,11 +923,13 @@ window.addEventListener('DOMContentLoaded', async () => {
                     window.location.href = `/static/login.html?redirect=${encodeURIComponent(window.location.href)}`;
                     return;
                 }
+                if (res.status === 412) return applyCardConflict(cardId, res);
                 if (res.status === 400) throw new Error('Неверные данные');
                 if (res.status === 404) throw new Error('Карточка не найдена');
                 if (res.status === 403) throw new Error('Нет прав для редактирования');
                 if (res.status === 500) throw new Error('Ошибка сервера');
                 if (!res.ok) throw new Error('Ошибка обновления карточки');
+                cardETags[cardId] = res.headers.get('ETag');
                 return res.json();
             })
             .then(() => {
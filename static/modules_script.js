@@ -119,6 +119,8 @@ function createModuleCard(module) {
   const card = document.createElement('div');
   card.className = 'card';
   card.dataset.moduleId = module.id;
+  // версия модуля для If-Match при переименовании
+  card.dataset.etag = `"${module.version}"`;
   card.style.cursor = 'pointer';
   
   const typeText = module.type === 0 ? 'Открытый' : 'Приватный';
@@ -420,11 +422,14 @@ function setupEditModuleModal(token) {
       return;
     }
 
+    const moduleCard = document.querySelector(`[data-module-id="${currentEditingModuleId}"]`);
+
     fetch(`${API_BASE_URL}/api/v1/module/rename/${currentEditingModuleId}`, {
       method: 'PUT',
       headers: { 
         'Authorization': `Bearer ${token}`, 
-        'Content-Type': 'application/json' 
+        'Content-Type': 'application/json',
+        'If-Match': moduleCard ? moduleCard.dataset.etag : ''
       },
       body: JSON.stringify({ new_name: newName })
     })
@@ -433,6 +438,17 @@ function setupEditModuleModal(token) {
         window.location.href = `/static/login.html?redirect=${encodeURIComponent(window.location.href)}`;
         return;
       }
+      if (res.status === 412) {
+        // модуль изменили в другом окне: показываем актуальное название
+        return res.json().then(data => {
+          if (moduleCard && data.module) {
+            moduleCard.dataset.etag = res.headers.get('ETag');
+            moduleCard.querySelector('.card-title').textContent = data.module.name;
+            originalModuleName = data.module.name;
+          }
+          throw new Error('Модуль уже изменили, проверьте название и повторите');
+        });
+      }
       if (res.status === 400) {
         throw new Error('Неверное название модуля');
       }
@@ -442,12 +458,14 @@ function setupEditModuleModal(token) {
       if (!res.ok) {
         throw new Error(`Ошибка: ${res.status}`);
       }
+      if (moduleCard) {
+        moduleCard.dataset.etag = res.headers.get('ETag');
+      }
       return res.json();
     })
     .then(() => {
-      const card = document.querySelector(`[data-module-id="${currentEditingModuleId}"]`);
-      if (card) {
-        const title = card.querySelector('.card-title');
+      if (moduleCard) {
+        const title = moduleCard.querySelector('.card-title');
         if (title) {
           title.textContent = newName;
         }